@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// runChangelog implements "geq changelog": it compares two schemas, each an
+// endpoint URL, an SDL file or an introspection JSON file, and writes release
+// notes for the changes as Markdown or JSON.
+func runChangelog(args []string) {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	format := fs.String("format", "markdown", "Output format: 'markdown' or 'json'")
+	outputFile := fs.String("output", "-", "Output file path for the changelog, or '-' for stdout")
+	linkTemplate := fs.String("link-template", "", "Link each entry to this URL; {type} and {coordinate} are replaced by the type and schema coordinate")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(outputFile, "o", *outputFile, "Output file path (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq changelog [options] <old> <new>\n\n")
+		fmt.Fprintf(fs.Output(), "Each schema is an endpoint URL, an SDL file (.graphql, .graphqls, .gql) or an introspection JSON file.\n\n")
+		fs.PrintDefaults()
+	}
+	schemas := parseInterspersed(fs, args)
+	if len(schemas) != 2 {
+		fmt.Println("Error: changelog needs exactly two schemas, the old and the new one")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if *format != "markdown" && *format != "json" {
+		fmt.Printf("Error: invalid --format value '%s'. Expected 'markdown' or 'json'\n", *format)
+		os.Exit(exitError)
+	}
+
+	oldSchema, err := loadSchema(schemas[0], &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+	newSchema, err := loadSchema(schemas[1], &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+
+	changelog := geq.NewChangelog(oldSchema, newSchema)
+	err = writeOutput(*outputFile, "changelog", func(w io.Writer) error {
+		if *format == "json" {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(changelog)
+		}
+		return changelog.WriteMarkdown(w, *linkTemplate)
+	})
+	if err != nil {
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// failOnLevels maps the values of --fail-on to the criticalities they fail on.
+var failOnLevels = map[string][]geq.Criticality{
+	"any":       {geq.CriticalityBreaking, geq.CriticalityDangerous, geq.CriticalityNonBreaking},
+	"dangerous": {geq.CriticalityBreaking, geq.CriticalityDangerous},
+	"breaking":  {geq.CriticalityBreaking},
+}
+
+// runCheck implements "geq check": it fetches the schema from an endpoint and
+// compares it to a committed schema file. Both are normalised (sorted, built-in
+// definitions left out, printed the same way) so that only real changes count.
+// On a difference it prints a unified diff and the classified changes, and
+// exits with exitSchemaChanged if any change is one --fail-on asks to fail on.
+func runCheck(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "", "The GraphQL endpoint URL")
+	against := fs.String("against", "", "Committed schema file (SDL or introspection JSON) to compare the endpoint to")
+	failOn := fs.String("fail-on", "any", "Changes that fail the check: 'any', 'dangerous' (dangerous or breaking) or 'breaking'")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(endpoint, "e", *endpoint, "The GraphQL endpoint URL (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq check --endpoint <url> --against <file> [--fail-on any|dangerous|breaking] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	rejectPositional(fs)
+
+	if *endpoint == "" || *against == "" {
+		fmt.Println("Error: check needs both --endpoint and --against")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if err := validateEndpoint(*endpoint); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	failLevels, ok := failOnLevels[*failOn]
+	if !ok {
+		fmt.Printf("Error: invalid --fail-on value '%s'. Expected 'any', 'dangerous' or 'breaking'\n", *failOn)
+		os.Exit(exitError)
+	}
+
+	committed, err := loadSchema(*against, &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+	live, err := loadSchema(*endpoint, &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+
+	committedSDL, liveSDL := normalizedSDL(committed), normalizedSDL(live)
+	if committedSDL == liveSDL {
+		fmt.Printf("Schema at %s matches %s\n", *endpoint, *against)
+		return
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(committedSDL),
+		B:        difflib.SplitLines(liveSDL),
+		FromFile: *against,
+		ToFile:   *endpoint,
+		Context:  3,
+	})
+	if err != nil {
+		fmt.Printf("Error computing diff: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Print(diff)
+	fmt.Println()
+
+	changes := geq.Diff(committed, live)
+	if err := writeChanges(os.Stdout, changes); err != nil {
+		fmt.Printf("Error writing changes: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	// Changes the comparison does not classify, such as a reordered argument
+	// list, only fail a check that fails on any difference.
+	failed := *failOn == "any"
+	for _, change := range changes {
+		for _, level := range failLevels {
+			failed = failed || change.Criticality == level
+		}
+	}
+	if failed {
+		fmt.Printf("Schema at %s has drifted from %s\n", *endpoint, *against)
+		os.Exit(exitSchemaChanged)
+	}
+}
+
+// normalizedSDL prints a schema in a canonical form for comparison: sorted,
+// without built-in scalars and directives, and formatted the same way no
+// matter whether it came from SDL or introspection.
+func normalizedSDL(schema *geq.Schema) string {
+	return geq.GenerateSDLWithOptions(geq.SchemaToIntrospection(schema), geq.PrintOptions{
+		Sort:         geq.SortAlphabetical,
+		OmitBuiltins: true,
+	})
+}
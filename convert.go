@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// runConvert implements "geq convert": it reads an introspection result or an
+// SDL document from a file or stdin and writes it through the same SDL/JSON
+// output pipeline as a fetched schema, without any network call.
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	input := fs.String("input", "", "Schema file to convert, or '-' for stdin")
+	from := fs.String("from", "", "Input format: 'json' (introspection result) or 'sdl' (default: from the file extension, else json)")
+	to := fs.String("to", "sdl", "Output format: 'sdl' or 'json'")
+	outputFile := fs.String("output", "", "Output file path for the schema (SDL or JSON), or '-' for stdout")
+	asJSON := fs.Bool("json", false, "Output as JSON (same as --to json)")
+	minify := fs.Bool("minify", false, "Generate an additional minified schema file (no descriptions)")
+	var printing printFlags
+	printing.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(input, "i", *input, "Input file (shorthand)")
+	fs.StringVar(outputFile, "o", *outputFile, "Output file path (shorthand)")
+	fs.BoolVar(asJSON, "j", *asJSON, "Output as JSON (shorthand)")
+	fs.BoolVar(minify, "m", *minify, "Generate minified schema (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq convert --input <file|-> [--from json|sdl] [--to sdl|json] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	rejectPositional(fs)
+
+	if *input == "" {
+		fmt.Println("Error: input file is required (use '-' for stdin)")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	checkMinifyOutput(*minify, *outputFile)
+
+	inputFormat := *from
+	if inputFormat == "" {
+		inputFormat = formatFromExtension(*input)
+	}
+	switch *to {
+	case "sdl":
+	case "json":
+		*asJSON = true
+	default:
+		fmt.Printf("Error: invalid --to value '%s'. Expected 'sdl' or 'json'\n", *to)
+		os.Exit(exitError)
+	}
+
+	if inputFormat != "json" && inputFormat != "sdl" {
+		fmt.Printf("Error: invalid --from value '%s'. Expected 'json' or 'sdl'\n", inputFormat)
+		os.Exit(exitError)
+	}
+
+	// An introspection result printed as SDL is decoded while it is read;
+	// everything else is converted in memory first.
+	if inputFormat == "json" && !*asJSON {
+		in, err := openInput(*input)
+		if err != nil {
+			fmt.Printf("Error reading input: %v\n", err)
+			os.Exit(exitError)
+		}
+		err = writeSchemaOutputs(in, *outputFile, false, *minify, printing.options())
+		in.Close()
+		if err != nil {
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	data, err := readInput(*input)
+	if err != nil {
+		fmt.Printf("Error reading input: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	var introspectionJSON []byte
+	if inputFormat == "json" {
+		introspectionJSON, err = geq.NormalizeIntrospectionJSON(data)
+	} else {
+		introspectionJSON, err = sdlToIntrospectionJSON(data)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	if err := writeSchemaOutputs(bytes.NewReader(introspectionJSON), *outputFile, *asJSON, *minify, printing.options()); err != nil {
+		os.Exit(exitError)
+	}
+}
+
+// sdlToIntrospectionJSON parses an SDL document and returns the equivalent
+// introspection result as JSON.
+func sdlToIntrospectionJSON(data []byte) ([]byte, error) {
+	schema, err := geq.ParseSDL(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing SDL: %w", err)
+	}
+	return json.Marshal(geq.SchemaToIntrospection(schema))
+}
+
+// formatFromExtension guesses the format of a schema file from its name:
+// "sdl" for .graphql, .graphqls and .gql files, "json" otherwise.
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".graphql", ".graphqls", ".gql":
+		return "sdl"
+	}
+	return "json"
+}
+
+// openInput opens a file for reading, or returns stdin if path is "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// readInput reads a whole file, or stdin if path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
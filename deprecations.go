@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pzurek/geq/pkg/geq"
+	"github.com/pzurek/geq/pkg/geq/store"
+)
+
+// deprecationRow is a deprecated element as "geq deprecations" reports it.
+type deprecationRow struct {
+	geq.Deprecation
+	// FirstSeen is the first snapshot, from --since on, in which the element
+	// was deprecated; empty if none was, or without --since.
+	FirstSeen string `json:"firstSeen,omitempty"`
+}
+
+// runDeprecations implements "geq deprecations": it lists every deprecated
+// field, argument, input field and enum value of a schema, optionally with the
+// snapshot in which each was first seen deprecated.
+func runDeprecations(args []string) {
+	fs := flag.NewFlagSet("deprecations", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: 'table', 'json' or 'csv'")
+	since := fs.String("since", "", "Snapshot to search the history from for the first snapshot each element was deprecated in")
+	dir := fs.String("dir", store.DefaultDir, "Directory the snapshots are kept in, for --since")
+	historyEndpoint := fs.String("endpoint", "", "Endpoint whose snapshots --since searches; defaults to the schema URL")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(historyEndpoint, "e", *historyEndpoint, "Endpoint whose snapshots --since searches (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq deprecations [options] <schema>\n\n")
+		fmt.Fprintf(fs.Output(), "The schema is an endpoint URL, an SDL file (.graphql, .graphqls, .gql) or an introspection JSON file.\n\n")
+		fs.PrintDefaults()
+	}
+	sources := parseInterspersed(fs, args)
+	if len(sources) != 1 {
+		fmt.Println("Error: deprecations needs exactly one schema")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if *format != "table" && *format != "json" && *format != "csv" {
+		fmt.Printf("Error: invalid --format value '%s'. Expected 'table', 'json' or 'csv'\n", *format)
+		os.Exit(exitError)
+	}
+
+	schema, err := loadSchema(sources[0], &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+	rows := make([]deprecationRow, 0)
+	for _, deprecation := range geq.Deprecations(schema) {
+		rows = append(rows, deprecationRow{Deprecation: deprecation})
+	}
+
+	if *since != "" {
+		if *historyEndpoint == "" && isEndpoint(sources[0]) {
+			*historyEndpoint = sources[0]
+		}
+		firstSeen := firstDeprecatedIn(store.New(*dir), *historyEndpoint, *since)
+		for i := range rows {
+			rows[i].FirstSeen = firstSeen[rows[i].Coordinate]
+		}
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(rows)
+	case "csv":
+		err = writeDeprecationsCSV(os.Stdout, rows, *since != "")
+	default:
+		err = writeDeprecationsTable(os.Stdout, rows, *since != "")
+	}
+	if err != nil {
+		fmt.Printf("Error writing deprecations: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// firstDeprecatedIn returns, for each coordinate deprecated in a snapshot
+// from the one since names on, the ID of the first such snapshot. It exits if
+// the history cannot be read.
+func firstDeprecatedIn(snapshots *store.Store, endpoint, since string) map[string]string {
+	start := findSnapshot(snapshots, endpoint, since)
+	list, err := snapshots.List(start.Metadata.Endpoint)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	firstSeen := make(map[string]string)
+	for _, snapshot := range list {
+		if snapshot.ID < start.ID {
+			continue
+		}
+		for _, deprecation := range geq.Deprecations(readSnapshotSchema(snapshot)) {
+			if _, seen := firstSeen[deprecation.Coordinate]; !seen {
+				firstSeen[deprecation.Coordinate] = snapshot.ID
+			}
+		}
+	}
+	return firstSeen
+}
+
+// writeDeprecationsTable writes the deprecations as an aligned table, followed
+// by a count.
+func writeDeprecationsTable(w io.Writer, rows []deprecationRow, withFirstSeen bool) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No deprecations found")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "COORDINATE\tKIND\tPARENT TYPE\tREASON"
+	if withFirstSeen {
+		header += "\tFIRST SEEN"
+	}
+	fmt.Fprintln(tw, header)
+	for _, row := range rows {
+		// Keep each deprecation on one line
+		line := strings.Join([]string{row.Coordinate, string(row.Kind), row.ParentType, strings.Join(strings.Fields(row.Reason), " ")}, "\t")
+		if withFirstSeen {
+			line += "\t" + orDash(row.FirstSeen)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	noun := "deprecations"
+	if len(rows) == 1 {
+		noun = "deprecation"
+	}
+	_, err := fmt.Fprintf(w, "\n%d %s\n", len(rows), noun)
+	return err
+}
+
+// writeDeprecationsCSV writes the deprecations as CSV with a header row.
+func writeDeprecationsCSV(w io.Writer, rows []deprecationRow, withFirstSeen bool) error {
+	cw := csv.NewWriter(w)
+	header := []string{"coordinate", "kind", "parentType", "reason"}
+	if withFirstSeen {
+		header = append(header, "firstSeen")
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{row.Coordinate, string(row.Kind), row.ParentType, row.Reason}
+		if withFirstSeen {
+			record = append(record, row.FirstSeen)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// runDiff implements "geq diff": it compares two schemas, each an endpoint
+// URL, an SDL file or an introspection JSON file, and lists the changes
+// between them. It exits with exitSchemaChanged if any change is breaking.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: 'text' or 'json'")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq diff [options] <old> <new>\n\n")
+		fmt.Fprintf(fs.Output(), "Each schema is an endpoint URL, an SDL file (.graphql, .graphqls, .gql) or an introspection JSON file.\n\n")
+		fs.PrintDefaults()
+	}
+	schemas := parseInterspersed(fs, args)
+	if len(schemas) != 2 {
+		fmt.Println("Error: diff needs exactly two schemas, the old and the new one")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Printf("Error: invalid --format value '%s'. Expected 'text' or 'json'\n", *format)
+		os.Exit(exitError)
+	}
+
+	oldSchema, err := loadSchema(schemas[0], &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+	newSchema, err := loadSchema(schemas[1], &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+
+	changes := geq.Diff(oldSchema, newSchema)
+	if *format == "json" {
+		err = writeChangesJSON(os.Stdout, changes)
+	} else {
+		err = writeChanges(os.Stdout, changes)
+	}
+	if err != nil {
+		fmt.Printf("Error writing changes: %v\n", err)
+		os.Exit(exitError)
+	}
+	if geq.HasBreakingChanges(changes) {
+		os.Exit(exitSchemaChanged)
+	}
+}
+
+// writeChanges lists the changes one per line, with their criticality,
+// followed by a summary.
+func writeChanges(w io.Writer, changes []geq.Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes detected")
+		return err
+	}
+	counts := make(map[geq.Criticality]int)
+	for _, change := range changes {
+		counts[change.Criticality]++
+		if _, err := fmt.Fprintf(w, "%-12s  %s\n", change.Criticality, change.Message); err != nil {
+			return err
+		}
+	}
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+	_, err := fmt.Fprintf(w, "\n%d %s: %d breaking, %d dangerous, %d non-breaking\n", len(changes), noun,
+		counts[geq.CriticalityBreaking], counts[geq.CriticalityDangerous], counts[geq.CriticalityNonBreaking])
+	return err
+}
+
+// writeChangesJSON writes the changes as an indented JSON array.
+func writeChangesJSON(w io.Writer, changes []geq.Change) error {
+	if changes == nil {
+		changes = []geq.Change{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(changes)
+}
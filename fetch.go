@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// fetchFlags are the options that control how schemas are fetched from an
+// endpoint. Every command that accepts an endpoint registers them.
+type fetchFlags struct {
+	headers           headerSources
+	timeout           time.Duration
+	retries           int
+	retryWait         time.Duration
+	retryMaxWait      time.Duration
+	probe             bool
+	queryFile         string
+	descriptions      bool
+	includeDeprecated bool
+	typeDepth         int
+	verbose           bool
+}
+
+// register adds the fetch flags to fs.
+func (f *fetchFlags) register(fs *flag.FlagSet) {
+	f.headers.register(fs)
+	fs.DurationVar(&f.timeout, "timeout", 0, "Timeout for the introspection request (e.g. 30s); 0 means no timeout")
+	fs.IntVar(&f.retries, "retries", 0, "Number of times to retry transient failures (network errors, 408, 429, 502, 503, 504)")
+	fs.DurationVar(&f.retryWait, "retry-wait", geq.DefaultMinBackoff, "Initial wait between retries; doubles on each retry")
+	fs.DurationVar(&f.retryMaxWait, "retry-max-wait", geq.DefaultMaxBackoff, "Maximum wait between retries")
+	fs.BoolVar(&f.probe, "probe", true, "Probe the server for newer introspection fields (isRepeatable, specifiedByURL, ...) before fetching")
+	fs.StringVar(&f.queryFile, "query-file", "", "File with a custom introspection query to send instead of the built-in one")
+	fs.BoolVar(&f.descriptions, "descriptions", true, "Request descriptions in the introspection query")
+	fs.BoolVar(&f.includeDeprecated, "include-deprecated", true, "Request deprecated fields, enum values, arguments and input fields")
+	fs.IntVar(&f.typeDepth, "type-depth", geq.DefaultTypeRefDepth, "Levels of ofType nesting requested for type references")
+	fs.BoolVar(&f.verbose, "verbose", false, "Log requests and responses to stderr (secrets are masked)")
+}
+
+// queryOptions returns the introspection query settings given by the flags.
+func (f *fetchFlags) queryOptions() geq.QueryOptions {
+	queryOpts := geq.DefaultQueryOptions()
+	queryOpts.Descriptions = f.descriptions
+	queryOpts.IncludeDeprecated = f.includeDeprecated
+	queryOpts.TypeRefDepth = f.typeDepth
+	return queryOpts
+}
+
+// customQuery returns the query read from --query-file, or "" if none is set.
+func (f *fetchFlags) customQuery() (string, error) {
+	if f.queryFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(f.queryFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading query file: %w", err)
+	}
+	return string(data), nil
+}
+
+// client builds a client for endpoint. It also returns the headers the client
+// sends and a redactor that masks the secrets among them; use it on every
+// error or log message, including the error returned here.
+func (f *fetchFlags) client(endpoint string) (*geq.Client, http.Header, *redactor, error) {
+	// Collect headers from every source; secrets are masked in all output
+	requestHeaders, secrets, err := f.headers.load()
+	if err != nil {
+		return nil, nil, secrets, err
+	}
+	if err := validateEndpoint(endpoint); err != nil {
+		return nil, nil, secrets, err
+	}
+	customQuery, err := f.customQuery()
+	if err != nil {
+		return nil, nil, secrets, err
+	}
+
+	opts := []geq.Option{
+		geq.WithHeaders(requestHeaders),
+		geq.WithTimeout(f.timeout),
+		geq.WithUserAgent("geq/" + version),
+		geq.WithCapabilityProbe(f.probe),
+		geq.WithQueryOptions(f.queryOptions()),
+		geq.WithRetry(geq.RetryPolicy{
+			MaxRetries: f.retries,
+			MinBackoff: f.retryWait,
+			MaxBackoff: f.retryMaxWait,
+		}),
+	}
+	if f.verbose {
+		opts = append(opts, geq.WithLogger(secrets.logger()))
+	}
+	if customQuery != "" {
+		opts = append(opts, geq.WithQuery(customQuery))
+	}
+	return geq.NewClient(endpoint, opts...), requestHeaders, secrets, nil
+}
+
+// isEndpoint reports whether a schema source names an endpoint rather than a file.
+func isEndpoint(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// validateEndpoint checks that an --endpoint value is an http:// or https://
+// URL. Without a scheme, e.g. "localhost:4000/graphql", it would be taken for
+// a file name where a schema source may be either.
+func validateEndpoint(endpoint string) error {
+	if isEndpoint(endpoint) {
+		return nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		return fmt.Errorf("endpoint '%s' is not an http:// or https:// URL; did you mean 'http://%s'?", endpoint, endpoint)
+	}
+	return fmt.Errorf("endpoint '%s' is not an http:// or https:// URL", endpoint)
+}
+
+// loadSchema loads a schema from an endpoint URL, fetched with the fetch
+// flags, or from a file: SDL for .graphql, .graphqls and .gql files, an
+// introspection result otherwise. A source of "-" reads an introspection
+// result from stdin. Secrets are masked in the returned error, which wraps
+// the *geq.IntrospectionError of a server that returned no schema.
+func loadSchema(source string, fetching *fetchFlags) (*geq.Schema, error) {
+	if isEndpoint(source) {
+		client, _, secrets, err := fetching.client(source)
+		if err != nil {
+			return nil, secrets.wrap(err)
+		}
+		response, err := client.FetchIntrospection(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("error fetching schema from %s: %w", source, secrets.wrap(err))
+		}
+		return geq.NewSchema(response), nil
+	}
+
+	in, err := openInput(source)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema: %w", err)
+	}
+	defer in.Close()
+
+	if formatFromExtension(source) == "sdl" {
+		schema, err := geq.ParseSDL(in)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing SDL in %s: %w", source, err)
+		}
+		return schema, nil
+	}
+	response, err := geq.DecodeIntrospection(in)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", source, err)
+	}
+	return geq.NewSchema(response), nil
+}
+
+// exitWithLoadError reports an error returned by loadSchema and exits, with
+// exitIntrospectionDisabled if the server has introspection turned off.
+func exitWithLoadError(err error) {
+	fmt.Printf("Error: %v\n", err)
+	var introspectionErr *geq.IntrospectionError
+	if errors.As(err, &introspectionErr) && introspectionErr.IntrospectionDisabled() {
+		fmt.Println("The server appears to have introspection disabled.")
+		os.Exit(exitIntrospectionDisabled)
+	}
+	os.Exit(exitError)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// headerFlags collects repeated -H/--header values.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// headerSources describes every place the CLI can take request headers from.
+type headerSources struct {
+	headers     headerFlags // "name: value", with ${VAR} expansion
+	headersFile string      // file of "name: value" lines, with ${VAR} expansion
+	fromFile    headerFlags // "name=path", value read from the file
+	fromEnv     headerFlags // "name=VAR", value read from the environment
+}
+
+// register adds the header flags to fs.
+func (s *headerSources) register(fs *flag.FlagSet) {
+	fs.Var(&s.headers, "header", "Header in the format 'name: value' (repeatable; ${VAR} is expanded)")
+	fs.Var(&s.headers, "H", "Header in the format 'name: value' (shorthand, repeatable)")
+	fs.StringVar(&s.headersFile, "headers-file", "", "File with one 'name: value' header per line")
+	fs.Var(&s.fromFile, "header-from-file", "Header whose value is read from a file, as 'name=path' (repeatable)")
+	fs.Var(&s.fromEnv, "header-from-env", "Header whose value is read from an environment variable, as 'name=VAR' (repeatable)")
+}
+
+// load collects the headers from all sources. Headers file entries come
+// first, then --header, --header-from-file and --header-from-env. It also
+// returns a redactor that masks the secrets among them: the values read with
+// --header-from-file and --header-from-env, and the values of credential-like
+// headers such as Authorization. Other headers, e.g. X-Api-Version, are not
+// secret even when their value comes from an environment variable.
+func (s *headerSources) load() (http.Header, *redactor, error) {
+	result := make(http.Header)
+	r := &redactor{}
+	// Expanded variables are masked in parse errors, which quote the header
+	// before its name is known
+	expansions := &redactor{}
+
+	if s.headersFile != "" {
+		data, err := os.ReadFile(s.headersFile)
+		if err != nil {
+			return nil, r, fmt.Errorf("error reading headers file: %w", err)
+		}
+		expanded, err := expandEnv(string(data), expansions)
+		if err != nil {
+			return nil, r, fmt.Errorf("error in headers file '%s': %w", s.headersFile, err)
+		}
+		fileHeaders, err := geq.ReadHeaders(strings.NewReader(expanded))
+		if err != nil {
+			return nil, r, fmt.Errorf("error in headers file '%s': %s", s.headersFile, expansions.Error(err))
+		}
+		addHeaders(result, fileHeaders)
+	}
+
+	for _, headerStr := range s.headers {
+		expanded, err := expandEnv(headerStr, expansions)
+		if err != nil {
+			return nil, r, err
+		}
+		name, value, err := geq.ParseHeader(expanded)
+		if err != nil {
+			return nil, r, errors.New(expansions.Error(err))
+		}
+		result.Add(name, value)
+	}
+
+	for _, source := range s.fromFile {
+		name, path, err := splitHeaderSource(source, "header-from-file")
+		if err != nil {
+			return nil, r, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, r, fmt.Errorf("error reading value for header '%s': %w", name, err)
+		}
+		value := strings.TrimSpace(string(data))
+		r.add(value)
+		result.Add(name, value)
+	}
+
+	for _, source := range s.fromEnv {
+		name, variable, err := splitHeaderSource(source, "header-from-env")
+		if err != nil {
+			return nil, r, err
+		}
+		value, ok := os.LookupEnv(variable)
+		if !ok {
+			return nil, r, fmt.Errorf("environment variable '%s' for header '%s' is not set", variable, name)
+		}
+		r.add(value)
+		result.Add(name, value)
+	}
+
+	// Values of credential-like headers are secrets no matter where they came
+	// from, and so are their credentials without an authentication scheme
+	for name, values := range result {
+		if geq.IsSensitiveHeader(name) {
+			for _, value := range values {
+				r.add(value)
+				if _, credentials, ok := strings.Cut(value, " "); ok {
+					r.add(strings.TrimSpace(credentials))
+				}
+			}
+		}
+	}
+
+	return result, r, nil
+}
+
+// addHeaders appends every value in src to dst.
+func addHeaders(dst, src http.Header) {
+	for name, values := range src {
+		for _, value := range values {
+			dst.Add(name, value)
+		}
+	}
+}
+
+// splitHeaderSource splits a "name=source" flag value.
+func splitHeaderSource(value, flagName string) (string, string, error) {
+	name, source, ok := strings.Cut(value, "=")
+	name, source = strings.TrimSpace(name), strings.TrimSpace(source)
+	if !ok || name == "" || source == "" {
+		return "", "", fmt.Errorf("invalid --%s value. Expected 'name=source', got '%s'", flagName, value)
+	}
+	return name, source, nil
+}
+
+// expandEnv replaces ${VAR} and $VAR references with the values of
+// environment variables, registering each value with r. "$$" produces a
+// literal "$". Referencing an unset variable is an error, so a missing token
+// is never silently sent as an empty string.
+func expandEnv(s string, r *redactor) (string, error) {
+	var missing []string
+	expanded := os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+			return ""
+		}
+		r.add(value)
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable(s) not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// redactor masks secret values in text printed by the CLI.
+type redactor struct {
+	secrets []string
+}
+
+// minSecretLength is the length below which values are not masked: replacing
+// every "1" or "ab" would garble unrelated output such as addresses and
+// ports. The headers themselves are still masked by name in logs.
+const minSecretLength = 4
+
+// add registers a secret value. Values shorter than minSecretLength are
+// ignored.
+func (r *redactor) add(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	for _, existing := range r.secrets {
+		if existing == secret {
+			return
+		}
+	}
+	r.secrets = append(r.secrets, secret)
+	// Replace longer secrets first so that a secret containing another is fully masked.
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+}
+
+// String returns s with every registered secret masked.
+func (r *redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, "****")
+	}
+	return s
+}
+
+// Error returns the redacted message of err.
+func (r *redactor) Error(err error) string {
+	return r.String(err.Error())
+}
+
+// wrap returns err with its message redacted. The original error stays
+// available to errors.Is and errors.As.
+func (r *redactor) wrap(err error) error {
+	return &redactedError{message: r.Error(err), err: err}
+}
+
+// redactedError is an error whose message has its secrets masked.
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string { return e.message }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// logger returns a verbose logger writing to stderr with secrets masked.
+func (r *redactor) logger() geq.Logger {
+	return &redactingLogger{r: r, l: log.New(os.Stderr, "geq: ", 0)}
+}
+
+// redactingLogger masks secrets before writing log lines.
+type redactingLogger struct {
+	r *redactor
+	l *log.Logger
+}
+
+func (l *redactingLogger) Printf(format string, v ...any) {
+	l.l.Print(l.r.String(fmt.Sprintf(format, v...)))
+}
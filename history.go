@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pzurek/geq/pkg/geq"
+	"github.com/pzurek/geq/pkg/geq/store"
+)
+
+// historyUsage describes the "geq history" subcommands.
+const historyUsage = `Usage: geq history <command> [options]
+
+Commands:
+  list                  List the snapshots of each endpoint
+  show <snapshot>       Print a snapshot (--metadata prints its metadata instead)
+  diff <old> <new>      List the changes between two snapshots
+
+A snapshot is named by its ID, an RFC 3339 timestamp, the start of one
+(e.g. 2024-05, the latest snapshot of May 2024), "latest" or "latest~N".
+`
+
+// runHistory implements "geq history": it lists, prints and compares the
+// snapshots saved by "geq snapshot".
+func runHistory(args []string) {
+	if len(args) == 0 {
+		fmt.Print(historyUsage)
+		os.Exit(exitError)
+	}
+	command, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("history "+command, flag.ExitOnError)
+	dir := fs.String("dir", store.DefaultDir, "Directory the snapshots are kept in")
+	endpoint := fs.String("endpoint", "", "Endpoint whose snapshots to use; optional when the directory has snapshots of a single endpoint")
+	fs.StringVar(endpoint, "e", *endpoint, "Endpoint whose snapshots to use (shorthand)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), historyUsage+"\n")
+		fs.PrintDefaults()
+	}
+
+	switch command {
+	case "list":
+		parseHistoryArgs(fs, args, 0)
+		historyList(store.New(*dir), *endpoint)
+	case "show":
+		metadata := fs.Bool("metadata", false, "Print the metadata of the snapshot as JSON instead of the schema")
+		refs := parseHistoryArgs(fs, args, 1)
+		historyShow(store.New(*dir), *endpoint, refs[0], *metadata)
+	case "diff":
+		format := fs.String("format", "text", "Output format: 'text' or 'json'")
+		refs := parseHistoryArgs(fs, args, 2)
+		if *format != "text" && *format != "json" {
+			fmt.Printf("Error: invalid --format value '%s'. Expected 'text' or 'json'\n", *format)
+			os.Exit(exitError)
+		}
+		historyDiff(store.New(*dir), *endpoint, refs[0], refs[1], *format)
+	default:
+		fmt.Printf("Error: unknown history command '%s'\n", command)
+		fmt.Print(historyUsage)
+		os.Exit(exitError)
+	}
+}
+
+// parseHistoryArgs parses the flags of a history command and returns its
+// positional arguments, exiting unless there are exactly count of them.
+func parseHistoryArgs(fs *flag.FlagSet, args []string, count int) []string {
+	positional := parseInterspersed(fs, args)
+	if len(positional) != count {
+		fmt.Printf("Error: %s takes %d snapshot(s), got %d\n", fs.Name(), count, len(positional))
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	return positional
+}
+
+// historyList prints the snapshots of an endpoint, or of every endpoint in
+// the store if none is given.
+func historyList(snapshots *store.Store, endpoint string) {
+	endpoints := []string{endpoint}
+	if endpoint == "" {
+		var err error
+		if endpoints, err = snapshots.Endpoints(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if len(endpoints) == 0 {
+			fmt.Printf("No snapshots in %s\n", snapshots.Dir())
+			return
+		}
+	}
+
+	for i, endpoint := range endpoints {
+		list, err := snapshots.List(endpoint)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(endpoint)
+		if len(list) == 0 {
+			fmt.Println("  No snapshots")
+		}
+		for j, snapshot := range list {
+			note := ""
+			if j > 0 && snapshot.Metadata.SHA256 == list[j-1].Metadata.SHA256 {
+				note = "  unchanged"
+			}
+			fmt.Printf("  %s  %-12s  %8s%s\n", snapshot.ID, shortHash(snapshot.Metadata.SHA256),
+				snapshot.Metadata.FetchDuration.Round(time.Millisecond), note)
+		}
+	}
+}
+
+// historyShow prints a snapshot, or its metadata.
+func historyShow(snapshots *store.Store, endpoint, ref string, metadata bool) {
+	snapshot := findSnapshot(snapshots, endpoint, ref)
+	if metadata {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(snapshot.Metadata); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+	schema, err := snapshot.Read()
+	if err != nil {
+		fmt.Printf("Error reading snapshot: %v\n", err)
+		os.Exit(exitError)
+	}
+	os.Stdout.Write(schema)
+}
+
+// historyDiff lists the changes between two snapshots of an endpoint.
+func historyDiff(snapshots *store.Store, endpoint, oldRef, newRef, format string) {
+	oldSchema := readSnapshotSchema(findSnapshot(snapshots, endpoint, oldRef))
+	newSchema := readSnapshotSchema(findSnapshot(snapshots, endpoint, newRef))
+
+	changes := geq.Diff(oldSchema, newSchema)
+	var err error
+	if format == "json" {
+		err = writeChangesJSON(os.Stdout, changes)
+	} else {
+		err = writeChanges(os.Stdout, changes)
+	}
+	if err != nil {
+		fmt.Printf("Error writing changes: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// findSnapshot returns the snapshot a reference names, exiting if there is
+// none. Without an endpoint, the store must hold the snapshots of exactly one.
+func findSnapshot(snapshots *store.Store, endpoint, ref string) *store.Snapshot {
+	if endpoint == "" {
+		endpoints, err := snapshots.Endpoints()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		switch len(endpoints) {
+		case 0:
+			fmt.Printf("Error: no snapshots in %s\n", snapshots.Dir())
+			os.Exit(exitError)
+		case 1:
+			endpoint = endpoints[0]
+		default:
+			fmt.Printf("Error: %s has snapshots of several endpoints; choose one with --endpoint:\n  %s\n",
+				snapshots.Dir(), strings.Join(endpoints, "\n  "))
+			os.Exit(exitError)
+		}
+	}
+
+	snapshot, err := snapshots.Find(endpoint, ref)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	return snapshot
+}
+
+// readSnapshotSchema reads and parses the schema of a snapshot, exiting on error.
+func readSnapshotSchema(snapshot *store.Snapshot) *geq.Schema {
+	data, err := snapshot.Read()
+	if err != nil {
+		fmt.Printf("Error reading snapshot: %v\n", err)
+		os.Exit(exitError)
+	}
+	schema, err := geq.ParseSDL(bytes.NewReader(data))
+	if err != nil {
+		fmt.Printf("Error parsing snapshot %s: %v\n", snapshot.ID, err)
+		os.Exit(exitError)
+	}
+	return schema
+}
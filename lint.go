@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// defaultLintConfigFile is the lint configuration used when --config is not
+// given, if it exists in the current directory.
+const defaultLintConfigFile = ".geq-lint.json"
+
+// lintResult is a lint problem together with the schema it was found in.
+type lintResult struct {
+	// Schema is the file or endpoint the problem was found in.
+	Schema string `json:"schema"`
+	geq.LintProblem
+}
+
+// runLint implements "geq lint": it checks schemas against the lint rules and
+// exits with exitLintErrors if any problem has error severity.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	configFile := fs.String("config", "", "JSON file that sets the severity of lint rules (default "+defaultLintConfigFile+" if it exists)")
+	format := fs.String("format", "text", "Output format: 'text' or 'json'")
+	printConfig := fs.Bool("print-config", false, "Print the configuration in effect as JSON and exit")
+	listRules := fs.Bool("list-rules", false, "List the lint rules with their default severities and exit")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq lint [options] <schema>...\n\n")
+		fmt.Fprintf(fs.Output(), "Each schema is an SDL file (.graphql, .graphqls, .gql), an introspection JSON file or an endpoint URL.\n\n")
+		fs.PrintDefaults()
+	}
+	sources := parseInterspersed(fs, args)
+	if *format != "text" && *format != "json" {
+		fmt.Printf("Error: invalid --format value '%s'. Expected 'text' or 'json'\n", *format)
+		os.Exit(exitError)
+	}
+
+	if *listRules {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, rule := range geq.LintRules {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", rule.Name, rule.Severity, rule.Description)
+		}
+		tw.Flush()
+		return
+	}
+
+	config, err := loadLintConfig(*configFile)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if *printConfig {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(config); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	if len(sources) == 0 {
+		fmt.Println("Error: lint needs at least one schema")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	results := make([]lintResult, 0)
+	for _, source := range sources {
+		schema, err := loadSchema(source, &fetching)
+		if err != nil {
+			exitWithLoadError(err)
+		}
+		problems, err := geq.Lint(schema, config)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		for _, problem := range problems {
+			results = append(results, lintResult{Schema: source, LintProblem: problem})
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(results)
+	} else {
+		err = writeLintResults(os.Stdout, results)
+	}
+	if err != nil {
+		fmt.Printf("Error writing lint results: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	for _, result := range results {
+		if result.Severity == geq.SeverityError {
+			os.Exit(exitLintErrors)
+		}
+	}
+}
+
+// loadLintConfig reads the lint configuration from path. Without a path, it
+// reads defaultLintConfigFile if it exists, and otherwise returns the default
+// configuration.
+func loadLintConfig(path string) (geq.LintConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultLintConfigFile
+	}
+	f, err := os.Open(path)
+	if !explicit && errors.Is(err, os.ErrNotExist) {
+		return geq.DefaultLintConfig(), nil
+	}
+	if err != nil {
+		return geq.LintConfig{}, fmt.Errorf("error reading lint configuration: %w", err)
+	}
+	defer f.Close()
+
+	config, err := geq.ReadLintConfig(f)
+	if err != nil {
+		return geq.LintConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	// Show the full configuration in effect: rules not listed keep their defaults
+	merged := geq.DefaultLintConfig()
+	for name, severity := range config.Rules {
+		merged.Rules[name] = severity
+	}
+	return merged, nil
+}
+
+// writeLintResults lists the problems one per line, as
+// "schema:line:column: severity: coordinate: message (rule)", followed by a
+// summary. The line and column are left out for schemas read from
+// introspection.
+func writeLintResults(w io.Writer, results []lintResult) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No problems found")
+		return err
+	}
+	errorCount := 0
+	for _, result := range results {
+		if result.Severity == geq.SeverityError {
+			errorCount++
+		}
+		position := result.Schema
+		if result.Location.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d", position, result.Location.Line, result.Location.Column)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s: %s (%s)\n", position, result.Severity, result.Coordinate, result.Message, result.Rule); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%s (%s, %s)\n", plural(len(results), "problem"), plural(errorCount, "error"), plural(len(results)-errorCount, "warning"))
+	return err
+}
+
+// plural formats a count with a noun, adding an "s" unless the count is one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
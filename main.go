@@ -2,10 +2,14 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/pzurek/geq/pkg/geq"
 )
@@ -14,35 +18,141 @@ import (
 var version = "dev"
 var buildTime = "unset"
 
+// Exit codes
+const (
+	exitError                 = 1 // Generic failure
+	exitIntrospectionDisabled = 3 // The server has introspection turned off
+	exitSchemaChanged         = 4 // A schema comparison found the changes it fails on
+	exitLintErrors            = 5 // Lint found problems with error severity
+	exitInvalidSchema         = 6 // Schema validation found errors
+	exitInvalidOperations     = 7 // Operation validation found errors
+)
+
 // writeSchemaFile handles file writing and console output for the CLI.
 func writeSchemaFile(outputPath string, content string) error {
-	err := os.WriteFile(outputPath, []byte(content), 0644)
+	return writeOutput(outputPath, "schema", func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+}
+
+// parseInterspersed parses args with fs and returns the positional
+// arguments. Unlike fs.Parse, it also accepts flags after them, as in
+// "geq diff old.graphql new.graphql --format json".
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args) // The flag sets exit on error
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// rejectPositional exits with an error if fs was given positional arguments,
+// for commands that take none. Parsing stops at the first positional
+// argument, so the flags after it would otherwise be silently ignored.
+func rejectPositional(fs *flag.FlagSet) {
+	if fs.NArg() == 0 {
+		return
+	}
+	fmt.Printf("Error: unexpected argument '%s'\n", fs.Arg(0))
+	fs.Usage()
+	os.Exit(exitError)
+}
+
+// writeOutput creates the file at outputPath and fills it with write, or
+// writes to stdout if outputPath is "-". The result is reported on stderr, so
+// that stdout only ever carries the output itself; what names the output in
+// those messages, e.g. "schema" or "changelog".
+func writeOutput(outputPath, what string, write func(w io.Writer) error) error {
+	if outputPath == "-" {
+		if err := write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s to stdout: %v\n", what, err)
+			return err
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err == nil {
+		err = write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
-		fmt.Printf("Error writing schema to file '%s': %v\n", outputPath, err)
+		fmt.Fprintf(os.Stderr, "Error writing %s to file '%s': %v\n", what, outputPath, err)
 		return err // Return error for main to handle exit
 	}
-	fmt.Printf("Schema successfully saved to %s\n", outputPath)
+	fmt.Fprintf(os.Stderr, "%s%s successfully saved to %s\n", strings.ToUpper(what[:1]), what[1:], outputPath)
 	return nil
 }
 
 func main() {
+	// Dispatch subcommands; without one, geq fetches a schema from an endpoint
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "convert":
+			runConvert(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "check":
+			runCheck(os.Args[2:])
+			return
+		case "changelog":
+			runChangelog(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		case "snapshot":
+			runSnapshot(os.Args[2:])
+			return
+		case "history":
+			runHistory(os.Args[2:])
+			return
+		case "deprecations":
+			runDeprecations(os.Args[2:])
+			return
+		case "lint":
+			runLint(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		case "validate-ops":
+			runValidateOps(os.Args[2:])
+			return
+		}
+	}
+
 	// Parse command line arguments
 	endpoint := flag.String("endpoint", "", "The GraphQL endpoint URL")
-	header := flag.String("header", "", "Header in the format 'name: value'")
-	outputFile := flag.String("output", "", "Output file path for the schema (SDL or JSON)")
+	var fetching fetchFlags
+	fetching.register(flag.CommandLine)
+	outputFile := flag.String("output", "", "Output file path for the schema (SDL or JSON), or '-' for stdout")
 	asJSON := flag.Bool("json", false, "Output as JSON")
 	versionFlag := flag.Bool("version", false, "Show version information")
 	minify := flag.Bool("minify", false, "Generate an additional minified schema file (no descriptions)")
+	printQuery := flag.Bool("print-query", false, "Print the introspection query geq would send and exit")
+	var printing printFlags
+	printing.register(flag.CommandLine)
 
 	// Short flag aliases
 	flag.StringVar(endpoint, "e", *endpoint, "The GraphQL endpoint URL (shorthand)")
-	flag.StringVar(header, "H", *header, "Header in the format 'name: value' (shorthand)")
 	flag.StringVar(outputFile, "o", *outputFile, "Output file path (shorthand)")
 	flag.BoolVar(asJSON, "j", *asJSON, "Output as JSON (shorthand)")
 	flag.BoolVar(versionFlag, "v", *versionFlag, "Show version information (shorthand)")
 	flag.BoolVar(minify, "m", *minify, "Generate minified schema (shorthand)")
 
 	flag.Parse()
+	rejectPositional(flag.CommandLine)
 
 	// Show version if requested
 	if *versionFlag {
@@ -50,101 +160,161 @@ func main() {
 		return
 	}
 
+	// Without an endpoint there is nothing to probe, so print the configured query
+	if *printQuery && *endpoint == "" {
+		customQuery, err := fetching.customQuery()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if customQuery == "" {
+			customQuery = geq.IntrospectionQuery(fetching.queryOptions())
+		}
+		fmt.Print(customQuery)
+		return
+	}
+
 	// Check if URL is provided
 	if *endpoint == "" {
 		fmt.Println("Error: GraphQL endpoint URL is required")
 		flag.Usage()
-		os.Exit(1)
+		os.Exit(exitError)
 	}
 
-	// Fetch schema data using the library function
-	introspectionJSON, err := geq.FetchIntrospectionJSON(*endpoint, *header)
+	checkMinifyOutput(*minify, *outputFile)
+
+	// Configure the client
+	client, _, secrets, err := fetching.client(*endpoint)
 	if err != nil {
-		fmt.Printf("Error fetching schema data: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error: %s\n", secrets.Error(err))
+		os.Exit(exitError)
 	}
 
-	// Determine main output path and format
-	mainOutputPath := *outputFile
-	outputIsJSON := *asJSON
-	mainSchemaContent := ""
-
-	if mainOutputPath == "" {
-		if outputIsJSON {
-			mainOutputPath = "schema.json"
-		} else {
-			mainOutputPath = "schema.graphql"
+	// Print the query (after probing the server, if enabled) instead of fetching
+	if *printQuery {
+		query, err := client.IntrospectionQuery(context.Background())
+		if err != nil {
+			fmt.Printf("Error building introspection query: %s\n", secrets.Error(err))
+			os.Exit(exitError)
 		}
+		fmt.Print(query)
+		return
 	}
 
-	// Generate main schema content (SDL or JSON)
-	if outputIsJSON {
-		// JSON output logic
-		var prettyJSON bytes.Buffer
-		if err := json.Indent(&prettyJSON, []byte(introspectionJSON), "", "  "); err != nil {
-			fmt.Printf("Error formatting JSON: %v\n", err)
-			os.Exit(1)
+	// Fetch schema data using the library client. JSON output is the response
+	// as the server sent it; for SDL the response is decoded as it is read.
+	var introspectionJSON string
+	var response geq.IntrospectionResponse
+	if *asJSON {
+		introspectionJSON, err = client.FetchIntrospectionJSON(context.Background())
+	} else {
+		response, err = client.FetchIntrospection(context.Background())
+	}
+	if err != nil {
+		fmt.Printf("Error fetching schema data: %s\n", secrets.Error(err))
+		var introspectionErr *geq.IntrospectionError
+		if errors.As(err, &introspectionErr) && introspectionErr.IntrospectionDisabled() {
+			fmt.Println("The server appears to have introspection disabled.")
+			os.Exit(exitIntrospectionDisabled)
 		}
-		mainSchemaContent = prettyJSON.String()
+		os.Exit(exitError)
+	}
+
+	// Write the schema files in the requested formats
+	if *asJSON {
+		err = writeSchemaOutputs(strings.NewReader(introspectionJSON), *outputFile, true, *minify, printing.options())
 	} else {
-		// SDL output logic
-		var introspectionResp geq.IntrospectionResponse
-		if err := json.Unmarshal([]byte(introspectionJSON), &introspectionResp); err != nil {
-			// Provide more context on JSON parsing error
-			fmt.Printf("Error parsing introspection JSON response: %v\n", err)
-			// Optionally print a snippet of the received JSON if it's not too large
-			snippet := introspectionJSON
-			if len(snippet) > 200 {
-				snippet = snippet[:200] + "..."
-			}
-			fmt.Printf("Received JSON snippet: %s\n", snippet)
-			os.Exit(1)
+		err = writeSDLOutputs(response, *outputFile, *minify, printing.options())
+	}
+	if err != nil {
+		os.Exit(exitError)
+	}
+}
+
+// checkMinifyOutput exits with an error if --minify is combined with writing
+// the schema to stdout: the minified copy would go to a file next to it, and
+// its status line would be mixed up with a piped schema.
+func checkMinifyOutput(minify bool, outputFile string) {
+	if minify && outputFile == "-" {
+		fmt.Println("Error: --minify cannot be used with --output -")
+		os.Exit(exitError)
+	}
+}
+
+// writeSchemaOutputs writes the introspection result read from input as SDL
+// or JSON, plus a minified copy if requested. SDL is printed with printOpts.
+// An outputFile of "-" writes the main output to stdout. Errors are reported
+// on stderr before they are returned.
+//
+// For SDL, the JSON is decoded as it is read and the SDL is streamed to the
+// output, so neither the raw JSON nor the printed schema is held in memory.
+func writeSchemaOutputs(input io.Reader, outputFile string, outputIsJSON, minify bool, printOpts geq.PrintOptions) error {
+	if outputIsJSON {
+		if outputFile == "" {
+			outputFile = "schema.json"
 		}
-		mainSchemaContent = geq.GenerateSDL(introspectionResp)
+		return writeJSONOutputs(input, outputFile, minify)
 	}
 
-	// Write main schema file using the local function
-	err = writeSchemaFile(mainOutputPath, mainSchemaContent)
+	introspectionResp, err := geq.DecodeIntrospection(input)
 	if err != nil {
-		os.Exit(1) // Exit if writing failed
+		fmt.Fprintf(os.Stderr, "Error parsing introspection JSON response: %v\n", err)
+		return err
+	}
+	return writeSDLOutputs(introspectionResp, outputFile, minify, printOpts)
+}
+
+// writeSDLOutputs prints an introspection result as SDL with printOpts to
+// outputFile, schema.graphql by default, plus a minified copy if requested.
+// Errors are reported on stderr before they are returned.
+func writeSDLOutputs(introspectionResp geq.IntrospectionResponse, outputFile string, minify bool, printOpts geq.PrintOptions) error {
+	if outputFile == "" {
+		outputFile = "schema.graphql"
+	}
+	err := writeOutput(outputFile, "schema", func(w io.Writer) error {
+		return geq.WriteSDL(w, introspectionResp, printOpts)
+	})
+	if err != nil {
+		return err
 	}
 
 	// Generate and write minified schema if requested
-	if *minify {
-		minifiedOutputPath := ""
-		minifiedSchemaContent := ""
-
-		// Determine minified output path
-		if outputIsJSON {
-			minifiedOutputPath = "schema.min.json"
-		} else {
-			minifiedOutputPath = "schema.min.graphql"
-		}
+	if minify {
+		minifyOpts := printOpts
+		minifyOpts.Minify = true
+		return writeOutput("schema.min.graphql", "schema", func(w io.Writer) error {
+			return geq.WriteSDL(w, introspectionResp, minifyOpts)
+		})
+	}
+	return nil
+}
 
-		// Generate minified schema content
-		if outputIsJSON {
-			// Minified JSON logic
-			var compactJSON bytes.Buffer
-			// Use json.Compact instead of Marshal for minification
-			if err := json.Compact(&compactJSON, []byte(introspectionJSON)); err != nil {
-				fmt.Printf("Error compacting JSON: %v\n", err)
-				os.Exit(1)
-			}
-			minifiedSchemaContent = compactJSON.String()
-		} else {
-			// Minified SDL logic
-			var introspectionResp geq.IntrospectionResponse
-			if err := json.Unmarshal([]byte(introspectionJSON), &introspectionResp); err != nil {
-				fmt.Printf("Error parsing introspection response for minify: %v\n", err)
-				os.Exit(1)
-			}
-			minifiedSchemaContent = geq.GenerateMinifiedSDL(introspectionResp)
-		}
+// writeJSONOutputs writes the introspection result read from input as
+// indented JSON, plus a compacted copy if requested.
+func writeJSONOutputs(input io.Reader, outputPath string, minify bool) error {
+	introspectionJSON, err := io.ReadAll(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading introspection JSON: %v\n", err)
+		return err
+	}
 
-		// Write minified schema file using the local function
-		err = writeSchemaFile(minifiedOutputPath, minifiedSchemaContent)
-		if err != nil {
-			os.Exit(1) // Exit if writing failed
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, introspectionJSON, "", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+		return err
+	}
+	if err := writeSchemaFile(outputPath, prettyJSON.String()); err != nil {
+		return err
+	}
+
+	if minify {
+		var compactJSON bytes.Buffer
+		// Use json.Compact instead of Marshal for minification
+		if err := json.Compact(&compactJSON, introspectionJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error compacting JSON: %v\n", err)
+			return err
 		}
+		return writeSchemaFile("schema.min.json", compactJSON.String())
 	}
+	return nil
 }
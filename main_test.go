@@ -1,17 +1,34 @@
 package main
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pzurek/geq/pkg/geq"
+	"github.com/pzurek/geq/pkg/geq/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// buildCLI builds the CLI binary into a temporary directory and returns its path.
+func buildCLI(t *testing.T) string {
+	t.Helper()
+	binaryPath := filepath.Join(t.TempDir(), "geq")
+	cmd := exec.Command("go", "build", "-o", binaryPath, ".")
+	require.NoError(t, cmd.Run(), "Failed to build CLI binary")
+	return binaryPath
+}
+
 // TestCLIBasicFunctionality tests the basic CLI functionality
 func TestCLIBasicFunctionality(t *testing.T) {
 	// This is a simple integration test that verifies the CLI runs successfully
@@ -85,3 +102,721 @@ func TestCLIOutputFileHandling(t *testing.T) {
 	// Additional tests would call the actual CLI with different output options
 	// but we'll keep those as integration tests that can be explicitly enabled
 }
+
+// TestCLIHeaderSecretsAreMasked verifies that header values taken from the
+// environment are sent to the server but never printed in verbose logs.
+func TestCLIHeaderSecretsAreMasked(t *testing.T) {
+	var gotAuth, gotTenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotTenant = r.Header.Get("X-Tenant-Id")
+		http.Error(w, `{"errors":[{"message":"boom"}]}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	binaryPath := buildCLI(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "tenant")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("acme-tenant\n"), 0600))
+
+	cmd := exec.Command(binaryPath,
+		"--endpoint", server.URL,
+		"--header", "Authorization: Bearer ${GEQ_TEST_TOKEN}",
+		"--header-from-file", "X-Tenant-ID="+tokenFile,
+		"--verbose",
+		"--output", filepath.Join(t.TempDir(), "schema.graphql"),
+	)
+	cmd.Env = append(os.Environ(), "GEQ_TEST_TOKEN=super-secret-value")
+	output, err := cmd.CombinedOutput()
+	assert.Error(t, err, "CLI should fail when the server returns an error")
+
+	assert.Equal(t, "Bearer super-secret-value", gotAuth)
+	assert.Equal(t, "acme-tenant", gotTenant)
+	assert.Contains(t, string(output), "Authorization: Bearer ****")
+	assert.NotContains(t, string(output), "super-secret-value")
+	assert.NotContains(t, string(output), "acme-tenant")
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("GEQ_TEST_TOKEN", "abc123")
+
+	r := &redactor{}
+	expanded, err := expandEnv("Authorization: Bearer ${GEQ_TEST_TOKEN} costs $$5", r)
+	require.NoError(t, err)
+	assert.Equal(t, "Authorization: Bearer abc123 costs $5", expanded)
+	assert.Equal(t, "token ****", r.String("token abc123"))
+
+	_, err = expandEnv("Authorization: Bearer ${GEQ_TEST_UNSET_VARIABLE}", r)
+	assert.Error(t, err, "Unset variables must not expand to an empty string")
+}
+
+func TestHeaderSourcesSecrets(t *testing.T) {
+	t.Setenv("GEQ_TEST_VERSION", "2024-01")
+	t.Setenv("GEQ_TEST_TOKEN", "abc123")
+	t.Setenv("GEQ_TEST_TENANT", "acme-tenant")
+	t.Setenv("GEQ_TEST_SHORT", "1")
+	sources := headerSources{
+		headers: headerFlags{"X-Api-Version: ${GEQ_TEST_VERSION}", "Authorization: Bearer ${GEQ_TEST_TOKEN}", "X-Retry: $GEQ_TEST_SHORT"},
+		fromEnv: headerFlags{"X-Tenant=GEQ_TEST_TENANT"},
+	}
+	headers, r, err := sources.load()
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc123", headers.Get("Authorization"))
+
+	// Only credentials and values read from files or variables are masked,
+	// and values too short to mask are left alone
+	assert.Equal(t, "version 2024-01, token ****, tenant ****, host 127.0.0.1:4000",
+		r.String("version 2024-01, token abc123, tenant acme-tenant, host 127.0.0.1:4000"))
+
+	// A header that does not parse is reported without the variables in it
+	sources = headerSources{headers: headerFlags{"Bearer ${GEQ_TEST_TOKEN}"}}
+	_, _, err = sources.load()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "abc123")
+}
+
+// TestCLIIntrospectionDisabledExitCode verifies the distinct exit code used
+// when the server answers 200 with an "introspection disabled" error.
+func TestCLIIntrospectionDisabledExitCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errors":[{"message":"GraphQL introspection is not allowed"}],"data":null}`))
+	}))
+	defer server.Close()
+
+	binaryPath := buildCLI(t)
+
+	outputPath := filepath.Join(t.TempDir(), "schema.graphql")
+	cmd := exec.Command(binaryPath, "--endpoint", server.URL, "--output", outputPath)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, "CLI should fail - got: %s", string(output))
+	assert.Equal(t, exitIntrospectionDisabled, exitErr.ExitCode())
+	assert.NoFileExists(t, outputPath, "No schema file should be written")
+}
+
+// TestCLIPrintQuery verifies that --print-query prints the configured query without a network call.
+func TestCLIPrintQuery(t *testing.T) {
+	binaryPath := buildCLI(t)
+
+	output, err := exec.Command(binaryPath, "--print-query").CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.True(t, strings.HasPrefix(string(output), "query IntrospectionQuery {"))
+	assert.Contains(t, string(output), "description")
+
+	output, err = exec.Command(binaryPath, "--print-query", "--descriptions=false", "--type-depth", "2").CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.NotContains(t, string(output), "description")
+	assert.Equal(t, 2, strings.Count(string(output), "ofType {"))
+
+	queryFile := filepath.Join(t.TempDir(), "query.graphql")
+	require.NoError(t, os.WriteFile(queryFile, []byte("query Custom { __schema { types { name } } }\n"), 0644))
+	output, err = exec.Command(binaryPath, "--print-query", "--query-file", queryFile).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Equal(t, "query Custom { __schema { types { name } } }\n", string(output))
+}
+
+// TestCLIConvert verifies that the convert command runs the output pipeline
+// on the sample introspection file without a network call.
+func TestCLIConvert(t *testing.T) {
+	binaryPath := buildCLI(t)
+	inputPath, err := filepath.Abs(filepath.Join("testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	expectedSDL, err := os.ReadFile(filepath.Join("testdata", "sample_schema.graphql"))
+	require.NoError(t, err)
+
+	// From a file, writing SDL plus the minified schema into the working directory
+	workDir := t.TempDir()
+	cmd := exec.Command(binaryPath, "convert", "--input", inputPath, "--minify")
+	cmd.Dir = workDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+
+	actualSDL, err := os.ReadFile(filepath.Join(workDir, "schema.graphql"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expectedSDL), string(actualSDL))
+	assert.FileExists(t, filepath.Join(workDir, "schema.min.graphql"))
+
+	// To stdout: only the schema is written
+	cmd = exec.Command(binaryPath, "convert", "--input", inputPath, "-o", "-")
+	cmd.Dir = workDir
+	stdout, err := cmd.Output()
+	require.NoError(t, err, "CLI execution failed")
+	assert.Equal(t, string(expectedSDL), string(stdout))
+
+	// A minified copy would be written next to the piped schema, so it is refused
+	cmd = exec.Command(binaryPath, "convert", "--input", inputPath, "-o", "-", "-m")
+	cmd.Dir = workDir
+	output, err = cmd.CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(output), "--minify cannot be used with --output -")
+
+	// Status lines go to stderr, keeping stdout for the schema
+	cmd = exec.Command(binaryPath, "convert", "--input", inputPath, "-o", filepath.Join(workDir, "quiet.graphql"))
+	stdout, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Empty(t, string(stdout))
+
+	// From stdin, writing JSON
+	input, err := os.Open(inputPath)
+	require.NoError(t, err)
+	defer input.Close()
+	outputPath := filepath.Join(workDir, "out.json")
+	cmd = exec.Command(binaryPath, "convert", "-i", "-", "-j", "-o", outputPath)
+	cmd.Stdin = input
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.FileExists(t, outputPath)
+
+	// From SDL to JSON and back: the SDL output matches the original types
+	sdlPath, err := filepath.Abs(filepath.Join("testdata", "sample_schema.graphql"))
+	require.NoError(t, err)
+	jsonPath := filepath.Join(workDir, "from-sdl.json")
+	cmd = exec.Command(binaryPath, "convert", "--input", sdlPath, "--from", "sdl", "--to", "json", "-o", jsonPath)
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var response geq.IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+	assert.Equal(t, "Query", response.Data.Schema.QueryType.Name)
+
+	roundTripPath := filepath.Join(workDir, "round-trip.graphql")
+	cmd = exec.Command(binaryPath, "convert", "-i", jsonPath, "-o", roundTripPath)
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	roundTrip, err := os.ReadFile(roundTripPath)
+	require.NoError(t, err)
+	typesSDL, _, _ := strings.Cut(string(expectedSDL), "directive @")
+	assert.True(t, strings.HasPrefix(string(roundTrip), typesSDL), "round trip changed the schema:\n%s", roundTrip)
+
+	// The input format defaults to SDL for .graphql files
+	cmd = exec.Command(binaryPath, "convert", "-i", sdlPath, "-j", "-o", filepath.Join(workDir, "detected.json"))
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+
+	// Invalid SDL reports the error location
+	cmd = exec.Command(binaryPath, "convert", "-i", "-", "--from", "sdl")
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader("type Query {\n  a Int\n}")
+	output, err = cmd.CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(output), "line 2, column 5")
+
+	// Invalid input
+	cmd = exec.Command(binaryPath, "convert", "-i", "-")
+	cmd.Dir = workDir
+	cmd.Stdin = strings.NewReader(`{"foo": 1}`)
+	_, err = cmd.CombinedOutput()
+	assert.Error(t, err, "CLI should fail on input without an introspection result")
+}
+
+func TestCLIPrintOptions(t *testing.T) {
+	binaryPath := buildCLI(t)
+	inputPath, err := filepath.Abs(filepath.Join("testdata", "features_introspection.json"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(inputPath)
+	require.NoError(t, err)
+	var response geq.IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected geq.PrintOptions
+	}{
+		{name: "Sort alphabetically", args: []string{"--sort=alpha"}, expected: geq.PrintOptions{Sort: geq.SortAlphabetical}},
+		{name: "Sort by kind", args: []string{"--sort=kind", "--no-builtins"}, expected: geq.PrintOptions{Sort: geq.SortByKind, OmitBuiltins: true}},
+		{name: "Sort by kind without =", args: []string{"--sort", "kind"}, expected: geq.PrintOptions{Sort: geq.SortByKind}},
+		{name: "Indent", args: []string{"--indent", "4"}, expected: geq.PrintOptions{Indent: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "schema.graphql")
+			args := append([]string{"convert", "-i", inputPath, "-o", outputPath}, tt.args...)
+			output, err := exec.Command(binaryPath, args...).CombinedOutput()
+			require.NoError(t, err, "CLI execution failed: %s", string(output))
+
+			actual, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			assert.Equal(t, geq.GenerateSDLWithOptions(response, tt.expected), string(actual))
+		})
+	}
+
+	output, err := exec.Command(binaryPath, "convert", "-i", inputPath, "--sort=size").CombinedOutput()
+	assert.Error(t, err)
+	assert.Contains(t, string(output), "invalid sort order 'size'")
+
+	// Commands without positional arguments do not ignore the flags after one
+	for _, args := range [][]string{
+		{"convert", "-i", inputPath, "stray", "-o", "-"},
+		{"-e", "http://localhost:4000/graphql", "stray"},
+		{"check", "-e", "http://localhost:4000/graphql", "stray", "--against", inputPath},
+		{"snapshot", "-e", "http://localhost:4000/graphql", "stray"},
+	} {
+		output, err = exec.Command(binaryPath, args...).CombinedOutput()
+		assert.Error(t, err)
+		assert.Contains(t, string(output), "unexpected argument 'stray'")
+	}
+}
+
+func TestCLIDiff(t *testing.T) {
+	binaryPath := buildCLI(t)
+	workDir := t.TempDir()
+	oldPath := filepath.Join(workDir, "old.graphql")
+	newPath := filepath.Join(workDir, "new.graphql")
+	require.NoError(t, os.WriteFile(oldPath, []byte("type Query { user(id: ID): User }\ntype User { name: String }\n"), 0644))
+	require.NoError(t, os.WriteFile(newPath, []byte("type Query { user(id: ID): User }\ntype User { name: String email: String }\n"), 0644))
+
+	// Additive changes succeed
+	output, err := exec.Command(binaryPath, "diff", oldPath, newPath).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "Field 'email' was added to object type 'User'")
+
+	// Breaking changes exit with their own code, here with flags after the schemas
+	output, err = exec.Command(binaryPath, "diff", newPath, oldPath, "--format", "json").Output()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitSchemaChanged, exitErr.ExitCode())
+	var changes []geq.Change
+	require.NoError(t, json.Unmarshal(output, &changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, geq.FieldRemoved, changes[0].Type)
+	assert.Equal(t, "User.email", changes[0].Path)
+
+	// An endpoint compared to the SDL printed from the same schema has no changes
+	introspectionJSON, err := os.ReadFile(filepath.Join("testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(introspectionJSON)
+	}))
+	defer server.Close()
+	output, err = exec.Command(binaryPath, "diff", "--probe=false", server.URL, filepath.Join("testdata", "sample_schema.graphql")).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "No changes detected")
+
+	// Exactly two schemas are required
+	_, err = exec.Command(binaryPath, "diff", oldPath).CombinedOutput()
+	assert.Error(t, err)
+}
+
+func TestCLICheck(t *testing.T) {
+	binaryPath := buildCLI(t)
+	introspectionJSON, err := os.ReadFile(filepath.Join("testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(introspectionJSON)
+	}))
+	defer server.Close()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "sample_schema.graphql"))
+	require.NoError(t, err)
+	sdl := string(data)
+	nameField := "  \"\"\"The name of the user\"\"\"\n  name: String\n"
+	require.Contains(t, sdl, nameField)
+	workDir := t.TempDir()
+
+	// The same schema, with its definitions in a different order, passes
+	definitions := strings.Split(strings.TrimSpace(sdl), "\n\n")
+	slices.Reverse(definitions)
+	reordered := filepath.Join(workDir, "reordered.graphql")
+	require.NoError(t, os.WriteFile(reordered, []byte(strings.Join(definitions, "\n\n")), 0644))
+	output, err := exec.Command(binaryPath, "check", "--probe=false", "-e", server.URL, "--against", reordered).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "matches")
+
+	// A committed schema missing a field: the live schema added it
+	older := filepath.Join(workDir, "older.graphql")
+	require.NoError(t, os.WriteFile(older, []byte(strings.Replace(sdl, nameField, "", 1)), 0644))
+	output, err = exec.Command(binaryPath, "check", "--probe=false", "-e", server.URL, "--against", older).CombinedOutput()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, "check should fail on drift: %s", string(output))
+	assert.Equal(t, exitSchemaChanged, exitErr.ExitCode())
+	assert.Contains(t, string(output), "--- "+older)
+	assert.Contains(t, string(output), "+  name: String")
+
+	// An additive change is allowed with --fail-on breaking
+	output, err = exec.Command(binaryPath, "check", "--probe=false", "-e", server.URL, "--against", older, "--fail-on", "breaking").CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "Field 'name' was added to object type 'User'")
+
+	// A committed schema with an extra field: the live schema removed it
+	newer := filepath.Join(workDir, "newer.graphql")
+	require.NoError(t, os.WriteFile(newer, []byte(strings.Replace(sdl, nameField, nameField+"  email: String\n", 1)), 0644))
+	output, err = exec.Command(binaryPath, "check", "--probe=false", "-e", server.URL, "--against", newer, "--fail-on", "breaking").CombinedOutput()
+	require.ErrorAs(t, err, &exitErr, "check should fail on a breaking change: %s", string(output))
+	assert.Equal(t, exitSchemaChanged, exitErr.ExitCode())
+
+	// An endpoint without a scheme is rejected rather than read as a file
+	output, err = exec.Command(binaryPath, "check", "-e", strings.TrimPrefix(server.URL, "http://"), "--against", newer).CombinedOutput()
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitError, exitErr.ExitCode())
+	assert.Contains(t, string(output), "is not an http:// or https:// URL; did you mean 'http://")
+}
+
+func TestCLIChangelog(t *testing.T) {
+	binaryPath := buildCLI(t)
+	workDir := t.TempDir()
+	oldPath := filepath.Join(workDir, "old.graphql")
+	newPath := filepath.Join(workDir, "new.graphql")
+	require.NoError(t, os.WriteFile(oldPath, []byte("type Query { user: User }\ntype User { name: String }\n"), 0644))
+	require.NoError(t, os.WriteFile(newPath, []byte("type Query { user: User }\ntype User {\n  name: String @deprecated(reason: \"Use fullName\")\n  \"The full name\"\n  fullName: String\n}\n"), 0644))
+
+	output, err := exec.Command(binaryPath, "changelog", oldPath, newPath, "--link-template", "https://docs.example.com/{type}").CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "## Deprecated\n\n- [`User.name`](https://docs.example.com/User): Field 'User.name' is deprecated\n  > *Deprecation reason:* Use fullName\n")
+	assert.Contains(t, string(output), "## Added\n\n- [`User.fullName`](https://docs.example.com/User): Field 'fullName' was added to object type 'User'\n  > The full name\n")
+
+	// Breaking changes are listed, not failed on
+	changelogPath := filepath.Join(workDir, "changelog.json")
+	output, err = exec.Command(binaryPath, "changelog", "--format", "json", "-o", changelogPath, newPath, oldPath).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "Changelog successfully saved to "+changelogPath)
+	data, err := os.ReadFile(changelogPath)
+	require.NoError(t, err)
+	var changelog geq.Changelog
+	require.NoError(t, json.Unmarshal(data, &changelog))
+	require.Len(t, changelog.Breaking, 1)
+	assert.Equal(t, "User.fullName", changelog.Breaking[0].Path)
+	assert.Equal(t, "The full name", changelog.Breaking[0].Description)
+
+	_, err = exec.Command(binaryPath, "changelog", "--format", "yaml", oldPath, newPath).CombinedOutput()
+	assert.Error(t, err)
+}
+
+func TestCLIWatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook runs through sh and the watcher is stopped with an interrupt")
+	}
+	binaryPath := buildCLI(t)
+	before, err := sdlToIntrospectionJSON([]byte("type Query { user: User }\ntype User { name: String email: String }\n"))
+	require.NoError(t, err)
+	after, err := sdlToIntrospectionJSON([]byte("type Query { user: User }\ntype User { name: String }\n"))
+	require.NoError(t, err)
+
+	// The schema loses a field from the third request on
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if requests.Add(1) < 3 {
+			_, _ = w.Write(before)
+		} else {
+			_, _ = w.Write(after)
+		}
+	}))
+	defer server.Close()
+
+	workDir := t.TempDir()
+	schemaPath := filepath.Join(workDir, "schema.graphql")
+	hookPath := filepath.Join(workDir, "hooks.txt")
+	cmd := exec.Command(binaryPath, "watch", "--probe=false", "-e", server.URL+"/graphql?token=s3cr3t-token", "--interval", "50ms", "-o", schemaPath,
+		"--exec", `echo "$GEQ_SCHEMA_HASH $GEQ_BREAKING" >> `+hookPath)
+	var output strings.Builder
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	require.NoError(t, cmd.Start())
+
+	// The hook runs once for the first schema and once for the change
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if data, err := os.ReadFile(hookPath); err == nil && strings.Count(string(data), "\n") >= 2 {
+			break
+		}
+	}
+	require.NoError(t, cmd.Process.Signal(os.Interrupt))
+	require.NoError(t, cmd.Wait(), "watch should stop cleanly: %s", output.String())
+
+	data, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "the hook should run once per change: %s", output.String())
+	assert.Regexp(t, `^[0-9a-f]{64} false$`, lines[0])
+	assert.Regexp(t, `^[0-9a-f]{64} true$`, lines[1])
+
+	assert.Contains(t, output.String(), "Watching "+server.URL+"/graphql every 50ms")
+	assert.NotContains(t, output.String(), "s3cr3t-token")
+	assert.Contains(t, output.String(), "Fetched schema "+lines[0][:12])
+	assert.Contains(t, output.String(), "Schema changed from "+lines[0][:12]+" to "+lines[1][:12])
+	assert.Contains(t, output.String(), "Field 'email' was removed from object type 'User'")
+	assert.Contains(t, output.String(), "Stopped watching")
+	schema, err := os.ReadFile(schemaPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(schema), "email")
+}
+
+func TestCLISnapshotHistory(t *testing.T) {
+	binaryPath := buildCLI(t)
+	before, err := sdlToIntrospectionJSON([]byte("type Query { user: User }\ntype User { name: String email: String }\n"))
+	require.NoError(t, err)
+	after, err := sdlToIntrospectionJSON([]byte("type Query { user: User }\ntype User { name: String }\n"))
+	require.NoError(t, err)
+	var current atomic.Pointer[[]byte]
+	current.Store(&before)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(*current.Load())
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "history")
+	snapshot := func(extra ...string) string {
+		t.Helper()
+		args := append([]string{"snapshot", "--probe=false", "-e", server.URL, "--dir", dir}, extra...)
+		output, err := exec.Command(binaryPath, args...).CombinedOutput()
+		require.NoError(t, err, "CLI execution failed: %s", string(output))
+		return string(output)
+	}
+
+	t.Setenv("GEQ_TEST_TOKEN", "s3cr3t-token")
+	saved := snapshot("-H", "Authorization: Bearer ${GEQ_TEST_TOKEN}", "-H", "X-Tenant: acme")
+	assert.Contains(t, saved, "Saved snapshot ")
+	// Snapshot IDs have a resolution of one second
+	time.Sleep(time.Second)
+	assert.Contains(t, snapshot("--if-changed"), "is unchanged since snapshot")
+	current.Store(&after)
+	snapshot()
+
+	output, err := exec.Command(binaryPath, "history", "list", "--dir", dir).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	require.Len(t, lines, 3, "two snapshots expected: %s", string(output))
+	assert.Equal(t, server.URL, lines[0])
+
+	// The metadata records the headers with the secret masked
+	output, err = exec.Command(binaryPath, "history", "show", "latest~1", "--dir", dir, "--metadata").CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.NotContains(t, string(output), "s3cr3t-token")
+	var metadata struct {
+		Endpoint      string              `json:"endpoint"`
+		Headers       map[string][]string `json:"headers"`
+		GeqVersion    string              `json:"geqVersion"`
+		FetchDuration string              `json:"fetchDuration"`
+		SHA256        string              `json:"sha256"`
+	}
+	require.NoError(t, json.Unmarshal(output, &metadata))
+	assert.Equal(t, server.URL, metadata.Endpoint)
+	assert.Equal(t, []string{"Bearer ****"}, metadata.Headers["Authorization"])
+	assert.Equal(t, []string{"acme"}, metadata.Headers["X-Tenant"])
+	assert.Equal(t, "dev", metadata.GeqVersion)
+	assert.NotEmpty(t, metadata.FetchDuration)
+	assert.Len(t, metadata.SHA256, 64)
+
+	output, err = exec.Command(binaryPath, "history", "show", "latest", "--dir", dir).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "type User {\n  name: String\n}\n")
+	assert.NotContains(t, string(output), "email")
+
+	output, err = exec.Command(binaryPath, "history", "diff", "latest~1", "latest", "--dir", dir, "-e", server.URL).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "Field 'email' was removed from object type 'User'")
+
+	_, err = exec.Command(binaryPath, "history", "show", "1999", "--dir", dir).CombinedOutput()
+	assert.Error(t, err)
+
+	// Credentials in the endpoint URL are neither printed nor stored
+	redactedDir := filepath.Join(t.TempDir(), "redacted")
+	endpoint := strings.Replace(server.URL, "http://", "http://user:pa55@", 1) + "?token=s3cr3t-query"
+	output, err = exec.Command(binaryPath, "snapshot", "--probe=false", "-e", endpoint, "--dir", redactedDir).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.NotContains(t, string(output), "s3cr3t-query")
+	assert.NotContains(t, string(output), "pa55")
+	metadataFiles, err := filepath.Glob(filepath.Join(redactedDir, "*", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, metadataFiles, 1)
+	data, err := os.ReadFile(metadataFiles[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t-query")
+	assert.NotContains(t, string(data), "pa55")
+
+	// Metadata without a hash is listed rather than crashing
+	require.NoError(t, os.WriteFile(metadataFiles[0], []byte(`{"endpoint": "`+server.URL+`"}`), 0644))
+	output, err = exec.Command(binaryPath, "history", "list", "--dir", redactedDir).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "Z  -  ")
+}
+
+func TestCLIDeprecations(t *testing.T) {
+	binaryPath := buildCLI(t)
+	workDir := t.TempDir()
+	schemaPath := filepath.Join(workDir, "schema.graphql")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`type Query { me: User @deprecated }
+type User {
+  name: String @deprecated(reason: "Use fullName")
+  fullName: String
+  posts(first: Int @deprecated(reason: "Use limit"), limit: Int): [String]
+}
+`), 0644))
+
+	output, err := exec.Command(binaryPath, "deprecations", schemaPath).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Regexp(t, `(?m)^User\.name\s+FIELD\s+User\s+Use fullName$`, string(output))
+	assert.Contains(t, string(output), "3 deprecations")
+
+	output, err = exec.Command(binaryPath, "deprecations", "--format", "csv", schemaPath).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Equal(t, "coordinate,kind,parentType,reason\n"+
+		"Query.me,FIELD,Query,No longer supported\n"+
+		"User.name,FIELD,User,Use fullName\n"+
+		"User.posts(first:),ARGUMENT,User,Use limit\n", string(output))
+
+	// With --since, each deprecation is dated by the snapshot it first appears in
+	endpoint := "https://api.example.com/graphql"
+	history := store.New(filepath.Join(workDir, "history"))
+	for i, sdl := range []string{
+		"type Query { me: User }\ntype User { name: String @deprecated }\n",
+		"type Query { me: User @deprecated }\ntype User { name: String @deprecated }\n",
+	} {
+		_, err := history.Save([]byte(sdl), store.Metadata{Endpoint: endpoint, FetchedAt: time.Date(2024, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)})
+		require.NoError(t, err)
+	}
+	output, err = exec.Command(binaryPath, "deprecations", schemaPath, "--format", "json", "--since", "2024-01", "--dir", history.Dir(), "-e", endpoint).Output()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	var rows []struct {
+		Coordinate string `json:"coordinate"`
+		FirstSeen  string `json:"firstSeen"`
+	}
+	require.NoError(t, json.Unmarshal(output, &rows))
+	require.Len(t, rows, 3)
+	assert.Equal(t, "Query.me", rows[0].Coordinate)
+	assert.Equal(t, "2024-02-01T00-00-00Z", rows[0].FirstSeen)
+	assert.Equal(t, "2024-01-01T00-00-00Z", rows[1].FirstSeen)
+	assert.Empty(t, rows[2].FirstSeen)
+
+	_, err = exec.Command(binaryPath, "deprecations", "--format", "xml", schemaPath).CombinedOutput()
+	assert.Error(t, err)
+}
+
+func TestCLILint(t *testing.T) {
+	binaryPath := buildCLI(t)
+	workDir := t.TempDir()
+	schemaPath := filepath.Join(workDir, "schema.graphql")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`"""Root"""
+type Query {
+  """Users"""
+  user_list(ids: [ID!]): String
+}
+`), 0644))
+
+	output, err := exec.Command(binaryPath, "lint", schemaPath).CombinedOutput()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, "lint should fail on errors: %s", string(output))
+	assert.Equal(t, exitLintErrors, exitErr.ExitCode())
+	assert.Contains(t, string(output), schemaPath+":3:3: error: Query.user_list: Field name 'user_list' is not camelCase (field-names-camel-case)\n")
+	assert.Contains(t, string(output), "1 problem (1 error, 0 warnings)")
+
+	// A configuration that turns the error into a warning passes, here read
+	// from the default configuration file in the working directory
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, defaultLintConfigFile), []byte(`{"rules": {"field-names-camel-case": "warning"}}`), 0644))
+	cmd := exec.Command(binaryPath, "lint", "--format", "json", schemaPath)
+	cmd.Dir = workDir
+	output, err = cmd.Output()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	var results []lintResult
+	require.NoError(t, json.Unmarshal(output, &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, geq.SeverityWarning, results[0].Severity)
+	assert.Equal(t, schemaPath, results[0].Schema)
+	assert.Equal(t, geq.Location{Line: 3, Column: 3}, results[0].Location)
+
+	// Configuration mistakes are reported
+	badConfig := filepath.Join(workDir, "bad.json")
+	require.NoError(t, os.WriteFile(badConfig, []byte(`{"rules": {"no-such-rule": "error"}}`), 0644))
+	output, err = exec.Command(binaryPath, "lint", "--config", badConfig, schemaPath).CombinedOutput()
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, exitError, exitErr.ExitCode())
+	assert.Contains(t, string(output), "unknown lint rule 'no-such-rule'")
+}
+
+func TestCLIValidate(t *testing.T) {
+	binaryPath := buildCLI(t)
+	output, err := exec.Command(binaryPath, "validate", filepath.Join("testdata", "sample_schema.graphql"), filepath.Join("testdata", "sample_introspection.json")).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Contains(t, string(output), "sample_introspection.json is valid")
+
+	invalidPath := filepath.Join(t.TempDir(), "invalid.graphql")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("type Query { user: User }\nunion Result = String\n"), 0644))
+	output, err = exec.Command(binaryPath, "validate", invalidPath).CombinedOutput()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, "validate should fail: %s", string(output))
+	assert.Equal(t, exitInvalidSchema, exitErr.ExitCode())
+	assert.Equal(t, invalidPath+":1:14: Field 'Query.user' has unknown type 'User'\n"+
+		invalidPath+":2:1: Union 'Result' can only have object types as members, but 'String' is a scalar type\n", string(output))
+}
+
+func TestExpandPatterns(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"a.graphql", "src/b.graphql", "src/deep/er/c.graphql", "src/deep/d.txt"} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	in := func(files ...string) []string {
+		for i, file := range files {
+			files[i] = filepath.Join(dir, file)
+		}
+		return files
+	}
+
+	files, err := expandPatterns([]string{filepath.Join(dir, "src/**/*.graphql")})
+	require.NoError(t, err)
+	assert.Equal(t, in("src/b.graphql", "src/deep/er/c.graphql"), files)
+
+	files, err = expandPatterns([]string{filepath.Join(dir, "**/c.graphql"), filepath.Join(dir, "*.graphql"), filepath.Join(dir, "a.graphql")})
+	require.NoError(t, err)
+	assert.Equal(t, in("src/deep/er/c.graphql", "a.graphql"), files)
+
+	files, err = expandPatterns([]string{dir + "/a.graphql", dir + "/./a.graphql", dir + "/src/../a.graphql"})
+	require.NoError(t, err)
+	assert.Equal(t, in("a.graphql"), files)
+
+	_, err = expandPatterns([]string{filepath.Join(dir, "**/*.gql")})
+	assert.EqualError(t, err, "no files match '"+filepath.Join(dir, "**/*.gql")+"'")
+	_, err = expandPatterns([]string{filepath.Join(dir, "[")})
+	assert.ErrorContains(t, err, "invalid pattern")
+}
+
+func TestCLIValidateOps(t *testing.T) {
+	binaryPath := buildCLI(t)
+	schemaPath := filepath.Join("testdata", "sample_schema.graphql")
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("src/user.graphql", "query GetUser($id: ID!) {\n  user(id: $id) { ...UserFields }\n}\n")
+	write("src/fragments/user.graphql", "fragment UserFields on User { id name }\n")
+
+	pattern := filepath.Join(dir, "src", "**", "*.graphql")
+	output, err := exec.Command(binaryPath, "validate-ops", "--schema", schemaPath, pattern).CombinedOutput()
+	require.NoError(t, err, "CLI execution failed: %s", string(output))
+	assert.Equal(t, "No problems found in 2 documents\n", string(output))
+
+	write("src/create.graphql", "mutation Create($name: String) {\n  createUser(input: {name: $name, role: OWNER}) { id email }\n}\n")
+	write("src/broken.graphql", "query {\n  user(id: 1) {\n")
+	output, err = exec.Command(binaryPath, "validate-ops", pattern, "-s", schemaPath).CombinedOutput()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr, "validate-ops should fail: %s", string(output))
+	assert.Equal(t, exitInvalidOperations, exitErr.ExitCode())
+	broken := filepath.Join(dir, "src", "broken.graphql")
+	create := filepath.Join(dir, "src", "create.graphql")
+	assert.Equal(t, broken+":3:1: error: expected name, found end of file\n"+
+		create+":2:28: error: Variable '$name' of type 'String' is used in a position expecting type 'String!'\n"+
+		create+":2:41: error: Value 'OWNER' does not exist in enum 'UserRole'\n"+
+		create+":2:54: error: Cannot query field 'email' on type 'User'\n"+
+		"\n4 problems (4 errors, 0 warnings) in 4 documents\n", string(output))
+
+	output, err = exec.Command(binaryPath, "validate-ops", "--schema", schemaPath, "--format", "json", create).CombinedOutput()
+	require.ErrorAs(t, err, &exitErr, "validate-ops should fail: %s", string(output))
+	var problems []geq.OperationProblem
+	require.NoError(t, json.Unmarshal(output, &problems))
+	require.Len(t, problems, 3)
+	assert.Equal(t, geq.OperationProblem{
+		Severity: geq.SeverityError,
+		Message:  "Cannot query field 'email' on type 'User'",
+		Source:   create,
+		Location: geq.Location{Line: 2, Column: 54},
+	}, problems[2])
+}
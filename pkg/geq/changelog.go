@@ -0,0 +1,273 @@
+package geq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Changelog describes the changes between two schemas for release notes,
+// grouped into sections.
+type Changelog struct {
+	// Breaking lists the breaking changes.
+	Breaking []ChangelogEntry `json:"breaking"`
+	// Deprecated lists the elements that were deprecated, or whose deprecation
+	// reason changed.
+	Deprecated []ChangelogEntry `json:"deprecated"`
+	// Added lists new types, fields, arguments, enum values and so on that are
+	// not breaking.
+	Added []ChangelogEntry `json:"added"`
+	// Changed lists every other change.
+	Changed []ChangelogEntry `json:"changed"`
+}
+
+// ChangelogEntry is a Change together with the documentation of the element
+// it concerns.
+type ChangelogEntry struct {
+	Change
+	// TypeName is the type or directive ("@name") the change belongs to.
+	TypeName string `json:"typeName,omitempty"`
+	// Description is the description of the changed element, from the new
+	// schema, or from the old one if the element was removed.
+	Description string `json:"description,omitempty"`
+	// DeprecationReason is the deprecation reason of the changed element, if
+	// it is deprecated.
+	DeprecationReason string `json:"deprecationReason,omitempty"`
+}
+
+// addedChangeTypes are the changes listed as additions when they are not
+// breaking.
+var addedChangeTypes = map[ChangeType]bool{
+	TypeAdded:                true,
+	ObjectTypeInterfaceAdded: true,
+	FieldAdded:               true,
+	FieldArgumentAdded:       true,
+	InputFieldAdded:          true,
+	EnumValueAdded:           true,
+	UnionMemberAdded:         true,
+	DirectiveAdded:           true,
+	DirectiveLocationAdded:   true,
+	DirectiveArgumentAdded:   true,
+}
+
+// deprecatedChangeTypes are the changes listed as deprecations.
+var deprecatedChangeTypes = map[ChangeType]bool{
+	FieldDeprecationAdded:                 true,
+	FieldDeprecationReasonChanged:         true,
+	FieldArgumentDeprecationAdded:         true,
+	FieldArgumentDeprecationReasonChanged: true,
+	InputFieldDeprecationAdded:            true,
+	InputFieldDeprecationReasonChanged:    true,
+	EnumValueDeprecationAdded:             true,
+	EnumValueDeprecationReasonChanged:     true,
+}
+
+// ownerPathChangeTypes are the changes whose Path names the type or directive
+// that changed rather than the element that was added or removed, such as a
+// union member. Their entries carry no description.
+var ownerPathChangeTypes = map[ChangeType]bool{
+	ObjectTypeInterfaceAdded:   true,
+	ObjectTypeInterfaceRemoved: true,
+	UnionMemberAdded:           true,
+	UnionMemberRemoved:         true,
+	DirectiveLocationAdded:     true,
+	DirectiveLocationRemoved:   true,
+	DirectiveRepeatableAdded:   true,
+	DirectiveRepeatableRemoved: true,
+}
+
+// NewChangelog compares two schemas with Diff and groups the changes into a
+// changelog. Entries keep the order in which Diff reports them.
+func NewChangelog(oldSchema, newSchema *Schema) *Changelog {
+	changelog := &Changelog{
+		Breaking:   []ChangelogEntry{},
+		Deprecated: []ChangelogEntry{},
+		Added:      []ChangelogEntry{},
+		Changed:    []ChangelogEntry{},
+	}
+	for _, change := range Diff(oldSchema, newSchema) {
+		entry := ChangelogEntry{Change: change, TypeName: pathOwner(change.Path)}
+		if change.Path != "" {
+			description, reason, ok := describeCoordinate(newSchema, change.Path)
+			if !ok {
+				description, reason, _ = describeCoordinate(oldSchema, change.Path)
+			}
+			if !ownerPathChangeTypes[change.Type] {
+				entry.Description = description
+			}
+			entry.DeprecationReason = reason
+		}
+
+		switch {
+		case change.Criticality == CriticalityBreaking:
+			changelog.Breaking = append(changelog.Breaking, entry)
+		case deprecatedChangeTypes[change.Type]:
+			changelog.Deprecated = append(changelog.Deprecated, entry)
+		case addedChangeTypes[change.Type]:
+			changelog.Added = append(changelog.Added, entry)
+		default:
+			changelog.Changed = append(changelog.Changed, entry)
+		}
+	}
+	return changelog
+}
+
+// IsEmpty reports whether the changelog has no entries.
+func (c *Changelog) IsEmpty() bool {
+	return len(c.Breaking)+len(c.Deprecated)+len(c.Added)+len(c.Changed) == 0
+}
+
+// WriteMarkdown writes the changelog as Markdown, one section per group with
+// entries. Each entry starts with the schema coordinate of the changed
+// element. If linkTemplate is set, the coordinate links to it, with "{type}"
+// replaced by the type or directive name and "{coordinate}" by the
+// coordinate, e.g. "https://docs.example.com/schema/{type}".
+func (c *Changelog) WriteMarkdown(w io.Writer, linkTemplate string) error {
+	bw := bufio.NewWriter(w)
+	if c.IsEmpty() {
+		bw.WriteString("No schema changes.\n")
+		return bw.Flush()
+	}
+
+	sections := []struct {
+		title   string
+		entries []ChangelogEntry
+	}{
+		{"Breaking", c.Breaking},
+		{"Deprecated", c.Deprecated},
+		{"Added", c.Added},
+		{"Changed", c.Changed},
+	}
+	first := true
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+		fmt.Fprintf(bw, "## %s\n\n", section.title)
+		for _, entry := range section.entries {
+			writeMarkdownEntry(bw, entry, linkTemplate)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownEntry writes a changelog entry as a Markdown list item.
+func writeMarkdownEntry(bw *bufio.Writer, entry ChangelogEntry, linkTemplate string) {
+	bw.WriteString("- ")
+	if entry.Path != "" {
+		coordinate := "`" + entry.Path + "`"
+		if linkTemplate != "" {
+			link := strings.NewReplacer("{type}", strings.TrimPrefix(entry.TypeName, "@"), "{coordinate}", entry.Path).Replace(linkTemplate)
+			coordinate = "[" + coordinate + "](" + link + ")"
+		}
+		bw.WriteString(coordinate + ": ")
+	}
+	bw.WriteString(entry.Message + "\n")
+
+	// The deprecation reason and description are quoted below the entry
+	var quoted []string
+	if entry.DeprecationReason != "" {
+		quoted = append(quoted, "*Deprecation reason:* "+entry.DeprecationReason)
+	}
+	if entry.Description != "" {
+		if len(quoted) > 0 {
+			quoted = append(quoted, "")
+		}
+		quoted = append(quoted, strings.Split(entry.Description, "\n")...)
+	}
+	for _, line := range quoted {
+		bw.WriteString(strings.TrimRight("  > "+line, " ") + "\n")
+	}
+}
+
+// describeCoordinate returns the description and the deprecation reason of
+// the element a schema coordinate names ("Type", "Type.field",
+// "Type.field(arg:)", "@directive" or "@directive(arg:)"). The reason is
+// empty unless the element is deprecated. ok is false if the schema has no
+// such element.
+func describeCoordinate(schema *Schema, path string) (description, deprecationReason string, ok bool) {
+	owner, member, argument := splitCoordinate(path)
+
+	if directiveName, isDirective := strings.CutPrefix(owner, "@"); isDirective {
+		directive := schema.DirectiveByName(directiveName)
+		if directive == nil {
+			return "", "", false
+		}
+		if argument == "" {
+			return directive.Description, "", true
+		}
+		return describeInputValue(findInputValue(directive.Args, argument))
+	}
+
+	def := schema.TypeByName(owner)
+	switch {
+	case def == nil:
+		return "", "", false
+	case member == "":
+		return def.Description, "", true
+	}
+	if field := def.Field(member); field != nil {
+		if argument != "" {
+			return describeInputValue(findInputValue(field.Args, argument))
+		}
+		return field.Description, deprecationReasonOf(field.IsDeprecated, field.DeprecationReason), true
+	}
+	if argument != "" {
+		return "", "", false
+	}
+	if inputField := def.InputField(member); inputField != nil {
+		return describeInputValue(inputField)
+	}
+	if value := def.EnumValue(member); value != nil {
+		return value.Description, deprecationReasonOf(value.IsDeprecated, value.DeprecationReason), true
+	}
+	return "", "", false
+}
+
+// splitCoordinate splits a schema coordinate into the type or directive, the
+// field, input field or enum value, and the argument it names.
+func splitCoordinate(path string) (owner, member, argument string) {
+	rest := path
+	if i := strings.Index(rest, "("); i >= 0 {
+		argument = strings.TrimSuffix(strings.TrimSuffix(rest[i+1:], ")"), ":")
+		rest = rest[:i]
+	}
+	owner, member, _ = strings.Cut(rest, ".")
+	return owner, member, argument
+}
+
+// findInputValue returns the named argument or input field, or nil.
+func findInputValue(values []*InputValueDefinition, name string) *InputValueDefinition {
+	for _, value := range values {
+		if value.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+// describeInputValue returns the description and deprecation reason of an
+// argument or input field, as describeCoordinate does.
+func describeInputValue(value *InputValueDefinition) (string, string, bool) {
+	if value == nil {
+		return "", "", false
+	}
+	return value.Description, deprecationReasonOf(value.IsDeprecated, value.DeprecationReason), true
+}
+
+// deprecationReasonOf returns the deprecation reason of an element, or "" if
+// it is not deprecated.
+func deprecationReasonOf(isDeprecated bool, reason string) string {
+	if !isDeprecated {
+		return ""
+	}
+	if reason == "" {
+		return DefaultDeprecationReason
+	}
+	return reason
+}
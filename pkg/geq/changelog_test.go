@@ -0,0 +1,143 @@
+package geq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const changelogOldSDL = `
+type Query { user(id: ID): User }
+
+"""A user of the service"""
+type User {
+  """The name"""
+  name: String
+  email: String
+}
+`
+
+const changelogNewSDL = `
+type Query { user(id: ID!): User }
+
+"""A user of the service"""
+type User {
+  email: String @deprecated(reason: "Use ` + "`contact`" + `")
+  """How to reach the user"""
+  contact: String
+}
+
+"""Access level"""
+enum Role { ADMIN }
+`
+
+func TestNewChangelog(t *testing.T) {
+	changelog := NewChangelog(mustParseSDL(t, changelogOldSDL), mustParseSDL(t, changelogNewSDL))
+
+	paths := func(entries []ChangelogEntry) []string {
+		var result []string
+		for _, entry := range entries {
+			result = append(result, entry.Path)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"Query.user(id:)", "User.name"}, paths(changelog.Breaking))
+	assert.Equal(t, []string{"User.email"}, paths(changelog.Deprecated))
+	assert.Equal(t, []string{"Role", "User.contact"}, paths(changelog.Added))
+	assert.Empty(t, changelog.Changed)
+	assert.False(t, changelog.IsEmpty())
+
+	// Removed elements are described from the old schema
+	removed := changelog.Breaking[1]
+	assert.Equal(t, "User", removed.TypeName)
+	assert.Equal(t, "The name", removed.Description)
+
+	deprecated := changelog.Deprecated[0]
+	assert.Equal(t, "Use `contact`", deprecated.DeprecationReason)
+	assert.Empty(t, deprecated.Description)
+
+	added := changelog.Added[0]
+	assert.Equal(t, "Role", added.TypeName)
+	assert.Equal(t, "Access level", added.Description)
+}
+
+func TestNewChangelogNoChanges(t *testing.T) {
+	schema := mustParseSDL(t, changelogOldSDL)
+	changelog := NewChangelog(schema, schema)
+	assert.True(t, changelog.IsEmpty())
+
+	var sb strings.Builder
+	require.NoError(t, changelog.WriteMarkdown(&sb, ""))
+	assert.Equal(t, "No schema changes.\n", sb.String())
+}
+
+func TestChangelogWriteMarkdown(t *testing.T) {
+	changelog := NewChangelog(mustParseSDL(t, changelogOldSDL), mustParseSDL(t, changelogNewSDL))
+
+	var sb strings.Builder
+	require.NoError(t, changelog.WriteMarkdown(&sb, "https://docs.example.com/{type}#{coordinate}"))
+	expected := "## Breaking\n" +
+		"\n" +
+		"- [`Query.user(id:)`](https://docs.example.com/Query#Query.user(id:)): Type for argument 'id' on field 'Query.user' changed from 'ID' to 'ID!'\n" +
+		"- [`User.name`](https://docs.example.com/User#User.name): Field 'name' was removed from object type 'User'\n" +
+		"  > The name\n" +
+		"\n" +
+		"## Deprecated\n" +
+		"\n" +
+		"- [`User.email`](https://docs.example.com/User#User.email): Field 'User.email' is deprecated\n" +
+		"  > *Deprecation reason:* Use `contact`\n" +
+		"\n" +
+		"## Added\n" +
+		"\n" +
+		"- [`Role`](https://docs.example.com/Role#Role): Type 'Role' was added\n" +
+		"  > Access level\n" +
+		"- [`User.contact`](https://docs.example.com/User#User.contact): Field 'contact' was added to object type 'User'\n" +
+		"  > How to reach the user\n"
+	assert.Equal(t, expected, sb.String())
+
+	// Without a template the coordinates are plain code spans
+	sb.Reset()
+	require.NoError(t, changelog.WriteMarkdown(&sb, ""))
+	assert.Contains(t, sb.String(), "- `User.name`: Field 'name' was removed from object type 'User'\n")
+}
+
+func TestDescribeCoordinate(t *testing.T) {
+	schema := mustParseSDL(t, `
+type Query {
+  "Find users"
+  users("Only these" ids: [ID] @deprecated): [String]
+}
+input Filter { "By name" name: String }
+enum Role { ADMIN @deprecated(reason: "Gone") }
+"Auth check"
+directive @auth("Needed role" role: Role) on FIELD_DEFINITION
+`)
+	tests := []struct {
+		path        string
+		description string
+		reason      string
+		ok          bool
+	}{
+		{path: "Query", ok: true},
+		{path: "Query.users", description: "Find users", ok: true},
+		{path: "Query.users(ids:)", description: "Only these", reason: DefaultDeprecationReason, ok: true},
+		{path: "Filter.name", description: "By name", ok: true},
+		{path: "Role.ADMIN", reason: "Gone", ok: true},
+		{path: "@auth", description: "Auth check", ok: true},
+		{path: "@auth(role:)", description: "Needed role", ok: true},
+		{path: "Query.missing"},
+		{path: "Filter.name(x:)"},
+		{path: "@missing"},
+		{path: "Missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			description, reason, ok := describeCoordinate(schema, tt.path)
+			assert.Equal(t, tt.description, description)
+			assert.Equal(t, tt.reason, reason)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package geq
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent header sent when none is configured.
+const DefaultUserAgent = "geq"
+
+// Client fetches GraphQL schemas from a single endpoint. A Client is safe for
+// concurrent use and is intended to be reused across calls.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	header     http.Header
+	logger     Logger
+	retry      RetryPolicy
+	probe      bool
+	queryOpts  QueryOptions
+	query      string
+}
+
+// Logger receives verbose progress messages from a Client. *log.Logger
+// satisfies this interface. Header values that look like credentials are
+// redacted before they are logged.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send requests. Use it to inject
+// a custom transport, proxy or TLS configuration.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithTimeout bounds each request attempt, including reading the response
+// body. A zero duration means no timeout beyond the one carried by the context,
+// which bounds the whole call including retries.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(name, value string) Option {
+	return func(c *Client) {
+		c.header.Add(name, value)
+	}
+}
+
+// WithHeaders adds every header in h to the headers sent with each request.
+func WithHeaders(h http.Header) Option {
+	return func(c *Client) {
+		for name, values := range h {
+			for _, value := range values {
+				c.header.Add(name, value)
+			}
+		}
+	}
+}
+
+// WithLogger enables verbose logging of requests and responses.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithCapabilityProbe makes the client probe the server for newer
+// introspection fields (such as isRepeatable, specifiedByURL, isOneOf and
+// deprecated arguments) before fetching, so that the richest query the server
+// accepts is used. The probe costs one extra request.
+func WithCapabilityProbe(enabled bool) Option {
+	return func(c *Client) {
+		c.probe = enabled
+	}
+}
+
+// WithQueryOptions sets the options used to build the introspection query.
+func WithQueryOptions(opts QueryOptions) Option {
+	return func(c *Client) {
+		c.queryOpts = opts
+	}
+}
+
+// WithQuery replaces the introspection query with a custom document, e.g. a
+// query registered with a server that only accepts allowlisted operations.
+// The response must still have the shape of an introspection result.
+// Capability probing and QueryOptions are ignored when a query is set.
+func WithQuery(query string) Option {
+	return func(c *Client) {
+		c.query = query
+	}
+}
+
+// NewClient creates a Client for the given GraphQL endpoint URL.
+func NewClient(endpoint string, opts ...Option) *Client {
+	c := &Client{
+		endpoint:   endpoint,
+		httpClient: http.DefaultClient,
+		userAgent:  DefaultUserAgent,
+		header:     make(http.Header),
+		queryOpts:  DefaultQueryOptions(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// logf writes a verbose message if a logger is configured.
+func (c *Client) logf(format string, v ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
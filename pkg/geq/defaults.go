@@ -0,0 +1,118 @@
+package geq
+
+import "strings"
+
+// typeIndex looks up the named types of an introspection response.
+type typeIndex map[string]*FullType
+
+// newTypeIndex indexes the types of response by name.
+func newTypeIndex(response IntrospectionResponse) typeIndex {
+	types := make(typeIndex, len(response.Data.Schema.Types))
+	for i := range response.Data.Schema.Types {
+		t := &response.Data.Schema.Types[i]
+		types[t.Name] = t
+	}
+	return types
+}
+
+// formatDefaultValue prints the introspected default value of an argument or
+// input field of the given type as a canonical GraphQL literal, or in the most
+// compact form if compact is set. Introspection reports default values as
+// literal strings, and servers are not always careful about their form: enum
+// values show up as strings and object fields in any order. The value is
+// therefore parsed and resolved against its input type first. A default that
+// does not parse is printed as is.
+func formatDefaultValue(raw string, typeRef TypeRef, types typeIndex, compact bool) string {
+	value, err := parseValueLiteral(raw)
+	if err != nil {
+		return raw
+	}
+	var sb strings.Builder
+	writeValueLiteral(&sb, coerceValue(value, typeRef, types), compact)
+	return sb.String()
+}
+
+// coerceValue resolves a value literal against an input type, turning it into
+// the literal the type expects where that is unambiguous:
+//   - a string naming a value of an enum type becomes that enum value
+//   - the fields of an input object are put in the order the type defines them
+//   - lists and input objects are resolved item by item and field by field
+//
+// Scalar values, and anything that does not match the type, are kept as they
+// are: guessing what a mismatched default was meant to be would hide a bug in
+// the server's schema.
+func coerceValue(value *Value, typeRef TypeRef, types typeIndex) *Value {
+	if value == nil || value.Kind == ValueNull || value.Kind == ValueVariable {
+		return value
+	}
+
+	switch typeRef.Kind {
+	case KindNonNull:
+		if typeRef.OfType != nil {
+			return coerceValue(value, *typeRef.OfType, types)
+		}
+		return value
+	case KindList:
+		if typeRef.OfType == nil {
+			return value
+		}
+		if value.Kind != ValueList {
+			// A single item is accepted where a list is expected.
+			return coerceValue(value, *typeRef.OfType, types)
+		}
+		coerced := *value
+		coerced.List = make([]*Value, len(value.List))
+		for i, item := range value.List {
+			coerced.List[i] = coerceValue(item, *typeRef.OfType, types)
+		}
+		return &coerced
+	}
+
+	t := types[typeRef.Name]
+	kind := typeRef.Kind
+	if t != nil {
+		kind = t.Kind
+	}
+
+	switch kind {
+	case KindEnum:
+		if value.Kind == ValueString && t != nil {
+			for _, enumValue := range t.EnumValues {
+				if enumValue.Name == value.Raw {
+					return &Value{Kind: ValueEnum, Raw: value.Raw, Location: value.Location}
+				}
+			}
+		}
+	case KindInputObject:
+		if value.Kind == ValueObject && t != nil {
+			return coerceObject(value, t, types)
+		}
+	}
+	return value
+}
+
+// coerceObject resolves the fields of an input object value, in the order of
+// the type's input fields. Fields the type does not define are kept at the end.
+func coerceObject(value *Value, t *FullType, types typeIndex) *Value {
+	coerced := *value
+	coerced.Fields = make([]*ObjectField, 0, len(value.Fields))
+	used := make([]bool, len(value.Fields))
+	for _, inputField := range t.InputFields {
+		for i, field := range value.Fields {
+			if !used[i] && field.Name == inputField.Name {
+				used[i] = true
+				coerced.Fields = append(coerced.Fields, &ObjectField{
+					Name:     field.Name,
+					Value:    coerceValue(field.Value, inputField.Type, types),
+					Location: field.Location,
+				})
+			}
+		}
+	}
+	for i, field := range value.Fields {
+		if !used[i] {
+			coerced.Fields = append(coerced.Fields, field)
+		}
+	}
+	return &coerced
+}
@@ -0,0 +1,65 @@
+package geq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDefaultValue(t *testing.T) {
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {"__schema": {"types": [
+		{"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "USER"}]},
+		{"kind": "SCALAR", "name": "JSON"},
+		{"kind": "INPUT_OBJECT", "name": "Filter", "inputFields": [
+			{"name": "role", "type": {"kind": "ENUM", "name": "Role"}},
+			{"name": "roles", "type": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "ENUM", "name": "Role"}}}},
+			{"name": "limit", "type": {"kind": "SCALAR", "name": "Int"}},
+			{"name": "nested", "type": {"kind": "INPUT_OBJECT", "name": "Filter"}}
+		]}
+	]}}}`), &response))
+	types := newTypeIndex(response)
+
+	named := func(kind, name string) TypeRef { return TypeRef{Kind: kind, Name: name} }
+	nonNull := func(ofType TypeRef) TypeRef { return TypeRef{Kind: KindNonNull, OfType: &ofType} }
+	list := func(ofType TypeRef) TypeRef { return TypeRef{Kind: KindList, OfType: &ofType} }
+
+	tests := []struct {
+		name     string
+		raw      string
+		typeRef  TypeRef
+		expected string
+		compact  string
+	}{
+		{name: "Enum given as string", raw: `"USER"`, typeRef: named(KindEnum, "Role"), expected: "USER", compact: "USER"},
+		{name: "Unknown enum value kept", raw: `"OTHER"`, typeRef: named(KindEnum, "Role"), expected: `"OTHER"`, compact: `"OTHER"`},
+		{name: "Non-null enum", raw: `"ADMIN"`, typeRef: nonNull(named(KindEnum, "Role")), expected: "ADMIN", compact: "ADMIN"},
+		{name: "List of enums", raw: `["ADMIN","USER"]`, typeRef: list(named(KindEnum, "Role")), expected: "[ADMIN, USER]", compact: "[ADMIN USER]"},
+		{name: "Single item for a list", raw: `"ADMIN"`, typeRef: list(named(KindEnum, "Role")), expected: "ADMIN", compact: "ADMIN"},
+		{
+			name:     "Input object fields in type order",
+			raw:      `{limit: "10", nested: {roles: ["USER"], role: "ADMIN"}, role: "USER", extra: 1}`,
+			typeRef:  named(KindInputObject, "Filter"),
+			expected: `{role: USER, limit: "10", nested: {role: ADMIN, roles: [USER]}, extra: 1}`,
+			compact:  `{role:USER limit:"10" nested:{role:ADMIN roles:[USER]} extra:1}`,
+		},
+		{name: "Int", raw: "10", typeRef: named(KindScalar, "Int"), expected: "10", compact: "10"},
+		{name: "Int given as string kept", raw: `"10"`, typeRef: named(KindScalar, "Int"), expected: `"10"`, compact: `"10"`},
+		{name: "Float given as string kept", raw: `"1.5"`, typeRef: named(KindScalar, "Float"), expected: `"1.5"`, compact: `"1.5"`},
+		{name: "Boolean given as string kept", raw: `"true"`, typeRef: named(KindScalar, "Boolean"), expected: `"true"`, compact: `"true"`},
+		{name: "String given as enum kept", raw: "hello", typeRef: named(KindScalar, "String"), expected: "hello", compact: "hello"},
+		{name: "Null", raw: "null", typeRef: named(KindEnum, "Role"), expected: "null", compact: "null"},
+		{name: "Custom scalar kept", raw: `{a: "USER", b: [1,2]}`, typeRef: named(KindScalar, "JSON"), expected: `{a: "USER", b: [1, 2]}`, compact: `{a:"USER" b:[1 2]}`},
+		{name: "Unknown type kept", raw: `"USER"`, typeRef: named("", "Missing"), expected: `"USER"`, compact: `"USER"`},
+		{name: "Invalid literal printed as is", raw: `{oops`, typeRef: named(KindInputObject, "Filter"), expected: `{oops`, compact: `{oops`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, formatDefaultValue(test.raw, test.typeRef, types, false))
+			assert.Equal(t, test.compact, formatDefaultValue(test.raw, test.typeRef, types, true))
+		})
+	}
+}
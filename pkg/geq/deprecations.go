@@ -0,0 +1,72 @@
+package geq
+
+import "sort"
+
+// DeprecationKind is the kind of schema element a Deprecation concerns.
+type DeprecationKind string
+
+// Kinds of deprecated elements.
+const (
+	DeprecatedField      DeprecationKind = "FIELD"
+	DeprecatedArgument   DeprecationKind = "ARGUMENT"
+	DeprecatedInputField DeprecationKind = "INPUT_FIELD"
+	DeprecatedEnumValue  DeprecationKind = "ENUM_VALUE"
+)
+
+// Deprecation is a deprecated field, argument, input field or enum value.
+type Deprecation struct {
+	// Coordinate is the schema coordinate of the element, e.g. "User.name",
+	// "Query.users(first:)" or "@auth(scope:)".
+	Coordinate string `json:"coordinate"`
+	// Kind is the kind of element.
+	Kind DeprecationKind `json:"kind"`
+	// ParentType is the type the element belongs to, or "@name" for the
+	// argument of a directive.
+	ParentType string `json:"parentType"`
+	// Reason is the deprecation reason, DefaultDeprecationReason if the schema
+	// gives none.
+	Reason string `json:"reason"`
+}
+
+// Deprecations lists the deprecated elements of a schema, sorted by parent
+// type and in definition order within a type, with directive arguments first.
+func Deprecations(schema *Schema) []Deprecation {
+	var deprecations []Deprecation
+	add := func(kind DeprecationKind, parent, coordinate string, isDeprecated bool, reason string) {
+		if isDeprecated {
+			deprecations = append(deprecations, Deprecation{
+				Coordinate: coordinate,
+				Kind:       kind,
+				ParentType: parent,
+				Reason:     deprecationReasonOf(isDeprecated, reason),
+			})
+		}
+	}
+	addArgs := func(parent, owner string, args []*InputValueDefinition) {
+		for _, arg := range args {
+			add(DeprecatedArgument, parent, owner+"("+arg.Name+":)", arg.IsDeprecated, arg.DeprecationReason)
+		}
+	}
+
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			coordinate := def.Name + "." + field.Name
+			add(DeprecatedField, def.Name, coordinate, field.IsDeprecated, field.DeprecationReason)
+			addArgs(def.Name, coordinate, field.Args)
+		}
+		for _, field := range def.InputFields {
+			add(DeprecatedInputField, def.Name, def.Name+"."+field.Name, field.IsDeprecated, field.DeprecationReason)
+		}
+		for _, value := range def.EnumValues {
+			add(DeprecatedEnumValue, def.Name, def.Name+"."+value.Name, value.IsDeprecated, value.DeprecationReason)
+		}
+	}
+	for _, directive := range schema.Directives {
+		addArgs("@"+directive.Name, "@"+directive.Name, directive.Args)
+	}
+
+	sort.SliceStable(deprecations, func(i, j int) bool {
+		return deprecations[i].ParentType < deprecations[j].ParentType
+	})
+	return deprecations
+}
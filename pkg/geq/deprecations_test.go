@@ -0,0 +1,42 @@
+package geq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecations(t *testing.T) {
+	schema := mustParseSDL(t, `
+type User {
+  name: String @deprecated(reason: "Use fullName")
+  fullName: String
+  posts(first: Int @deprecated, limit: Int): [String]
+}
+type Query {
+  me: User @deprecated
+}
+input Filter {
+  name: String @deprecated(reason: "Use query")
+  query: String
+}
+enum Role {
+  ADMIN
+  GUEST @deprecated(reason: """
+  Guests sign up now
+  """)
+}
+directive @auth(scope: String @deprecated(reason: "Scopes are implied"), role: Role) on FIELD_DEFINITION
+`)
+
+	assert.Equal(t, []Deprecation{
+		{Coordinate: "@auth(scope:)", Kind: DeprecatedArgument, ParentType: "@auth", Reason: "Scopes are implied"},
+		{Coordinate: "Filter.name", Kind: DeprecatedInputField, ParentType: "Filter", Reason: "Use query"},
+		{Coordinate: "Query.me", Kind: DeprecatedField, ParentType: "Query", Reason: DefaultDeprecationReason},
+		{Coordinate: "Role.GUEST", Kind: DeprecatedEnumValue, ParentType: "Role", Reason: "Guests sign up now"},
+		{Coordinate: "User.name", Kind: DeprecatedField, ParentType: "User", Reason: "Use fullName"},
+		{Coordinate: "User.posts(first:)", Kind: DeprecatedArgument, ParentType: "User", Reason: DefaultDeprecationReason},
+	}, Deprecations(schema))
+
+	assert.Empty(t, Deprecations(mustParseSDL(t, "type Query { a: Int }")))
+}
@@ -0,0 +1,583 @@
+package geq
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Criticality tells how a schema change affects existing clients.
+type Criticality string
+
+const (
+	// CriticalityBreaking marks changes that make existing operations invalid
+	// or change the shape of their results, such as a removed field.
+	CriticalityBreaking Criticality = "BREAKING"
+	// CriticalityDangerous marks changes that keep operations valid but may
+	// surprise existing clients, such as a new enum value they do not handle.
+	CriticalityDangerous Criticality = "DANGEROUS"
+	// CriticalityNonBreaking marks changes that are safe for existing clients.
+	CriticalityNonBreaking Criticality = "NON_BREAKING"
+)
+
+// ChangeType identifies what changed between two schemas. The names follow
+// graphql-inspector.
+type ChangeType string
+
+// Change types reported by Diff.
+const (
+	SchemaQueryTypeChanged        ChangeType = "SCHEMA_QUERY_TYPE_CHANGED"
+	SchemaMutationTypeChanged     ChangeType = "SCHEMA_MUTATION_TYPE_CHANGED"
+	SchemaSubscriptionTypeChanged ChangeType = "SCHEMA_SUBSCRIPTION_TYPE_CHANGED"
+
+	TypeAdded              ChangeType = "TYPE_ADDED"
+	TypeRemoved            ChangeType = "TYPE_REMOVED"
+	TypeKindChanged        ChangeType = "TYPE_KIND_CHANGED"
+	TypeDescriptionChanged ChangeType = "TYPE_DESCRIPTION_CHANGED"
+
+	ObjectTypeInterfaceAdded   ChangeType = "OBJECT_TYPE_INTERFACE_ADDED"
+	ObjectTypeInterfaceRemoved ChangeType = "OBJECT_TYPE_INTERFACE_REMOVED"
+
+	FieldAdded                    ChangeType = "FIELD_ADDED"
+	FieldRemoved                  ChangeType = "FIELD_REMOVED"
+	FieldTypeChanged              ChangeType = "FIELD_TYPE_CHANGED"
+	FieldDescriptionChanged       ChangeType = "FIELD_DESCRIPTION_CHANGED"
+	FieldDeprecationAdded         ChangeType = "FIELD_DEPRECATION_ADDED"
+	FieldDeprecationRemoved       ChangeType = "FIELD_DEPRECATION_REMOVED"
+	FieldDeprecationReasonChanged ChangeType = "FIELD_DEPRECATION_REASON_CHANGED"
+
+	FieldArgumentAdded                    ChangeType = "FIELD_ARGUMENT_ADDED"
+	FieldArgumentRemoved                  ChangeType = "FIELD_ARGUMENT_REMOVED"
+	FieldArgumentTypeChanged              ChangeType = "FIELD_ARGUMENT_TYPE_CHANGED"
+	FieldArgumentDefaultChanged           ChangeType = "FIELD_ARGUMENT_DEFAULT_CHANGED"
+	FieldArgumentDescriptionChanged       ChangeType = "FIELD_ARGUMENT_DESCRIPTION_CHANGED"
+	FieldArgumentDeprecationAdded         ChangeType = "FIELD_ARGUMENT_DEPRECATION_ADDED"
+	FieldArgumentDeprecationRemoved       ChangeType = "FIELD_ARGUMENT_DEPRECATION_REMOVED"
+	FieldArgumentDeprecationReasonChanged ChangeType = "FIELD_ARGUMENT_DEPRECATION_REASON_CHANGED"
+
+	InputFieldAdded                    ChangeType = "INPUT_FIELD_ADDED"
+	InputFieldRemoved                  ChangeType = "INPUT_FIELD_REMOVED"
+	InputFieldTypeChanged              ChangeType = "INPUT_FIELD_TYPE_CHANGED"
+	InputFieldDefaultValueChanged      ChangeType = "INPUT_FIELD_DEFAULT_VALUE_CHANGED"
+	InputFieldDescriptionChanged       ChangeType = "INPUT_FIELD_DESCRIPTION_CHANGED"
+	InputFieldDeprecationAdded         ChangeType = "INPUT_FIELD_DEPRECATION_ADDED"
+	InputFieldDeprecationRemoved       ChangeType = "INPUT_FIELD_DEPRECATION_REMOVED"
+	InputFieldDeprecationReasonChanged ChangeType = "INPUT_FIELD_DEPRECATION_REASON_CHANGED"
+
+	EnumValueAdded                    ChangeType = "ENUM_VALUE_ADDED"
+	EnumValueRemoved                  ChangeType = "ENUM_VALUE_REMOVED"
+	EnumValueDescriptionChanged       ChangeType = "ENUM_VALUE_DESCRIPTION_CHANGED"
+	EnumValueDeprecationAdded         ChangeType = "ENUM_VALUE_DEPRECATION_ADDED"
+	EnumValueDeprecationRemoved       ChangeType = "ENUM_VALUE_DEPRECATION_REMOVED"
+	EnumValueDeprecationReasonChanged ChangeType = "ENUM_VALUE_DEPRECATION_REASON_CHANGED"
+
+	UnionMemberAdded   ChangeType = "UNION_MEMBER_ADDED"
+	UnionMemberRemoved ChangeType = "UNION_MEMBER_REMOVED"
+
+	DirectiveAdded                      ChangeType = "DIRECTIVE_ADDED"
+	DirectiveRemoved                    ChangeType = "DIRECTIVE_REMOVED"
+	DirectiveDescriptionChanged         ChangeType = "DIRECTIVE_DESCRIPTION_CHANGED"
+	DirectiveLocationAdded              ChangeType = "DIRECTIVE_LOCATION_ADDED"
+	DirectiveLocationRemoved            ChangeType = "DIRECTIVE_LOCATION_REMOVED"
+	DirectiveRepeatableAdded            ChangeType = "DIRECTIVE_REPEATABLE_ADDED"
+	DirectiveRepeatableRemoved          ChangeType = "DIRECTIVE_REPEATABLE_REMOVED"
+	DirectiveArgumentAdded              ChangeType = "DIRECTIVE_ARGUMENT_ADDED"
+	DirectiveArgumentRemoved            ChangeType = "DIRECTIVE_ARGUMENT_REMOVED"
+	DirectiveArgumentTypeChanged        ChangeType = "DIRECTIVE_ARGUMENT_TYPE_CHANGED"
+	DirectiveArgumentDefaultChanged     ChangeType = "DIRECTIVE_ARGUMENT_DEFAULT_VALUE_CHANGED"
+	DirectiveArgumentDescriptionChanged ChangeType = "DIRECTIVE_ARGUMENT_DESCRIPTION_CHANGED"
+)
+
+// Change is a single difference between two schemas.
+type Change struct {
+	Type        ChangeType  `json:"type"`
+	Criticality Criticality `json:"criticality"`
+	// Path is the schema coordinate of the changed element: "User",
+	// "User.name", "Query.user(id:)", "Role.ADMIN", "@auth" or "@auth(role:)".
+	// It is empty for changes to the schema definition itself.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// IsBreaking reports whether the change breaks existing clients.
+func (c Change) IsBreaking() bool {
+	return c.Criticality == CriticalityBreaking
+}
+
+// HasBreakingChanges reports whether any of the changes is breaking.
+func HasBreakingChanges(changes []Change) bool {
+	return slices.ContainsFunc(changes, Change.IsBreaking)
+}
+
+// Diff compares two schemas and returns the changes that turn oldSchema into
+// newSchema, classified as graphql-inspector classifies them. Changes to the
+// schema definition come first, then changes to types in name order, then
+// changes to directives.
+//
+// The built-in scalars and the directives defined by the GraphQL
+// specification are not compared, since SDL files usually leave them out
+// while introspection always reports them.
+func Diff(oldSchema, newSchema *Schema) []Change {
+	d := &differ{}
+	d.rootTypes(oldSchema, newSchema)
+	typesStart := len(d.changes)
+
+	isBuiltin := func(def *TypeDefinition) bool { return def.Kind == KindScalar && builtinScalars[def.Name] }
+	oldTypes := slices.DeleteFunc(slices.Clone(oldSchema.Types), isBuiltin)
+	newTypes := slices.DeleteFunc(slices.Clone(newSchema.Types), isBuiltin)
+	compareByName(oldTypes, newTypes, typeName,
+		func(def *TypeDefinition) {
+			d.add(TypeRemoved, CriticalityBreaking, def.Name, "Type '%s' was removed", def.Name)
+		},
+		func(def *TypeDefinition) {
+			d.add(TypeAdded, CriticalityNonBreaking, def.Name, "Type '%s' was added", def.Name)
+		},
+		d.typeDefinition,
+	)
+	sortByOwner(d.changes[typesStart:])
+	directivesStart := len(d.changes)
+
+	isSpecified := func(def *DirectiveDefinition) bool { return specifiedDirectiveNames[def.Name] }
+	oldDirectives := slices.DeleteFunc(slices.Clone(oldSchema.Directives), isSpecified)
+	newDirectives := slices.DeleteFunc(slices.Clone(newSchema.Directives), isSpecified)
+	compareByName(oldDirectives, newDirectives, directiveName,
+		func(def *DirectiveDefinition) {
+			d.add(DirectiveRemoved, CriticalityBreaking, "@"+def.Name, "Directive '%s' was removed", def.Name)
+		},
+		func(def *DirectiveDefinition) {
+			d.add(DirectiveAdded, CriticalityNonBreaking, "@"+def.Name, "Directive '%s' was added", def.Name)
+		},
+		d.directive,
+	)
+	sortByOwner(d.changes[directivesStart:])
+
+	return d.changes
+}
+
+// differ collects the changes found by Diff.
+type differ struct {
+	changes []Change
+}
+
+// add records a change.
+func (d *differ) add(changeType ChangeType, criticality Criticality, path, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Type:        changeType,
+		Criticality: criticality,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// rootTypes compares the root operation types.
+func (d *differ) rootTypes(oldSchema, newSchema *Schema) {
+	roots := []struct {
+		changeType ChangeType
+		operation  string
+		old, new   string
+	}{
+		{SchemaQueryTypeChanged, "query", oldSchema.QueryType, newSchema.QueryType},
+		{SchemaMutationTypeChanged, "mutation", oldSchema.MutationType, newSchema.MutationType},
+		{SchemaSubscriptionTypeChanged, "subscription", oldSchema.SubscriptionType, newSchema.SubscriptionType},
+	}
+	for _, root := range roots {
+		switch {
+		case root.old == root.new:
+		case root.old == "":
+			d.add(root.changeType, CriticalityNonBreaking, "", "Schema %s root type '%s' was added", root.operation, root.new)
+		case root.new == "":
+			d.add(root.changeType, CriticalityBreaking, "", "Schema %s root type '%s' was removed", root.operation, root.old)
+		default:
+			d.add(root.changeType, CriticalityBreaking, "", "Schema %s root has changed from '%s' to '%s'", root.operation, root.old, root.new)
+		}
+	}
+}
+
+// typeDefinition compares two definitions of the same named type.
+func (d *differ) typeDefinition(oldType, newType *TypeDefinition) {
+	if oldType.Kind != newType.Kind {
+		d.add(TypeKindChanged, CriticalityBreaking, newType.Name, "'%s' kind changed from '%s' to '%s'", newType.Name, oldType.Kind, newType.Kind)
+		return
+	}
+	if oldType.Description != newType.Description {
+		d.add(TypeDescriptionChanged, CriticalityNonBreaking, newType.Name, "Description of type '%s' changed", newType.Name)
+	}
+
+	switch newType.Kind {
+	case KindObject, KindInterface:
+		d.interfaces(oldType, newType)
+		d.fields(oldType, newType)
+	case KindInputObject:
+		d.inputFields(oldType, newType)
+	case KindEnum:
+		d.enumValues(oldType, newType)
+	case KindUnion:
+		d.unionMembers(oldType, newType)
+	}
+}
+
+// interfaces compares the interfaces implemented by an object or interface type.
+func (d *differ) interfaces(oldType, newType *TypeDefinition) {
+	for _, name := range oldType.Interfaces {
+		if !slices.Contains(newType.Interfaces, name) {
+			d.add(ObjectTypeInterfaceRemoved, CriticalityBreaking, newType.Name, "'%s' no longer implements interface '%s'", newType.Name, name)
+		}
+	}
+	for _, name := range newType.Interfaces {
+		if !slices.Contains(oldType.Interfaces, name) {
+			d.add(ObjectTypeInterfaceAdded, CriticalityDangerous, newType.Name, "'%s' implements new interface '%s'", newType.Name, name)
+		}
+	}
+}
+
+// fields compares the fields of an object or interface type.
+func (d *differ) fields(oldType, newType *TypeDefinition) {
+	kind := kindDescription(newType.Kind)
+	compareByName(oldType.Fields, newType.Fields, fieldName,
+		func(field *FieldDefinition) {
+			path := newType.Name + "." + field.Name
+			if field.IsDeprecated {
+				d.add(FieldRemoved, CriticalityBreaking, path, "Field '%s' (deprecated) was removed from %s '%s'", field.Name, kind, newType.Name)
+			} else {
+				d.add(FieldRemoved, CriticalityBreaking, path, "Field '%s' was removed from %s '%s'", field.Name, kind, newType.Name)
+			}
+		},
+		func(field *FieldDefinition) {
+			d.add(FieldAdded, CriticalityNonBreaking, newType.Name+"."+field.Name, "Field '%s' was added to %s '%s'", field.Name, kind, newType.Name)
+		},
+		func(oldField, newField *FieldDefinition) {
+			path := newType.Name + "." + newField.Name
+			if oldField.Description != newField.Description {
+				d.add(FieldDescriptionChanged, CriticalityNonBreaking, path, "Description of field '%s' changed", path)
+			}
+			d.deprecation(deprecationChangeTypes{FieldDeprecationAdded, FieldDeprecationRemoved, FieldDeprecationReasonChanged}, "field", path,
+				oldField.IsDeprecated, newField.IsDeprecated, oldField.DeprecationReason, newField.DeprecationReason)
+
+			oldTypeName, newTypeName := TypeRefToString(oldField.Type), TypeRefToString(newField.Type)
+			if oldTypeName != newTypeName {
+				criticality := CriticalityBreaking
+				if isSafeOutputTypeChange(oldField.Type, newField.Type) {
+					criticality = CriticalityNonBreaking
+				}
+				d.add(FieldTypeChanged, criticality, path, "Field '%s' changed type from '%s' to '%s'", path, oldTypeName, newTypeName)
+			}
+
+			d.arguments(fieldArgumentChangeTypes, "field '"+path+"'", path, oldField.Args, newField.Args)
+		},
+	)
+}
+
+// argumentChangeTypes names the changes to the arguments of a field or a
+// directive, which are classified alike but reported as different types.
+type argumentChangeTypes struct {
+	added, removed, typeChanged, defaultChanged, descriptionChanged ChangeType
+	// optionalAdded is the criticality of a new optional argument.
+	optionalAdded Criticality
+	// deprecation names deprecation changes; they are not reported if empty.
+	deprecation deprecationChangeTypes
+}
+
+var (
+	fieldArgumentChangeTypes = argumentChangeTypes{
+		added:              FieldArgumentAdded,
+		removed:            FieldArgumentRemoved,
+		typeChanged:        FieldArgumentTypeChanged,
+		defaultChanged:     FieldArgumentDefaultChanged,
+		descriptionChanged: FieldArgumentDescriptionChanged,
+		optionalAdded:      CriticalityDangerous,
+		deprecation:        deprecationChangeTypes{FieldArgumentDeprecationAdded, FieldArgumentDeprecationRemoved, FieldArgumentDeprecationReasonChanged},
+	}
+	directiveArgumentChangeTypes = argumentChangeTypes{
+		added:              DirectiveArgumentAdded,
+		removed:            DirectiveArgumentRemoved,
+		typeChanged:        DirectiveArgumentTypeChanged,
+		defaultChanged:     DirectiveArgumentDefaultChanged,
+		descriptionChanged: DirectiveArgumentDescriptionChanged,
+		optionalAdded:      CriticalityNonBreaking,
+	}
+)
+
+// arguments compares the arguments of a field or directive. owner describes
+// the field or directive in messages; path is its coordinate.
+func (d *differ) arguments(types argumentChangeTypes, owner, path string, oldArgs, newArgs []*InputValueDefinition) {
+	argPath := func(arg *InputValueDefinition) string { return path + "(" + arg.Name + ":)" }
+	compareByName(oldArgs, newArgs, inputValueName,
+		func(arg *InputValueDefinition) {
+			d.add(types.removed, CriticalityBreaking, argPath(arg), "Argument '%s: %s' was removed from %s", arg.Name, TypeRefToString(arg.Type), owner)
+		},
+		func(arg *InputValueDefinition) {
+			criticality := types.optionalAdded
+			if isRequired(arg) {
+				criticality = CriticalityBreaking
+			}
+			d.add(types.added, criticality, argPath(arg), "Argument '%s: %s' added to %s", arg.Name, TypeRefToString(arg.Type), owner)
+		},
+		func(oldArg, newArg *InputValueDefinition) {
+			p := argPath(newArg)
+			if oldArg.Description != newArg.Description {
+				d.add(types.descriptionChanged, CriticalityNonBreaking, p, "Description of argument '%s' on %s changed", newArg.Name, owner)
+			}
+			if types.deprecation.added != "" {
+				d.deprecation(types.deprecation, "argument", p,
+					oldArg.IsDeprecated, newArg.IsDeprecated, oldArg.DeprecationReason, newArg.DeprecationReason)
+			}
+			oldTypeName, newTypeName := TypeRefToString(oldArg.Type), TypeRefToString(newArg.Type)
+			if oldTypeName != newTypeName {
+				criticality := CriticalityBreaking
+				if isSafeInputTypeChange(oldArg.Type, newArg.Type) {
+					criticality = CriticalityNonBreaking
+				}
+				d.add(types.typeChanged, criticality, p, "Type for argument '%s' on %s changed from '%s' to '%s'", newArg.Name, owner, oldTypeName, newTypeName)
+			}
+			if oldDefault, newDefault := defaultValueString(oldArg.DefaultValue), defaultValueString(newArg.DefaultValue); oldDefault != newDefault {
+				d.add(types.defaultChanged, CriticalityDangerous, p, "Default value for argument '%s' on %s changed from '%s' to '%s'", newArg.Name, owner, orNone(oldDefault), orNone(newDefault))
+			}
+		},
+	)
+}
+
+// inputFields compares the fields of an input object type.
+func (d *differ) inputFields(oldType, newType *TypeDefinition) {
+	compareByName(oldType.InputFields, newType.InputFields, inputValueName,
+		func(field *InputValueDefinition) {
+			d.add(InputFieldRemoved, CriticalityBreaking, newType.Name+"."+field.Name, "Input field '%s' was removed from input object type '%s'", field.Name, newType.Name)
+		},
+		func(field *InputValueDefinition) {
+			criticality := CriticalityDangerous
+			if isRequired(field) {
+				criticality = CriticalityBreaking
+			}
+			d.add(InputFieldAdded, criticality, newType.Name+"."+field.Name, "Input field '%s' was added to input object type '%s'", field.Name, newType.Name)
+		},
+		func(oldField, newField *InputValueDefinition) {
+			path := newType.Name + "." + newField.Name
+			if oldField.Description != newField.Description {
+				d.add(InputFieldDescriptionChanged, CriticalityNonBreaking, path, "Description of input field '%s' changed", path)
+			}
+			d.deprecation(deprecationChangeTypes{InputFieldDeprecationAdded, InputFieldDeprecationRemoved, InputFieldDeprecationReasonChanged}, "input field", path,
+				oldField.IsDeprecated, newField.IsDeprecated, oldField.DeprecationReason, newField.DeprecationReason)
+			oldTypeName, newTypeName := TypeRefToString(oldField.Type), TypeRefToString(newField.Type)
+			if oldTypeName != newTypeName {
+				criticality := CriticalityBreaking
+				if isSafeInputTypeChange(oldField.Type, newField.Type) {
+					criticality = CriticalityNonBreaking
+				}
+				d.add(InputFieldTypeChanged, criticality, path, "Input field '%s' changed type from '%s' to '%s'", path, oldTypeName, newTypeName)
+			}
+			if oldDefault, newDefault := defaultValueString(oldField.DefaultValue), defaultValueString(newField.DefaultValue); oldDefault != newDefault {
+				d.add(InputFieldDefaultValueChanged, CriticalityDangerous, path, "Default value of input field '%s' changed from '%s' to '%s'", path, orNone(oldDefault), orNone(newDefault))
+			}
+		},
+	)
+}
+
+// enumValues compares the values of an enum type.
+func (d *differ) enumValues(oldType, newType *TypeDefinition) {
+	compareByName(oldType.EnumValues, newType.EnumValues, enumValueName,
+		func(value *EnumValueDefinition) {
+			d.add(EnumValueRemoved, CriticalityBreaking, newType.Name+"."+value.Name, "Enum value '%s' was removed from enum '%s'", value.Name, newType.Name)
+		},
+		func(value *EnumValueDefinition) {
+			d.add(EnumValueAdded, CriticalityDangerous, newType.Name+"."+value.Name, "Enum value '%s' was added to enum '%s'", value.Name, newType.Name)
+		},
+		func(oldValue, newValue *EnumValueDefinition) {
+			path := newType.Name + "." + newValue.Name
+			if oldValue.Description != newValue.Description {
+				d.add(EnumValueDescriptionChanged, CriticalityNonBreaking, path, "Description of enum value '%s' changed", path)
+			}
+			d.deprecation(deprecationChangeTypes{EnumValueDeprecationAdded, EnumValueDeprecationRemoved, EnumValueDeprecationReasonChanged}, "enum value", path,
+				oldValue.IsDeprecated, newValue.IsDeprecated, oldValue.DeprecationReason, newValue.DeprecationReason)
+		},
+	)
+}
+
+// unionMembers compares the member types of a union.
+func (d *differ) unionMembers(oldType, newType *TypeDefinition) {
+	for _, name := range oldType.PossibleTypes {
+		if !slices.Contains(newType.PossibleTypes, name) {
+			d.add(UnionMemberRemoved, CriticalityBreaking, newType.Name, "Member '%s' was removed from union type '%s'", name, newType.Name)
+		}
+	}
+	for _, name := range newType.PossibleTypes {
+		if !slices.Contains(oldType.PossibleTypes, name) {
+			d.add(UnionMemberAdded, CriticalityDangerous, newType.Name, "Member '%s' was added to union type '%s'", name, newType.Name)
+		}
+	}
+}
+
+// directive compares two definitions of the same directive.
+func (d *differ) directive(oldDirective, newDirective *DirectiveDefinition) {
+	name := newDirective.Name
+	path := "@" + name
+	if oldDirective.Description != newDirective.Description {
+		d.add(DirectiveDescriptionChanged, CriticalityNonBreaking, path, "Description of directive '%s' changed", name)
+	}
+	switch {
+	case oldDirective.IsRepeatable && !newDirective.IsRepeatable:
+		d.add(DirectiveRepeatableRemoved, CriticalityBreaking, path, "Directive '%s' is no longer repeatable", name)
+	case !oldDirective.IsRepeatable && newDirective.IsRepeatable:
+		d.add(DirectiveRepeatableAdded, CriticalityNonBreaking, path, "Directive '%s' is now repeatable", name)
+	}
+	for _, location := range oldDirective.Locations {
+		if !slices.Contains(newDirective.Locations, location) {
+			d.add(DirectiveLocationRemoved, CriticalityBreaking, path, "Location '%s' was removed from directive '%s'", location, name)
+		}
+	}
+	for _, location := range newDirective.Locations {
+		if !slices.Contains(oldDirective.Locations, location) {
+			d.add(DirectiveLocationAdded, CriticalityNonBreaking, path, "Location '%s' was added to directive '%s'", location, name)
+		}
+	}
+	d.arguments(directiveArgumentChangeTypes, "directive '"+name+"'", path, oldDirective.Args, newDirective.Args)
+}
+
+// deprecationChangeTypes names the deprecation changes of one kind of element.
+type deprecationChangeTypes struct {
+	added, removed, reasonChanged ChangeType
+}
+
+// deprecation compares the deprecation of an element. what describes the
+// element in messages; path is its coordinate.
+func (d *differ) deprecation(types deprecationChangeTypes, what, path string, oldDeprecated, newDeprecated bool, oldReason, newReason string) {
+	switch {
+	case !oldDeprecated && newDeprecated:
+		d.add(types.added, CriticalityNonBreaking, path, "%s '%s' is deprecated", capitalize(what), path)
+	case oldDeprecated && !newDeprecated:
+		d.add(types.removed, CriticalityNonBreaking, path, "%s '%s' is no longer deprecated", capitalize(what), path)
+	case oldDeprecated && oldReason != newReason:
+		d.add(types.reasonChanged, CriticalityNonBreaking, path, "Deprecation reason on %s '%s' changed from '%s' to '%s'", what, path, oldReason, newReason)
+	}
+}
+
+// isSafeOutputTypeChange reports whether changing the type of an output
+// field from oldType to newType keeps existing queries and their results
+// valid: the new type may only add non-null wrappers.
+func isSafeOutputTypeChange(oldType, newType TypeRef) bool {
+	switch oldType.Kind {
+	case KindList:
+		if newType.Kind == KindList {
+			return isSafeOutputTypeChange(ofType(oldType), ofType(newType))
+		}
+		return newType.Kind == KindNonNull && isSafeOutputTypeChange(oldType, ofType(newType))
+	case KindNonNull:
+		return newType.Kind == KindNonNull && isSafeOutputTypeChange(ofType(oldType), ofType(newType))
+	default:
+		if newType.Kind == KindNonNull {
+			return isSafeOutputTypeChange(oldType, ofType(newType))
+		}
+		return newType.Kind != KindList && newType.Name == oldType.Name
+	}
+}
+
+// isSafeInputTypeChange reports whether changing the type of an argument or
+// input field from oldType to newType keeps existing operations valid: the
+// new type may only drop non-null wrappers.
+func isSafeInputTypeChange(oldType, newType TypeRef) bool {
+	switch oldType.Kind {
+	case KindList:
+		return newType.Kind == KindList && isSafeInputTypeChange(ofType(oldType), ofType(newType))
+	case KindNonNull:
+		if newType.Kind == KindNonNull {
+			return isSafeInputTypeChange(ofType(oldType), ofType(newType))
+		}
+		return isSafeInputTypeChange(ofType(oldType), newType)
+	default:
+		return newType.Kind != KindList && newType.Kind != KindNonNull && newType.Name == oldType.Name
+	}
+}
+
+// ofType returns the type wrapped by a list or non-null type reference.
+func ofType(typeRef TypeRef) TypeRef {
+	if typeRef.OfType == nil {
+		return TypeRef{}
+	}
+	return *typeRef.OfType
+}
+
+// isRequired reports whether an argument or input field must be given: it
+// is non-null and has no default value.
+func isRequired(value *InputValueDefinition) bool {
+	return value.Type.Kind == KindNonNull && value.DefaultValue == nil
+}
+
+// kindDescription names a type kind in messages.
+func kindDescription(kind string) string {
+	switch kind {
+	case KindObject:
+		return "object type"
+	case KindInterface:
+		return "interface type"
+	case KindInputObject:
+		return "input object type"
+	}
+	return strings.ToLower(kind) + " type"
+}
+
+// capitalize upper-cases the first letter of an ASCII string.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// orNone returns s, or "none" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func typeName(def *TypeDefinition) string             { return def.Name }
+func directiveName(def *DirectiveDefinition) string   { return def.Name }
+func fieldName(def *FieldDefinition) string           { return def.Name }
+func inputValueName(def *InputValueDefinition) string { return def.Name }
+func enumValueName(def *EnumValueDefinition) string   { return def.Name }
+func changeOwner(change Change) string                { return pathOwner(change.Path) }
+
+// pathOwner returns the type or directive part of a schema coordinate:
+// "User" for "User.name" and "@auth" for "@auth(role:)".
+func pathOwner(path string) string {
+	if i := strings.IndexAny(path, ".("); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// sortByOwner sorts changes by the type or directive they belong to, keeping
+// the order of the changes to each one.
+func sortByOwner(changes []Change) {
+	sortByName(changes, changeOwner)
+}
+
+// sortByName sorts items by the name returned by name.
+func sortByName[T any](items []T, name func(T) string) {
+	slices.SortStableFunc(items, func(a, b T) int { return cmp.Compare(name(a), name(b)) })
+}
+
+// compareByName matches the items of two lists by name and calls removed for
+// the items only in oldItems, added for those only in newItems and matched
+// for those in both. Items are visited in the order of oldItems, followed by
+// the new items in the order of newItems.
+func compareByName[T any](oldItems, newItems []T, name func(T) string, removed, added func(T), matched func(oldItem, newItem T)) {
+	newByName := make(map[string]T, len(newItems))
+	for _, item := range newItems {
+		newByName[name(item)] = item
+	}
+	oldNames := make(map[string]bool, len(oldItems))
+	for _, oldItem := range oldItems {
+		oldNames[name(oldItem)] = true
+		if newItem, ok := newByName[name(oldItem)]; ok {
+			matched(oldItem, newItem)
+		} else {
+			removed(oldItem)
+		}
+	}
+	for _, newItem := range newItems {
+		if !oldNames[name(newItem)] {
+			added(newItem)
+		}
+	}
+}
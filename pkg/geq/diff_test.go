@@ -0,0 +1,184 @@
+package geq
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseSDL(t *testing.T, sdl string) *Schema {
+	t.Helper()
+	schema, err := ParseSDL(strings.NewReader(sdl))
+	require.NoError(t, err)
+	return schema
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected []Change
+	}{
+		{
+			name: "No changes",
+			old:  "type Query { a: Int }",
+			new:  "type Query { a: Int }",
+		},
+		{
+			name: "Types added, removed and changed kind",
+			old:  "type Query { a: Int } type Gone { a: Int } type Shape { a: Int }",
+			new:  "type Query { a: Int } type Added { a: Int } interface Shape { a: Int }",
+			expected: []Change{
+				{Type: TypeAdded, Criticality: CriticalityNonBreaking, Path: "Added"},
+				{Type: TypeRemoved, Criticality: CriticalityBreaking, Path: "Gone"},
+				{Type: TypeKindChanged, Criticality: CriticalityBreaking, Path: "Shape"},
+			},
+		},
+		{
+			name: "Fields",
+			old:  "type Query { a: Int b: String c: [Int] d: Int! e: Int @deprecated }",
+			new:  "type Query { a: Int! b: Int c: [Int!]! d: Int e: Int f: Int }",
+			expected: []Change{
+				{Type: FieldTypeChanged, Criticality: CriticalityNonBreaking, Path: "Query.a"},
+				{Type: FieldTypeChanged, Criticality: CriticalityBreaking, Path: "Query.b"},
+				{Type: FieldTypeChanged, Criticality: CriticalityNonBreaking, Path: "Query.c"},
+				{Type: FieldTypeChanged, Criticality: CriticalityBreaking, Path: "Query.d"},
+				{Type: FieldDeprecationRemoved, Criticality: CriticalityNonBreaking, Path: "Query.e"},
+				{Type: FieldAdded, Criticality: CriticalityNonBreaking, Path: "Query.f"},
+			},
+		},
+		{
+			name: "Field removed and deprecated",
+			old:  "type Query { a: Int b: Int c: Int @deprecated(reason: \"old\") }",
+			new:  "type Query { b: Int @deprecated c: Int @deprecated(reason: \"new\") }",
+			expected: []Change{
+				{Type: FieldRemoved, Criticality: CriticalityBreaking, Path: "Query.a"},
+				{Type: FieldDeprecationAdded, Criticality: CriticalityNonBreaking, Path: "Query.b"},
+				{Type: FieldDeprecationReasonChanged, Criticality: CriticalityNonBreaking, Path: "Query.c"},
+			},
+		},
+		{
+			name: "Arguments",
+			old:  "type Query { f(a: Int, b: Int!, c: Int = 1, d: Int, e: String): Int }",
+			new:  "type Query { f(a: Int!, b: Int, c: Int = 2, e: String, g: Int, h: Int!, i: Int! = 0): Int }",
+			expected: []Change{
+				{Type: FieldArgumentTypeChanged, Criticality: CriticalityBreaking, Path: "Query.f(a:)"},
+				{Type: FieldArgumentTypeChanged, Criticality: CriticalityNonBreaking, Path: "Query.f(b:)"},
+				{Type: FieldArgumentDefaultChanged, Criticality: CriticalityDangerous, Path: "Query.f(c:)"},
+				{Type: FieldArgumentRemoved, Criticality: CriticalityBreaking, Path: "Query.f(d:)"},
+				{Type: FieldArgumentAdded, Criticality: CriticalityDangerous, Path: "Query.f(g:)"},
+				{Type: FieldArgumentAdded, Criticality: CriticalityBreaking, Path: "Query.f(h:)"},
+				{Type: FieldArgumentAdded, Criticality: CriticalityDangerous, Path: "Query.f(i:)"},
+			},
+		},
+		{
+			name: "Input fields",
+			old:  "type Query { a: Int } input In { a: Int b: Int = 1 c: [Int] }",
+			new:  "type Query { a: Int } input In { a: Int! b: Int = 2 c: [Int] d: Int e: Int! }",
+			expected: []Change{
+				{Type: InputFieldTypeChanged, Criticality: CriticalityBreaking, Path: "In.a"},
+				{Type: InputFieldDefaultValueChanged, Criticality: CriticalityDangerous, Path: "In.b"},
+				{Type: InputFieldAdded, Criticality: CriticalityDangerous, Path: "In.d"},
+				{Type: InputFieldAdded, Criticality: CriticalityBreaking, Path: "In.e"},
+			},
+		},
+		{
+			name: "Enums, unions and interfaces",
+			old:  "type Query { a: Int } enum E { A B } union U = Query | X type X implements I { a: Int } interface I { a: Int } interface J { a: Int }",
+			new:  "type Query { a: Int } enum E { A @deprecated C } union U = Query | Y type X implements J { a: Int } type Y { a: Int } interface I { a: Int } interface J { a: Int }",
+			expected: []Change{
+				{Type: EnumValueDeprecationAdded, Criticality: CriticalityNonBreaking, Path: "E.A"},
+				{Type: EnumValueRemoved, Criticality: CriticalityBreaking, Path: "E.B"},
+				{Type: EnumValueAdded, Criticality: CriticalityDangerous, Path: "E.C"},
+				{Type: UnionMemberRemoved, Criticality: CriticalityBreaking, Path: "U"},
+				{Type: UnionMemberAdded, Criticality: CriticalityDangerous, Path: "U"},
+				{Type: ObjectTypeInterfaceRemoved, Criticality: CriticalityBreaking, Path: "X"},
+				{Type: ObjectTypeInterfaceAdded, Criticality: CriticalityDangerous, Path: "X"},
+				{Type: TypeAdded, Criticality: CriticalityNonBreaking, Path: "Y"},
+			},
+		},
+		{
+			name: "Directives",
+			old:  "type Query { a: Int } directive @a(x: Int) repeatable on FIELD | QUERY directive @b on FIELD",
+			new:  "type Query { a: Int } directive @a(x: Int, y: Int!, z: Int) on FIELD | MUTATION directive @c on FIELD",
+			expected: []Change{
+				{Type: DirectiveRepeatableRemoved, Criticality: CriticalityBreaking, Path: "@a"},
+				{Type: DirectiveLocationRemoved, Criticality: CriticalityBreaking, Path: "@a"},
+				{Type: DirectiveLocationAdded, Criticality: CriticalityNonBreaking, Path: "@a"},
+				{Type: DirectiveArgumentAdded, Criticality: CriticalityBreaking, Path: "@a(y:)"},
+				{Type: DirectiveArgumentAdded, Criticality: CriticalityNonBreaking, Path: "@a(z:)"},
+				{Type: DirectiveRemoved, Criticality: CriticalityBreaking, Path: "@b"},
+				{Type: DirectiveAdded, Criticality: CriticalityNonBreaking, Path: "@c"},
+			},
+		},
+		{
+			name: "Root types",
+			old:  "schema { query: Query mutation: Mutation } type Query { a: Int } type Mutation { a: Int }",
+			new:  "schema { query: Root subscription: Query } type Query { a: Int } type Root { a: Int } type Mutation { a: Int }",
+			expected: []Change{
+				{Type: SchemaQueryTypeChanged, Criticality: CriticalityBreaking},
+				{Type: SchemaMutationTypeChanged, Criticality: CriticalityBreaking},
+				{Type: SchemaSubscriptionTypeChanged, Criticality: CriticalityNonBreaking},
+				{Type: TypeAdded, Criticality: CriticalityNonBreaking, Path: "Root"},
+			},
+		},
+		{
+			name: "Descriptions",
+			old:  "\"Old\" type Query { \"old\" a(\"old\" x: Int): Int }",
+			new:  "\"New\" type Query { \"new\" a(\"new\" x: Int): Int }",
+			expected: []Change{
+				{Type: TypeDescriptionChanged, Criticality: CriticalityNonBreaking, Path: "Query"},
+				{Type: FieldDescriptionChanged, Criticality: CriticalityNonBreaking, Path: "Query.a"},
+				{Type: FieldArgumentDescriptionChanged, Criticality: CriticalityNonBreaking, Path: "Query.a(x:)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(mustParseSDL(t, tt.old), mustParseSDL(t, tt.new))
+			for i := range changes {
+				assert.NotEmpty(t, changes[i].Message)
+				changes[i].Message = ""
+			}
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestDiffMessages(t *testing.T) {
+	changes := Diff(
+		mustParseSDL(t, "type Query { user(id: ID): User } type User { name: String @deprecated }"),
+		mustParseSDL(t, "type Query { user(id: ID!): User } type User { email: String }"),
+	)
+	var messages []string
+	for _, change := range changes {
+		messages = append(messages, change.Message)
+	}
+	assert.Equal(t, []string{
+		"Type for argument 'id' on field 'Query.user' changed from 'ID' to 'ID!'",
+		"Field 'name' (deprecated) was removed from object type 'User'",
+		"Field 'email' was added to object type 'User'",
+	}, messages)
+	assert.True(t, HasBreakingChanges(changes))
+	assert.False(t, HasBreakingChanges(changes[2:]))
+}
+
+// TestDiffIntrospectionAndSDL checks that a schema read from introspection
+// and the same schema printed as SDL have no differences, even though
+// introspection also reports the built-in scalars and directives.
+func TestDiffIntrospectionAndSDL(t *testing.T) {
+	for _, fixture := range sdlFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			response := readFixture(t, fixture.input)
+			sdl, err := os.ReadFile(filepath.Join("../../testdata", fixture.golden))
+			require.NoError(t, err)
+			assert.Empty(t, Diff(NewSchema(response), mustParseSDL(t, string(sdl))))
+		})
+	}
+}
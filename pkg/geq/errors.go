@@ -0,0 +1,82 @@
+package geq
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Location is a line and column in a GraphQL document. Lines and columns are 1-based.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is a single entry of the "errors" array of a GraphQL response.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// IntrospectionError is returned when the server does not answer the
+// introspection query with a schema, either because of a non-200 status or
+// because the response carries GraphQL errors and no schema data.
+type IntrospectionError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Errors are the GraphQL errors reported by the server, if any.
+	Errors []GraphQLError
+	// Body is the raw response body, kept when it contains no GraphQL errors.
+	Body string
+}
+
+func (e *IntrospectionError) Error() string {
+	if len(e.Errors) == 0 {
+		if e.StatusCode != http.StatusOK {
+			return fmt.Sprintf("server returned error: %s", e.Body)
+		}
+		return "server returned no schema data"
+	}
+
+	errorMessages := make([]string, len(e.Errors))
+	for i, gqlErr := range e.Errors {
+		errorMessages[i] = gqlErr.Message
+		if len(gqlErr.Locations) > 0 {
+			errorMessages[i] += fmt.Sprintf(" (line %d, column %d)", gqlErr.Locations[0].Line, gqlErr.Locations[0].Column)
+		}
+	}
+	if e.StatusCode == http.StatusOK {
+		return fmt.Sprintf("server returned GraphQL errors: %s", strings.Join(errorMessages, "; "))
+	}
+	return fmt.Sprintf("server returned status %d: %s", e.StatusCode, strings.Join(errorMessages, "; "))
+}
+
+// introspectionDisabledPhrases appear in the error messages servers use when
+// introspection is turned off, e.g. "GraphQL introspection is not allowed".
+var introspectionDisabledPhrases = []string{"disabled", "not allowed", "not enabled", "forbidden"}
+
+// IntrospectionDisabled reports whether the server rejected the query
+// because introspection is turned off.
+func (e *IntrospectionError) IntrospectionDisabled() bool {
+	for _, gqlErr := range e.Errors {
+		if code, ok := gqlErr.Extensions["code"].(string); ok && strings.EqualFold(code, "INTROSPECTION_DISABLED") {
+			return true
+		}
+
+		message := strings.ToLower(gqlErr.Message)
+		if strings.Contains(message, "introspection") {
+			for _, phrase := range introspectionDisabledPhrases {
+				if strings.Contains(message, phrase) {
+					return true
+				}
+			}
+		}
+		// Servers that strip the introspection fields report them as unknown.
+		if strings.Contains(message, "cannot query field") && strings.Contains(message, "__schema") {
+			return true
+		}
+	}
+	return false
+}
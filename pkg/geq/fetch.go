@@ -2,172 +2,279 @@ package geq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+	"time"
 )
 
 // FetchIntrospectionJSON fetches the GraphQL schema using the standard introspection query.
-// It takes the GraphQL endpoint URL and an optional header string (e.g., "Authorization: Bearer token").
-// It returns the raw JSON response as a string.
-func FetchIntrospectionJSON(endpoint, headerStr string) (string, error) {
-	// Use the canonical introspection query from graphql-js
-	introspectionQuery := `
-    query IntrospectionQuery {
-      __schema {
-        queryType { name }
-        mutationType { name }
-        subscriptionType { name }
-        types {
-          ...FullType
-        }
-        directives {
-          name
-          description
-          locations
-          args {
-            ...InputValue
-          }
-        }
-      }
-    }
-
-    fragment FullType on __Type {
-      kind
-      name
-      description
-      fields(includeDeprecated: true) {
-        name
-        description
-        args {
-          ...InputValue
-        }
-        type {
-          ...TypeRef
-        }
-        isDeprecated
-        deprecationReason
-      }
-      inputFields {
-        ...InputValue
-      }
-      interfaces {
-        ...TypeRef
-      }
-      enumValues(includeDeprecated: true) {
-        name
-        description
-        isDeprecated
-        deprecationReason
-      }
-      possibleTypes {
-        ...TypeRef
-      }
-    }
-
-    fragment InputValue on __InputValue {
-      name
-      description
-      type { ...TypeRef }
-      defaultValue
-    }
-
-    fragment TypeRef on __Type {
-      kind
-      name
-      ofType {
-        kind
-        name
-        ofType {
-          kind
-          name
-          ofType {
-            kind
-            name
-            ofType {
-              kind
-              name
-              ofType {
-                kind
-                name
-                ofType {
-                  kind
-                  name
-                  ofType {
-                    kind
-                    name
-                  }
-                }
-              }
-            }
-          }
-        }
-      }
-    }
-  `
+// It takes the GraphQL endpoint URL and optional headers (e.g., an Authorization header)
+// sent with the request. It returns the raw JSON response as a string.
+//
+// It is a thin wrapper around Client for callers that do not need a context or
+// custom transport settings.
+func FetchIntrospectionJSON(endpoint string, headers http.Header) (string, error) {
+	return NewClient(endpoint, WithHeaders(headers)).FetchIntrospectionJSON(context.Background())
+}
 
-	// Prepare the request body
-	requestBody, err := json.Marshal(map[string]interface{}{
-		"query": introspectionQuery,
-	})
+// FetchIntrospectionJSON fetches the GraphQL schema from the client's endpoint
+// and returns the raw JSON response. The query sent is the one returned by
+// IntrospectionQuery.
+func (c *Client) FetchIntrospectionJSON(ctx context.Context) (string, error) {
+	query, err := c.IntrospectionQuery(ctx)
 	if err != nil {
-		return "", fmt.Errorf("error creating request body: %w", err)
+		return "", err
 	}
 
-	// Create request
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(requestBody))
+	resp, err := c.post(ctx, query)
 	if err != nil {
-		return "", fmt.Errorf("error creating request: %w", err)
+		return "", err
+	}
+	body := resp.body
+	if resp.statusCode != http.StatusOK {
+		return "", statusError(resp.statusCode, body)
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
+	// Check that the response carries a schema. Servers that disable
+	// introspection often answer 200 with only an "errors" array.
+	var result struct {
+		Data struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("error parsing introspection response: %w", err)
+	}
+	if !isJSONValue(result.Data.Schema) {
+		return "", &IntrospectionError{StatusCode: resp.statusCode, Errors: result.Errors}
+	}
 
-	// Add custom header if provided
-	if headerStr != "" {
-		parts := strings.SplitN(headerStr, ":", 2)
-		if len(parts) == 2 {
-			name := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			req.Header.Set(name, value)
+	return string(body), nil // Return raw JSON string
+}
+
+// FetchIntrospection fetches the schema like FetchIntrospectionJSON, but
+// decodes the response body as it is read instead of returning the raw JSON,
+// so large schemas are never held in memory twice.
+func (c *Client) FetchIntrospection(ctx context.Context) (IntrospectionResponse, error) {
+	query, err := c.IntrospectionQuery(ctx)
+	if err != nil {
+		return IntrospectionResponse{}, err
+	}
+
+	resp, err := c.open(ctx, query)
+	if err != nil {
+		return IntrospectionResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return IntrospectionResponse{}, fmt.Errorf("error reading response: %w", err)
+		}
+		return IntrospectionResponse{}, statusError(resp.StatusCode, body)
+	}
+	// DecodeIntrospection reports a response with only an "errors" array
+	// as an *IntrospectionError
+	return DecodeIntrospection(resp.Body)
+}
+
+// statusError returns the error for an introspection request answered with a
+// status other than 200: the GraphQL errors in the body, or the raw body if
+// it has none.
+func statusError(statusCode int, body []byte) *IntrospectionError {
+	var result struct {
+		Errors []GraphQLError `json:"errors"`
+	}
+	err := &IntrospectionError{StatusCode: statusCode}
+	if json.Unmarshal(body, &result) == nil && len(result.Errors) > 0 {
+		err.Errors = result.Errors
+	} else {
+		err.Body = string(body) // Fallback to raw body
+	}
+	return err
+}
+
+// IntrospectionQuery returns the query document the client sends to fetch the
+// schema: the custom query set with WithQuery, or one built from the client's
+// QueryOptions. If capability probing is enabled, the server is probed first
+// and the probed Capabilities replace those in the QueryOptions. A failed
+// probe falls back to the configured options.
+func (c *Client) IntrospectionQuery(ctx context.Context) (string, error) {
+	if c.query != "" {
+		return c.query, nil
+	}
+
+	opts := c.queryOpts
+	if c.probe {
+		caps, err := c.ProbeCapabilities(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", err
+			}
+			// Fall back to the configured query; it reports its own errors.
+			c.logf("Capability probe failed, using the standard introspection query: %v", err)
 		} else {
-			return "", fmt.Errorf("invalid header format. Expected 'name: value', got '%s'", headerStr)
+			opts.Capabilities = caps
 		}
 	}
+	return IntrospectionQuery(opts), nil
+}
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// ProbeCapabilities asks the server which introspection fields it supports by
+// introspecting the __Schema, __Type, __Field, __Directive and __InputValue types.
+func (c *Client) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	resp, err := c.post(ctx, capabilitiesQuery)
 	if err != nil {
-		return "", fmt.Errorf("error sending request: %w", err)
+		return Capabilities{}, err
+	}
+
+	var result struct {
+		Data *struct {
+			Schema     *metaType `json:"schema"`
+			Type       *metaType `json:"type"`
+			Field      *metaType `json:"field"`
+			Directive  *metaType `json:"directive"`
+			InputValue *metaType `json:"inputValue"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return Capabilities{}, fmt.Errorf("error parsing capabilities response: %w", err)
+	}
+	if resp.statusCode != http.StatusOK || result.Data == nil {
+		return Capabilities{}, &IntrospectionError{StatusCode: resp.statusCode, Errors: result.Errors, Body: string(resp.body)}
+	}
+
+	d := result.Data
+	caps := capabilitiesFromProbe(d.Schema, d.Type, d.Field, d.Directive, d.InputValue)
+	c.logf("Server capabilities: %+v", caps)
+	return caps, nil
+}
+
+// response is the status, headers and body of a single GraphQL HTTP response.
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// post sends a GraphQL query to the endpoint, as open does, and reads the
+// whole response.
+func (c *Client) post(ctx context.Context, query string) (*response, error) {
+	resp, err := c.open(ctx, query)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("error reading response: %w", err)
+		return nil, fmt.Errorf("error reading response: %w", err)
 	}
+	return &response{statusCode: resp.StatusCode, header: resp.Header, body: body}, nil
+}
 
-	// Check if status code is successful
-	if resp.StatusCode != http.StatusOK {
-		// Try to unmarshal the error response for better formatting, fallback to raw body
-		var errorResp struct {
-			Errors []struct {
-				Message string `json:"message"`
-			} `json:"errors"`
-		}
-		if json.Unmarshal(body, &errorResp) == nil && len(errorResp.Errors) > 0 {
-			errorMessages := make([]string, len(errorResp.Errors))
-			for i, e := range errorResp.Errors {
-				errorMessages[i] = e.Message
+// open sends a GraphQL query to the endpoint and returns the response with its
+// body unread; the caller must close the body. Network errors and transient
+// HTTP statuses are retried according to the client's retry policy; the last
+// response is returned once the attempts are exhausted.
+func (c *Client) open(ctx context.Context, query string) (*http.Response, error) {
+	// Prepare the request body
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"query": query,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating request body: %w", err)
+	}
+
+	attempts := c.retry.MaxRetries + 1
+	for attempt := 1; ; attempt++ {
+		c.logf("Attempt %d/%d: POST %s", attempt, attempts, c.endpoint)
+		resp, err := c.attempt(ctx, requestBody)
+
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= attempts {
+				return nil, err
 			}
-			return "", fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.Join(errorMessages, "; "))
+			c.logf("Attempt %d/%d failed: %v", attempt, attempts, err)
+		case isRetryableStatus(resp.StatusCode) && attempt < attempts:
+			c.logf("Attempt %d/%d failed: server returned status %d", attempt, attempts, resp.StatusCode)
+			retryAfter = c.retry.retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		wait := c.retry.backoff(attempt)
+		if retryAfter > 0 {
+			wait = retryAfter
+			c.logf("Server asked to retry after %s", wait)
+		}
+		c.logf("Retrying in %s", wait.Round(time.Millisecond))
+		if err := sleep(ctx, wait); err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
 		}
-		return "", fmt.Errorf("server returned error: %s", body) // Fallback to raw body
 	}
+}
 
-	return string(body), nil // Return raw JSON string
+// attempt sends a single HTTP request. The client's timeout covers reading
+// the response body, so it only ends when the body is closed.
+func (c *Client) attempt(ctx context.Context, requestBody []byte) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(requestBody))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for name, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	for _, line := range formatHeaders(RedactHeaders(req.Header)) {
+		c.logf("  %s", line)
+	}
+
+	// Send request
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	c.logf("Received %s in %s", resp.Status, time.Since(start).Round(time.Millisecond))
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose is a response body that releases the request's context once
+// it is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
 }
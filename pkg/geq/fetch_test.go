@@ -0,0 +1,425 @@
+package geq
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSampleServer starts a test server that answers every request with the
+// sample introspection response and passes the request to inspect.
+func newSampleServer(t *testing.T, inspect func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err, "Failed to read input JSON file")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inspect != nil {
+			inspect(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientFetchIntrospectionJSON(t *testing.T) {
+	var got *http.Request
+	server := newSampleServer(t, func(r *http.Request) { got = r })
+
+	client := NewClient(server.URL,
+		WithUserAgent("geq-test/1.0"),
+		WithHeader("Authorization", "Bearer token"),
+		WithTimeout(5*time.Second),
+	)
+	result, err := client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+
+	assert.Contains(t, result, `"__schema"`)
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "geq-test/1.0", got.Header.Get("User-Agent"))
+	assert.Equal(t, "Bearer token", got.Header.Get("Authorization"))
+}
+
+func TestClientFetchIntrospection(t *testing.T) {
+	server := newSampleServer(t, nil)
+	response, err := NewClient(server.URL).FetchIntrospection(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Query", response.Data.Schema.QueryType.Name)
+	assert.NotEmpty(t, response.Data.Schema.Types)
+
+	errorStatus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("forbidden"))
+	}))
+	t.Cleanup(errorStatus.Close)
+	_, err = NewClient(errorStatus.URL).FetchIntrospection(context.Background())
+	var introspectionErr *IntrospectionError
+	require.ErrorAs(t, err, &introspectionErr)
+	assert.Equal(t, http.StatusForbidden, introspectionErr.StatusCode)
+	assert.Equal(t, "forbidden", introspectionErr.Body)
+
+	disabled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors": [{"message": "Introspection has been disabled"}], "data": null}`))
+	}))
+	t.Cleanup(disabled.Close)
+	_, err = NewClient(disabled.URL).FetchIntrospection(context.Background())
+	require.ErrorAs(t, err, &introspectionErr)
+	assert.True(t, introspectionErr.IntrospectionDisabled())
+}
+
+func TestClientDefaultUserAgent(t *testing.T) {
+	var userAgent string
+	server := newSampleServer(t, func(r *http.Request) { userAgent = r.UserAgent() })
+
+	_, err := NewClient(server.URL).FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultUserAgent, userAgent)
+}
+
+func TestClientCustomHTTPClient(t *testing.T) {
+	server := newSampleServer(t, nil)
+
+	transport := &countingTransport{base: http.DefaultTransport}
+	client := NewClient(server.URL, WithHTTPClient(&http.Client{Transport: transport}))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, transport.requests)
+}
+
+func TestClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := NewClient(server.URL, WithTimeout(50*time.Millisecond))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientCanceledContext(t *testing.T) {
+	server := newSampleServer(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewClient(server.URL).FetchIntrospectionJSON(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFetchIntrospectionJSONWrapper(t *testing.T) {
+	var auth string
+	server := newSampleServer(t, func(r *http.Request) { auth = r.Header.Get("Authorization") })
+
+	result, err := FetchIntrospectionJSON(server.URL, http.Header{"Authorization": {"Bearer token"}})
+	require.NoError(t, err)
+	assert.Contains(t, result, `"__schema"`)
+	assert.Equal(t, "Bearer token", auth)
+}
+
+func TestClientMultipleHeaders(t *testing.T) {
+	var got http.Header
+	server := newSampleServer(t, func(r *http.Request) { got = r.Header })
+
+	headers, err := ParseHeaders([]string{
+		"Authorization: Bearer token",
+		"X-Tenant-ID: acme",
+		"X-Feature-Flag: a",
+		"X-Feature-Flag: b",
+	})
+	require.NoError(t, err)
+
+	_, err = NewClient(server.URL, WithHeaders(headers)).FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", got.Get("Authorization"))
+	assert.Equal(t, "acme", got.Get("X-Tenant-Id"))
+	assert.Equal(t, []string{"a", "b"}, got.Values("X-Feature-Flag"))
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{name: "Simple header", input: "Authorization: Bearer token", wantName: "Authorization", wantValue: "Bearer token"},
+		{name: "Value with colon", input: "X-Url: https://example.com", wantName: "X-Url", wantValue: "https://example.com"},
+		{name: "Extra whitespace", input: "  X-Tenant :  acme  ", wantName: "X-Tenant", wantValue: "acme"},
+		{name: "Empty value", input: "X-Empty:", wantName: "X-Empty", wantValue: ""},
+		{name: "Missing colon", input: "not-a-header", wantErr: true},
+		{name: "Missing name", input: ": value", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, value, err := ParseHeader(test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantName, name)
+			assert.Equal(t, test.wantValue, value)
+		})
+	}
+}
+
+func TestReadHeaders(t *testing.T) {
+	input := "# gateway headers\nAuthorization: Bearer token\n\nX-Tenant-ID: acme\nX-Feature-Flag: a\nX-Feature-Flag: b\n"
+	headers, err := ReadHeaders(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+	assert.Equal(t, "acme", headers.Get("X-Tenant-ID"))
+	assert.Equal(t, []string{"a", "b"}, headers.Values("X-Feature-Flag"))
+
+	_, err = ReadHeaders(strings.NewReader("Authorization: Bearer token\nbroken\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+// countingTransport counts the requests passed through to the base transport.
+type countingTransport struct {
+	base     http.RoundTripper
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests++
+	return t.base.RoundTrip(r)
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := http.Header{
+		"Authorization": {"Bearer secret-token"},
+		"X-Api-Key":     {"abc123"},
+		"X-Tenant-Id":   {"acme"},
+	}
+	redacted := RedactHeaders(headers)
+	assert.Equal(t, "Bearer ****", redacted.Get("Authorization"))
+	assert.Equal(t, "****", redacted.Get("X-Api-Key"))
+	assert.Equal(t, "acme", redacted.Get("X-Tenant-Id"))
+	assert.Equal(t, "Bearer secret-token", headers.Get("Authorization"), "Original headers must not be modified")
+}
+
+func TestClientVerboseLogging(t *testing.T) {
+	server := newSampleServer(t, nil)
+
+	var logs strings.Builder
+	logger := log.New(&logs, "", 0)
+	client := NewClient(server.URL, WithLogger(logger), WithHeader("Authorization", "Bearer secret-token"))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+
+	assert.Contains(t, logs.String(), "POST "+server.URL)
+	assert.Contains(t, logs.String(), "Authorization: Bearer ****")
+	assert.Contains(t, logs.String(), "200 OK")
+	assert.NotContains(t, logs.String(), "secret-token")
+}
+
+func TestClientGraphQLErrorsInSuccessfulResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"errors": [{
+				"message": "GraphQL introspection is not allowed by Apollo Server, but the query contained __schema or __type.",
+				"locations": [{"line": 3, "column": 7}],
+				"extensions": {"code": "GRAPHQL_VALIDATION_FAILED"}
+			}],
+			"data": null
+		}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL).FetchIntrospectionJSON(context.Background())
+	require.Error(t, err)
+
+	var introspectionErr *IntrospectionError
+	require.ErrorAs(t, err, &introspectionErr)
+	assert.Equal(t, http.StatusOK, introspectionErr.StatusCode)
+	require.Len(t, introspectionErr.Errors, 1)
+	assert.Equal(t, []Location{{Line: 3, Column: 7}}, introspectionErr.Errors[0].Locations)
+	assert.Equal(t, "GRAPHQL_VALIDATION_FAILED", introspectionErr.Errors[0].Extensions["code"])
+	assert.True(t, introspectionErr.IntrospectionDisabled())
+	assert.Contains(t, err.Error(), "introspection is not allowed")
+}
+
+func TestClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Unauthorized"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL).FetchIntrospectionJSON(context.Background())
+	var introspectionErr *IntrospectionError
+	require.ErrorAs(t, err, &introspectionErr)
+	assert.Equal(t, http.StatusUnauthorized, introspectionErr.StatusCode)
+	assert.False(t, introspectionErr.IntrospectionDisabled())
+	assert.Equal(t, "server returned status 401: Unauthorized", err.Error())
+}
+
+func TestIntrospectionErrorDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      GraphQLError
+		expected bool
+	}{
+		{name: "Apollo", err: GraphQLError{Message: "GraphQL introspection is not allowed by Apollo Server"}, expected: true},
+		{name: "Disabled", err: GraphQLError{Message: "Introspection has been disabled for this request"}, expected: true},
+		{name: "Unknown field", err: GraphQLError{Message: `Cannot query field "__schema" on type "Query".`}, expected: true},
+		{name: "Extension code", err: GraphQLError{Message: "denied", Extensions: map[string]any{"code": "INTROSPECTION_DISABLED"}}, expected: true},
+		{name: "Unrelated", err: GraphQLError{Message: "Unauthorized"}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := &IntrospectionError{StatusCode: http.StatusOK, Errors: []GraphQLError{test.err}}
+			assert.Equal(t, test.expected, err.IntrospectionDisabled())
+		})
+	}
+}
+
+func TestClientCapabilityProbe(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		queries = append(queries, req.Query)
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "IntrospectionCapabilities") {
+			_, _ = w.Write([]byte(`{"data": {
+				"schema": {"fields": [{"name": "description", "args": []}, {"name": "types", "args": []}]},
+				"type": {"fields": [{"name": "specifiedByURL", "args": []}, {"name": "isOneOf", "args": []}, {"name": "inputFields", "args": [{"name": "includeDeprecated"}]}]},
+				"field": {"fields": [{"name": "args", "args": [{"name": "includeDeprecated"}]}]},
+				"directive": {"fields": [{"name": "isRepeatable", "args": []}, {"name": "args", "args": []}]},
+				"inputValue": {"fields": [{"name": "isDeprecated", "args": []}, {"name": "deprecationReason", "args": []}]}
+			}}`))
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, WithCapabilityProbe(true))
+	caps, err := client.ProbeCapabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Capabilities{
+		SchemaDescription:            true,
+		SpecifiedByURL:               true,
+		IsOneOf:                      true,
+		DirectiveIsRepeatable:        true,
+		InputValueDeprecation:        true,
+		FieldArgsIncludeDeprecated:   true,
+		InputFieldsIncludeDeprecated: true,
+	}, caps)
+
+	queries = nil
+	_, err = client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	require.Len(t, queries, 2, "Expected a probe request followed by the introspection request")
+	query := queries[1]
+	assert.Contains(t, query, "isRepeatable")
+	assert.Contains(t, query, "specifiedByURL")
+	assert.Contains(t, query, "isOneOf")
+	assert.Contains(t, query, "args(includeDeprecated: true)")
+	assert.Contains(t, query, "inputFields(includeDeprecated: true)")
+	assert.NotContains(t, query, "    args(includeDeprecated: true) {\n        ...InputValue", "Directive args do not support includeDeprecated")
+}
+
+func TestClientCapabilityProbeFallback(t *testing.T) {
+	var queries []string
+	body, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		queries = append(queries, req.Query)
+		if strings.Contains(req.Query, "IntrospectionCapabilities") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"aliases are not supported"}]}`))
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err = NewClient(server.URL, WithCapabilityProbe(true)).FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, IntrospectionQuery(DefaultQueryOptions()), queries[1])
+}
+
+func TestIntrospectionQuery(t *testing.T) {
+	standard := IntrospectionQuery(DefaultQueryOptions())
+	for _, field := range []string{"isRepeatable", "specifiedByURL", "isOneOf", "includeDeprecated: true) {\n      ...InputValue"} {
+		assert.NotContains(t, standard, field)
+	}
+	assert.Contains(t, standard, "fields(includeDeprecated: true)")
+	assert.Equal(t, DefaultTypeRefDepth, strings.Count(standard, "ofType {"))
+	assert.Equal(t, 5, strings.Count(standard, "description"))
+}
+
+func TestIntrospectionQueryOptions(t *testing.T) {
+	opts := DefaultQueryOptions()
+	opts.Descriptions = false
+	opts.IncludeDeprecated = false
+	opts.TypeRefDepth = 3
+	opts.Capabilities = Capabilities{SchemaDescription: true, InputValueDeprecation: true, FieldArgsIncludeDeprecated: true}
+	query := IntrospectionQuery(opts)
+
+	assert.NotContains(t, query, "description")
+	assert.NotContains(t, query, "includeDeprecated")
+	assert.Contains(t, query, "isDeprecated", "Deprecation flags are still requested")
+	assert.Equal(t, 3, strings.Count(query, "ofType {"))
+
+	opts = DefaultQueryOptions()
+	opts.TypeRefDepth = 0
+	assert.Equal(t, IntrospectionQuery(DefaultQueryOptions()), IntrospectionQuery(opts), "Zero depth means the default depth")
+}
+
+func TestClientCustomQuery(t *testing.T) {
+	var queries []string
+	server := newSampleServer(t, func(r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		queries = append(queries, req.Query)
+	})
+
+	customQuery := "query Allowlisted { __schema { types { name } } }"
+	client := NewClient(server.URL, WithQuery(customQuery), WithCapabilityProbe(true))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{customQuery}, queries, "A custom query is sent as-is, without probing")
+}
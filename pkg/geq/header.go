@@ -0,0 +1,125 @@
+package geq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces secret header values in logs and error messages.
+const redactedValue = "****"
+
+// sensitiveHeaders lists header names whose values are always treated as secrets.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// sensitiveHeaderParts are substrings that mark a custom header as secret,
+// e.g. X-Api-Key or X-Auth-Token.
+var sensitiveHeaderParts = []string{"token", "secret", "key", "password", "auth", "session"}
+
+// ParseHeader splits a header string in the format "name: value".
+func ParseHeader(headerStr string) (name, value string, err error) {
+	parts := strings.SplitN(headerStr, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", fmt.Errorf("invalid header format. Expected 'name: value', got '%s'", headerStr)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+// ParseHeaders parses header strings in the format "name: value" into an
+// http.Header. Repeated names are kept as multiple values.
+func ParseHeaders(headerStrs []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, headerStr := range headerStrs {
+		name, value, err := ParseHeader(headerStr)
+		if err != nil {
+			return nil, err
+		}
+		header.Add(name, value)
+	}
+	return header, nil
+}
+
+// ReadHeaders reads headers from r, one "name: value" pair per line.
+// Blank lines and lines starting with '#' are ignored.
+func ReadHeaders(r io.Reader) (http.Header, error) {
+	header := make(http.Header)
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, err := ParseHeader(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		header.Add(name, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading headers: %w", err)
+	}
+	return header, nil
+}
+
+// IsSensitiveHeader reports whether the values of the named header should be
+// treated as secrets and kept out of logs and error messages.
+func IsSensitiveHeader(name string) bool {
+	canonical := http.CanonicalHeaderKey(name)
+	if sensitiveHeaders[canonical] {
+		return true
+	}
+	lower := strings.ToLower(canonical)
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaderValue masks a secret header value. An authentication scheme
+// such as "Bearer" is kept so that logs still show which scheme was used.
+func RedactHeaderValue(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok && scheme != "" {
+		return scheme + " " + redactedValue
+	}
+	return redactedValue
+}
+
+// RedactHeaders returns a copy of h with the values of sensitive headers masked.
+func RedactHeaders(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for name, values := range h {
+		copied := make([]string, len(values))
+		for i, value := range values {
+			if IsSensitiveHeader(name) {
+				value = RedactHeaderValue(value)
+			}
+			copied[i] = value
+		}
+		redacted[name] = copied
+	}
+	return redacted
+}
+
+// formatHeaders renders headers as sorted "name: value" lines for logging.
+func formatHeaders(h http.Header) []string {
+	var lines []string
+	for name, values := range h {
+		for _, value := range values {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,495 @@
+package geq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// NormalizeIntrospectionJSON checks that data holds an introspection result
+// and returns it in the shape FetchIntrospectionJSON returns:
+// {"data": {"__schema": ...}}. Tools write introspection files either with
+// or without the "data" wrapper; both forms are accepted.
+func NormalizeIntrospectionJSON(data []byte) ([]byte, error) {
+	var doc struct {
+		Data *struct {
+			Schema json.RawMessage `json:"__schema"`
+		} `json:"data"`
+		Schema json.RawMessage `json:"__schema"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing introspection JSON: %w", err)
+	}
+
+	if doc.Data != nil && isJSONValue(doc.Data.Schema) {
+		return data, nil
+	}
+	if isJSONValue(doc.Schema) {
+		wrapped, err := json.Marshal(map[string]any{
+			"data": map[string]json.RawMessage{"__schema": doc.Schema},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error wrapping introspection JSON: %w", err)
+		}
+		return wrapped, nil
+	}
+	if len(doc.Errors) > 0 {
+		return nil, &IntrospectionError{StatusCode: 200, Errors: doc.Errors}
+	}
+	return nil, errors.New("input does not contain an introspection result (no __schema field)")
+}
+
+// DecodeIntrospection reads an introspection result from r, with or without
+// the "data" wrapper, as NormalizeIntrospectionJSON accepts it. The JSON is
+// decoded as it is read, so large schemas are never held in memory twice.
+func DecodeIntrospection(r io.Reader) (IntrospectionResponse, error) {
+	var doc struct {
+		Data *struct {
+			Schema *SchemaDef `json:"__schema"`
+		} `json:"data"`
+		Schema *SchemaDef     `json:"__schema"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return IntrospectionResponse{}, fmt.Errorf("error parsing introspection JSON: %w", err)
+	}
+
+	var resp IntrospectionResponse
+	switch {
+	case doc.Data != nil && doc.Data.Schema != nil:
+		resp.Data.Schema = *doc.Data.Schema
+	case doc.Schema != nil:
+		resp.Data.Schema = *doc.Schema
+	case len(doc.Errors) > 0:
+		return IntrospectionResponse{}, &IntrospectionError{StatusCode: 200, Errors: doc.Errors}
+	default:
+		return IntrospectionResponse{}, errors.New("input does not contain an introspection result (no __schema field)")
+	}
+	return resp, nil
+}
+
+// isJSONValue reports whether raw holds a JSON value other than null.
+func isJSONValue(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// SchemaToIntrospection converts a parsed schema into an introspection result
+// of the same shape FetchIntrospectionJSON returns, for tools that consume
+// schema.json files instead of SDL.
+//
+// Built-in scalars referenced by the schema, the introspection meta types
+// (__Schema, __Type, ...) and the directives defined by the GraphQL
+// specification are added, as a server would report them.
+func SchemaToIntrospection(schema *Schema) IntrospectionResponse {
+	var resp IntrospectionResponse
+	s := &resp.Data.Schema
+	s.Description = schema.Description
+	s.QueryType.Name = schema.QueryType
+	s.MutationType.Name = schema.MutationType
+	s.SubscriptionType.Name = schema.SubscriptionType
+
+	defined := make(map[string]bool)
+	for _, def := range schema.Types {
+		defined[def.Name] = true
+	}
+	directives := schema.Directives
+	for _, directive := range specifiedDirectives() {
+		if !hasDirective(directives, directive.Name) && (directive.Name != "oneOf" || usesOneOf(schema)) {
+			directives = append(directives, directive)
+		}
+	}
+
+	// Built-in scalars are only part of the schema when something refers to
+	// them. String and Boolean are always referenced by the introspection types.
+	used := map[string]bool{"String": true, "Boolean": true}
+	for _, name := range namedTypeRefs(schema.Types, directives) {
+		used[name] = true
+	}
+	types := schema.Types
+	for _, name := range []string{"ID", "Int", "Float", "String", "Boolean"} {
+		if used[name] && !defined[name] {
+			types = append(types, &TypeDefinition{Kind: KindScalar, Name: name})
+		}
+	}
+	for _, def := range introspectionTypes() {
+		if !defined[def.Name] {
+			types = append(types, def)
+		}
+	}
+
+	for _, def := range types {
+		t := FullType{
+			Kind:           def.Kind,
+			Name:           def.Name,
+			Description:    def.Description,
+			SpecifiedByURL: def.SpecifiedByURL,
+			IsOneOf:        def.IsOneOf,
+		}
+
+		switch def.Kind {
+		case KindObject, KindInterface:
+			t.Interfaces = []TypeRef{}
+			for _, name := range def.Interfaces {
+				t.Interfaces = append(t.Interfaces, TypeRef{Kind: KindInterface, Name: name})
+			}
+			t.Fields = []Field{}
+			for _, field := range def.Fields {
+				t.Fields = append(t.Fields, Field{
+					Name:              field.Name,
+					Description:       field.Description,
+					Args:              inputValuesToIntrospection(field.Args),
+					Type:              field.Type,
+					IsDeprecated:      field.IsDeprecated,
+					DeprecationReason: field.DeprecationReason,
+				})
+			}
+			if def.Kind == KindInterface {
+				t.PossibleTypes = []TypeRef{}
+				for _, impl := range types {
+					if impl.Kind == KindObject && slices.Contains(impl.Interfaces, def.Name) {
+						t.PossibleTypes = append(t.PossibleTypes, TypeRef{Kind: KindObject, Name: impl.Name})
+					}
+				}
+			}
+		case KindUnion:
+			t.PossibleTypes = []TypeRef{}
+			for _, name := range def.PossibleTypes {
+				t.PossibleTypes = append(t.PossibleTypes, TypeRef{Kind: KindObject, Name: name})
+			}
+		case KindEnum:
+			t.EnumValues = []EnumValue{}
+			for _, value := range def.EnumValues {
+				t.EnumValues = append(t.EnumValues, EnumValue{
+					Name:              value.Name,
+					Description:       value.Description,
+					IsDeprecated:      value.IsDeprecated,
+					DeprecationReason: value.DeprecationReason,
+				})
+			}
+		case KindInputObject:
+			t.InputFields = inputValuesToIntrospection(def.InputFields)
+		}
+		s.Types = append(s.Types, t)
+	}
+
+	for _, directive := range directives {
+		s.Directives = append(s.Directives, Directive{
+			Name:         directive.Name,
+			Description:  directive.Description,
+			IsRepeatable: directive.IsRepeatable,
+			Locations:    directive.Locations,
+			Args:         inputValuesToIntrospection(directive.Args),
+		})
+	}
+
+	return resp
+}
+
+// inputValuesToIntrospection converts argument or input field definitions.
+// The result is never nil, since introspection reports a field without
+// arguments as [].
+func inputValuesToIntrospection(args []*InputValueDefinition) []InputValue {
+	values := []InputValue{}
+	for _, arg := range args {
+		values = append(values, InputValue{
+			Name:              arg.Name,
+			Description:       arg.Description,
+			Type:              arg.Type,
+			DefaultValue:      defaultValueString(arg.DefaultValue),
+			IsDeprecated:      arg.IsDeprecated,
+			DeprecationReason: arg.DeprecationReason,
+		})
+	}
+	return values
+}
+
+// defaultValueString prints a default value the way introspection reports
+// it: as a GraphQL literal, or "" if there is no default.
+func defaultValueString(value *Value) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+// namedTypeRefs returns the names of all types referenced by fields,
+// arguments and input fields.
+func namedTypeRefs(types []*TypeDefinition, directives []*DirectiveDefinition) []string {
+	var names []string
+	add := func(typeRef TypeRef) {
+		for typeRef.OfType != nil {
+			typeRef = *typeRef.OfType
+		}
+		names = append(names, typeRef.Name)
+	}
+	addArgs := func(args []*InputValueDefinition) {
+		for _, arg := range args {
+			add(arg.Type)
+		}
+	}
+	for _, def := range types {
+		for _, field := range def.Fields {
+			add(field.Type)
+			addArgs(field.Args)
+		}
+		addArgs(def.InputFields)
+	}
+	for _, directive := range directives {
+		addArgs(directive.Args)
+	}
+	return names
+}
+
+func hasDirective(directives []*DirectiveDefinition, name string) bool {
+	for _, directive := range directives {
+		if directive.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func usesOneOf(schema *Schema) bool {
+	for _, def := range schema.Types {
+		if def.IsOneOf {
+			return true
+		}
+	}
+	return false
+}
+
+// specifiedDirectivesSDL defines the directives of the GraphQL specification.
+const specifiedDirectivesSDL = `
+"Directs the executor to include this field or fragment only when the ` + "`if`" + ` argument is true."
+directive @include(
+  "Included when true."
+  if: Boolean!
+) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+"Directs the executor to skip this field or fragment when the ` + "`if`" + ` argument is true."
+directive @skip(
+  "Skipped when true."
+  if: Boolean!
+) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+"Marks an element of a GraphQL schema as no longer supported."
+directive @deprecated(
+  "Explains why this element was deprecated, usually also including a suggestion for how to access supported similar data. Formatted using the Markdown syntax, as specified by [CommonMark](https://commonmark.org/)."
+  reason: String = "No longer supported"
+) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
+
+"Exposes a URL that specifies the behavior of this scalar."
+directive @specifiedBy(
+  "The URL that specifies the behavior of this scalar."
+  url: String!
+) on SCALAR
+
+"Indicates exactly one field must be supplied and this field must not be ` + "`null`" + `."
+directive @oneOf on INPUT_OBJECT
+`
+
+// specifiedDirectives returns fresh definitions of the specified directives.
+func specifiedDirectives() []*DirectiveDefinition {
+	schema, err := ParseSDL(strings.NewReader(specifiedDirectivesSDL))
+	if err != nil {
+		panic("geq: invalid specified directives: " + err.Error())
+	}
+	return schema.Directives
+}
+
+// introspectionTypesSDL defines the introspection meta types of the GraphQL
+// specification, with the descriptions graphql-js gives them.
+const introspectionTypesSDL = `
+"""
+A GraphQL Schema defines the capabilities of a GraphQL server. It exposes all available types and directives on the server, as well as the entry points for query, mutation, and subscription operations.
+"""
+type __Schema {
+  description: String
+
+  "A list of all types supported by this server."
+  types: [__Type!]!
+
+  "The type that query operations will be rooted at."
+  queryType: __Type!
+
+  "If this server supports mutation, the type that mutation operations will be rooted at."
+  mutationType: __Type
+
+  "If this server support subscription, the type that subscription operations will be rooted at."
+  subscriptionType: __Type
+
+  "A list of all directives supported by this server."
+  directives: [__Directive!]!
+}
+
+"""
+The fundamental unit of any GraphQL Schema is the type. There are many kinds of types in GraphQL as represented by the ` + "`__TypeKind`" + ` enum.
+
+Depending on the kind of a type, certain fields describe information about that type. Scalar types provide no information beyond a name, description and optional ` + "`specifiedByURL`" + `, while Enum types provide their values. Object and Interface types provide the fields they describe. Abstract types, Union and Interface, provide the Object types possible at runtime. List and NonNull types compose other types.
+"""
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  specifiedByURL: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields(includeDeprecated: Boolean = false): [__InputValue!]
+  ofType: __Type
+  isOneOf: Boolean
+}
+
+"An enum describing what kind of type a given ` + "`__Type`" + ` is."
+enum __TypeKind {
+  "Indicates this type is a scalar."
+  SCALAR
+
+  "Indicates this type is an object. ` + "`fields`" + ` and ` + "`interfaces`" + ` are valid fields."
+  OBJECT
+
+  "Indicates this type is an interface. ` + "`fields`" + `, ` + "`interfaces`" + `, and ` + "`possibleTypes`" + ` are valid fields."
+  INTERFACE
+
+  "Indicates this type is a union. ` + "`possibleTypes`" + ` is a valid field."
+  UNION
+
+  "Indicates this type is an enum. ` + "`enumValues`" + ` is a valid field."
+  ENUM
+
+  "Indicates this type is an input object. ` + "`inputFields`" + ` is a valid field."
+  INPUT_OBJECT
+
+  "Indicates this type is a list. ` + "`ofType`" + ` is a valid field."
+  LIST
+
+  "Indicates this type is a non-null. ` + "`ofType`" + ` is a valid field."
+  NON_NULL
+}
+
+"""
+Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type.
+"""
+type __Field {
+  name: String!
+  description: String
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+"""
+Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values which describe their type and optionally a default value.
+"""
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+
+  "A GraphQL-formatted string representing the default value for this input value."
+  defaultValue: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+"""
+One possible value for a given Enum. Enum values are unique values, not a placeholder for a string or numeric value. However an Enum value is returned in a JSON response as a string.
+"""
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+
+"""
+A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document.
+
+In some cases, you need to provide options to alter GraphQL's execution behavior in ways field arguments will not suffice, such as conditionally including or skipping a field. Directives provide this by describing additional information to the executor.
+"""
+type __Directive {
+  name: String!
+  description: String
+  isRepeatable: Boolean!
+  locations: [__DirectiveLocation!]!
+  args(includeDeprecated: Boolean = false): [__InputValue!]!
+}
+
+"""
+A Directive can be adjacent to many parts of the GraphQL language, a __DirectiveLocation describes one such possible adjacencies.
+"""
+enum __DirectiveLocation {
+  "Location adjacent to a query operation."
+  QUERY
+
+  "Location adjacent to a mutation operation."
+  MUTATION
+
+  "Location adjacent to a subscription operation."
+  SUBSCRIPTION
+
+  "Location adjacent to a field."
+  FIELD
+
+  "Location adjacent to a fragment definition."
+  FRAGMENT_DEFINITION
+
+  "Location adjacent to a fragment spread."
+  FRAGMENT_SPREAD
+
+  "Location adjacent to an inline fragment."
+  INLINE_FRAGMENT
+
+  "Location adjacent to a variable definition."
+  VARIABLE_DEFINITION
+
+  "Location adjacent to a schema definition."
+  SCHEMA
+
+  "Location adjacent to a scalar definition."
+  SCALAR
+
+  "Location adjacent to an object type definition."
+  OBJECT
+
+  "Location adjacent to a field definition."
+  FIELD_DEFINITION
+
+  "Location adjacent to an argument definition."
+  ARGUMENT_DEFINITION
+
+  "Location adjacent to an interface definition."
+  INTERFACE
+
+  "Location adjacent to a union definition."
+  UNION
+
+  "Location adjacent to an enum definition."
+  ENUM
+
+  "Location adjacent to an enum value definition."
+  ENUM_VALUE
+
+  "Location adjacent to an input object type definition."
+  INPUT_OBJECT
+
+  "Location adjacent to an input object field definition."
+  INPUT_FIELD_DEFINITION
+}
+`
+
+// introspectionTypes returns fresh definitions of the introspection meta
+// types.
+func introspectionTypes() []*TypeDefinition {
+	schema, err := ParseSDL(strings.NewReader(introspectionTypesSDL))
+	if err != nil {
+		panic("geq: invalid introspection types: " + err.Error())
+	}
+	return schema.Types
+}
@@ -0,0 +1,215 @@
+package geq
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIntrospectionJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "Data wrapper", input: `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": []}}}`},
+		{name: "Bare schema", input: `{"__schema": {"queryType": {"name": "Query"}, "types": []}}`},
+		{name: "Errors only", input: `{"errors": [{"message": "introspection is disabled"}], "data": null}`, wantErr: true},
+		{name: "No schema", input: `{"foo": 1}`, wantErr: true},
+		{name: "Invalid JSON", input: `{`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			normalized, err := NormalizeIntrospectionJSON([]byte(test.input))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var response IntrospectionResponse
+			require.NoError(t, json.Unmarshal(normalized, &response))
+			assert.Equal(t, "Query", response.Data.Schema.QueryType.Name)
+		})
+	}
+}
+
+func TestDecodeIntrospection(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "Data wrapper", input: `{"data": {"__schema": {"queryType": {"name": "Query"}, "types": [{"kind": "OBJECT", "name": "Query"}]}}}`},
+		{name: "Bare schema", input: `{"__schema": {"queryType": {"name": "Query"}, "types": [{"kind": "OBJECT", "name": "Query"}]}}`},
+		{name: "Errors only", input: `{"errors": [{"message": "introspection is disabled"}], "data": null}`, wantErr: "introspection is disabled"},
+		{name: "No schema", input: `{"foo": 1}`, wantErr: "no __schema field"},
+		{name: "Invalid JSON", input: `{`, wantErr: "error parsing introspection JSON"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := DecodeIntrospection(strings.NewReader(test.input))
+			if test.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Query", response.Data.Schema.QueryType.Name)
+			require.Len(t, response.Data.Schema.Types, 1)
+			assert.Equal(t, KindObject, response.Data.Schema.Types[0].Kind)
+		})
+	}
+}
+
+func TestSchemaToIntrospection(t *testing.T) {
+	schema, err := ParseSDL(strings.NewReader(`
+"The schema"
+schema { query: Query }
+
+interface Node { id: ID! }
+
+type Query implements Node {
+  id: ID!
+  users(role: Role = ADMIN, first: Int): [User!]! @deprecated(reason: "Use search")
+}
+
+type User implements Node { id: ID! }
+
+enum Role { ADMIN USER }
+
+input Filter @oneOf { name: String, age: Int = 3 }
+
+union Result = User | Query
+
+scalar Date @specifiedBy(url: "https://example.com/date")
+
+directive @cached(ttl: Int = 60) repeatable on FIELD_DEFINITION
+`))
+	require.NoError(t, err)
+
+	resp := SchemaToIntrospection(schema)
+	s := resp.Data.Schema
+	assert.Equal(t, "The schema", s.Description)
+	assert.Equal(t, "Query", s.QueryType.Name)
+	assert.Empty(t, s.MutationType.Name)
+
+	types := make(map[string]int)
+	for i, typ := range s.Types {
+		types[typ.Name] = i
+	}
+	for _, name := range []string{"Node", "Query", "User", "Role", "Filter", "Result", "Date", "ID", "Int", "String", "Boolean"} {
+		assert.Contains(t, types, name)
+	}
+	assert.NotContains(t, types, "Float", "unreferenced built-in scalars are not added")
+
+	// The meta types are reported as a server reports them
+	for _, name := range []string{"__Schema", "__Type", "__TypeKind", "__Field", "__InputValue", "__EnumValue", "__Directive", "__DirectiveLocation"} {
+		assert.Contains(t, types, name)
+	}
+	typeType := s.Types[types["__Type"]]
+	assert.Equal(t, KindObject, typeType.Kind)
+	require.Len(t, typeType.Fields, 11)
+	assert.Equal(t, "fields", typeType.Fields[4].Name)
+	assert.Equal(t, "[__Field!]", TypeRefToString(typeType.Fields[4].Type))
+	require.Len(t, typeType.Fields[4].Args, 1)
+	assert.Equal(t, "false", typeType.Fields[4].Args[0].DefaultValue)
+	assert.Len(t, s.Types[types["__TypeKind"]].EnumValues, 8)
+	assert.Len(t, s.Types[types["__DirectiveLocation"]].EnumValues, 19)
+
+	node := s.Types[types["Node"]]
+	assert.Equal(t, []TypeRef{{Kind: KindObject, Name: "Query"}, {Kind: KindObject, Name: "User"}}, node.PossibleTypes)
+
+	query := s.Types[types["Query"]]
+	assert.Equal(t, []TypeRef{{Kind: KindInterface, Name: "Node"}}, query.Interfaces)
+	require.Len(t, query.Fields, 2)
+	users := query.Fields[1]
+	assert.True(t, users.IsDeprecated)
+	assert.Equal(t, "Use search", users.DeprecationReason)
+	assert.Equal(t, "[User!]!", TypeRefToString(users.Type))
+	require.Len(t, users.Args, 2)
+	assert.Equal(t, "ADMIN", users.Args[0].DefaultValue)
+	assert.Empty(t, users.Args[1].DefaultValue)
+	assert.NotNil(t, query.Fields[0].Args)
+
+	filter := s.Types[types["Filter"]]
+	assert.True(t, filter.IsOneOf)
+	require.Len(t, filter.InputFields, 2)
+	assert.Equal(t, "3", filter.InputFields[1].DefaultValue)
+
+	assert.Equal(t, []TypeRef{{Kind: KindObject, Name: "User"}, {Kind: KindObject, Name: "Query"}}, s.Types[types["Result"]].PossibleTypes)
+	assert.Equal(t, "https://example.com/date", s.Types[types["Date"]].SpecifiedByURL)
+
+	var directives []string
+	for _, directive := range s.Directives {
+		directives = append(directives, directive.Name)
+	}
+	assert.Equal(t, []string{"cached", "include", "skip", "deprecated", "specifiedBy", "oneOf"}, directives)
+	assert.True(t, s.Directives[0].IsRepeatable)
+	assert.Equal(t, "60", s.Directives[0].Args[0].DefaultValue)
+
+	// Missing root types and default values are null rather than empty
+	// strings, which introspection clients would reject.
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"mutationType":null`)
+	assert.NotContains(t, string(data), `"defaultValue":""`)
+	assert.Contains(t, string(data), `"interfaces":[]`)
+
+	// The result reads back like a fetched introspection result.
+	var decoded IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	sdl := GenerateSDL(decoded)
+	assert.Contains(t, sdl, "interface Node {")
+	assert.Contains(t, sdl, "input Filter @oneOf {")
+	assert.Contains(t, sdl, `scalar Date @specifiedBy(url: "https://example.com/date")`)
+	assert.NotContains(t, sdl, "__Schema")
+	assert.Nil(t, NewSchema(decoded).TypeByName("__Type"))
+}
+
+// TestSchemaToIntrospectionMatchesReference compares the JSON converted from
+// an SDL file with the introspection result graphql-js returns for the same
+// schema. Types are compared by name, as their order is not significant, and
+// built-in scalars are added without the descriptions servers give them. The
+// reference was captured without the introspection meta types, which
+// TestSchemaToIntrospection covers.
+func TestSchemaToIntrospectionMatchesReference(t *testing.T) {
+	sdl, err := os.ReadFile(filepath.Join("../../testdata", "reference_schema.graphql"))
+	require.NoError(t, err)
+	schema, err := ParseSDL(strings.NewReader(string(sdl)))
+	require.NoError(t, err)
+	converted, err := json.Marshal(SchemaToIntrospection(schema))
+	require.NoError(t, err)
+	reference, err := os.ReadFile(filepath.Join("../../testdata", "reference_introspection.json"))
+	require.NoError(t, err)
+
+	var actual, expected map[string]any
+	require.NoError(t, json.Unmarshal(converted, &actual))
+	require.NoError(t, json.Unmarshal(reference, &expected))
+	actualSchema := actual["data"].(map[string]any)["__schema"].(map[string]any)
+	expectedSchema := expected["data"].(map[string]any)["__schema"].(map[string]any)
+	for _, s := range []map[string]any{actualSchema, expectedSchema} {
+		types := slices.DeleteFunc(s["types"].([]any), func(t any) bool {
+			return strings.HasPrefix(t.(map[string]any)["name"].(string), "__")
+		})
+		s["types"] = types
+		for _, t := range types {
+			if def := t.(map[string]any); builtinScalars[def["name"].(string)] {
+				def["description"] = nil
+			}
+		}
+		sort.Slice(types, func(i, j int) bool {
+			return types[i].(map[string]any)["name"].(string) < types[j].(map[string]any)["name"].(string)
+		})
+	}
+	assert.Equal(t, expectedSchema, actualSchema)
+}
@@ -0,0 +1,456 @@
+package geq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind identifies the lexical category of a token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenBlockString
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of file"
+	case tokenPunctuator:
+		return "punctuator"
+	case tokenName:
+		return "name"
+	case tokenInt:
+		return "int"
+	case tokenFloat:
+		return "float"
+	case tokenString:
+		return "string"
+	case tokenBlockString:
+		return "block string"
+	}
+	return "unknown token"
+}
+
+// token is a lexical token of a GraphQL document. For strings, value holds the
+// decoded string value; for all other tokens it holds the source text.
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// describe renders the token for error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenPunctuator:
+		return fmt.Sprintf("%q", t.value)
+	case tokenString, tokenBlockString:
+		return "string"
+	}
+	return fmt.Sprintf("%s %q", t.kind, t.value)
+}
+
+// ParseError is a syntax or schema construction error in a GraphQL document,
+// with the location it was found at.
+type ParseError struct {
+	Message  string
+	Location Location
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Location.Line, e.Location.Column, e.Message)
+}
+
+// lexer splits a GraphQL document into tokens, following the lexical grammar
+// of the GraphQL specification (October 2021).
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	lineStart int
+}
+
+// tokenize returns all tokens in src, ending with an EOF token.
+func tokenize(src string) ([]token, error) {
+	l := &lexer{src: src, line: 1}
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+// location returns the location of the byte offset pos on the current line.
+func (l *lexer) location(pos int) Location {
+	return Location{Line: l.line, Column: utf8.RuneCountInString(l.src[l.lineStart:pos]) + 1}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...any) error {
+	return &ParseError{Message: fmt.Sprintf(format, args...), Location: l.location(pos)}
+}
+
+// newline records a line terminator ending at pos.
+func (l *lexer) newline(pos int) {
+	l.line++
+	l.lineStart = pos
+}
+
+// skipIgnored skips whitespace, line terminators, commas, comments and the
+// byte order mark.
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline(l.pos)
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline(l.pos)
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], "\uFEFF") {
+				l.pos += len("\uFEFF")
+				continue
+			}
+			return
+		}
+	}
+}
+
+// next returns the next token.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	start := l.pos
+	loc := l.location(start)
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c), loc: loc}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokenPunctuator, value: "...", loc: loc}, nil
+		}
+		return token{}, l.errorf(start, "unexpected character %q, did you mean \"...\"?", '.')
+	case isNameStart(c):
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber()
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.readBlockString()
+		}
+		return l.readString()
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+// readNumber reads an IntValue or FloatValue.
+func (l *lexer) readNumber() (token, error) {
+	start := l.pos
+	loc := l.location(start)
+	kind := tokenInt
+
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			return token{}, l.errorf(l.pos, "invalid number, unexpected digit after 0")
+		}
+	} else if err := l.readDigits(); err != nil {
+		return token{}, err
+	}
+
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if err := l.readDigits(); err != nil {
+			return token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if err := l.readDigits(); err != nil {
+			return token{}, err
+		}
+	}
+
+	// A number must not be directly followed by a name or a dot.
+	if l.pos < len(l.src) && (l.src[l.pos] == '.' || isNameStart(l.src[l.pos])) {
+		return token{}, l.errorf(l.pos, "invalid number, unexpected character %q", l.src[l.pos])
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+// readDigits reads one or more decimal digits.
+func (l *lexer) readDigits() error {
+	if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+		if l.pos >= len(l.src) {
+			return l.errorf(l.pos, "invalid number, expected digit but got end of file")
+		}
+		return l.errorf(l.pos, "invalid number, expected digit but got %q", l.src[l.pos])
+	}
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return nil
+}
+
+// readString reads a single-line string literal and decodes its escapes.
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	loc := l.location(start)
+	l.pos++ // opening quote
+
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokenString, value: sb.String(), loc: loc}, nil
+		case '\n', '\r':
+			return token{}, l.errorf(l.pos, "unterminated string")
+		case '\\':
+			if err := l.readEscape(&sb); err != nil {
+				return token{}, err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r < 0x20 && r != '\t' {
+				return token{}, l.errorf(l.pos, "invalid character within string: %U", r)
+			}
+			sb.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, l.errorf(l.pos, "unterminated string")
+}
+
+// readEscape decodes an escape sequence starting at the backslash.
+func (l *lexer) readEscape(sb *strings.Builder) error {
+	start := l.pos
+	l.pos++ // backslash
+	if l.pos >= len(l.src) {
+		return l.errorf(start, "unterminated string")
+	}
+	c := l.src[l.pos]
+	l.pos++
+	switch c {
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case '/':
+		sb.WriteByte('/')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		r, err := l.readUnicodeEscape(start)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+	default:
+		return l.errorf(start, "invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+// readUnicodeEscape decodes the code point of a \uXXXX or \u{X...} escape,
+// combining UTF-16 surrogate pairs written as two fixed-width escapes.
+func (l *lexer) readUnicodeEscape(start int) (rune, error) {
+	if l.pos < len(l.src) && l.src[l.pos] == '{' {
+		end := strings.IndexByte(l.src[l.pos:], '}')
+		if end < 0 {
+			return 0, l.errorf(start, "invalid unicode escape sequence")
+		}
+		code, err := strconv.ParseUint(l.src[l.pos+1:l.pos+end], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return 0, l.errorf(start, "invalid unicode escape sequence %s", l.src[start:l.pos+end+1])
+		}
+		l.pos += end + 1
+		return rune(code), nil
+	}
+
+	code, ok := l.readHex4()
+	if !ok {
+		return 0, l.errorf(start, "invalid unicode escape sequence")
+	}
+	r := rune(code)
+	if r >= 0xD800 && r <= 0xDBFF {
+		// A leading surrogate must be followed by an escaped trailing surrogate.
+		if strings.HasPrefix(l.src[l.pos:], `\u`) {
+			save := l.pos
+			l.pos += 2
+			if trail, ok := l.readHex4(); ok && trail >= 0xDC00 && trail <= 0xDFFF {
+				return (r-0xD800)<<10 + (rune(trail) - 0xDC00) + 0x10000, nil
+			}
+			l.pos = save
+		}
+		return 0, l.errorf(start, "invalid unicode escape sequence, unpaired surrogate")
+	}
+	if r >= 0xDC00 && r <= 0xDFFF {
+		return 0, l.errorf(start, "invalid unicode escape sequence, unpaired surrogate")
+	}
+	return r, nil
+}
+
+// readHex4 reads exactly four hex digits.
+func (l *lexer) readHex4() (uint64, bool) {
+	if l.pos+4 > len(l.src) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	l.pos += 4
+	return code, true
+}
+
+// readBlockString reads a block string literal and returns its value with
+// common indentation and leading and trailing blank lines removed.
+func (l *lexer) readBlockString() (token, error) {
+	start := l.pos
+	loc := l.location(start)
+	l.pos += 3 // opening quotes
+
+	var raw strings.Builder
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return token{kind: tokenBlockString, value: blockStringValue(raw.String()), loc: loc}, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			l.pos += 4
+		case l.src[l.pos] == '\n':
+			raw.WriteByte('\n')
+			l.pos++
+			l.newline(l.pos)
+		case l.src[l.pos] == '\r':
+			raw.WriteByte('\n')
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline(l.pos)
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r < 0x20 && r != '\t' {
+				return token{}, l.errorf(l.pos, "invalid character within string: %U", r)
+			}
+			raw.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, l.errorf(start, "unterminated block string")
+}
+
+// blockStringValue implements the BlockStringValue algorithm of the
+// specification: it removes the common indentation of all lines but the
+// first, then removes leading and trailing blank lines.
+func blockStringValue(raw string) string {
+	lines := strings.Split(raw, "\n")
+
+	commonIndent := -1
+	for _, line := range lines[1:] {
+		indent := leadingWhitespace(line)
+		if indent < len(line) && (commonIndent < 0 || indent < commonIndent) {
+			commonIndent = indent
+		}
+	}
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= commonIndent {
+				lines[i] = lines[i][commonIndent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// leadingWhitespace returns the number of leading spaces and tabs in line.
+func leadingWhitespace(line string) int {
+	i := 0
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// isBlank reports whether line contains only spaces and tabs.
+func isBlank(line string) bool {
+	return leadingWhitespace(line) == len(line)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
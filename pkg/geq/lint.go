@@ -0,0 +1,338 @@
+package geq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity is how seriously a lint rule is taken.
+type Severity string
+
+// Lint severities. A rule set to SeverityOff is not checked.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityOff     Severity = "off"
+)
+
+// LintRule is a check Lint can run on a schema.
+type LintRule struct {
+	// Name identifies the rule in a LintConfig and in LintProblem.Rule.
+	Name string
+	// Description says what the rule requires.
+	Description string
+	// Severity is the severity of the rule unless a LintConfig sets another.
+	Severity Severity
+
+	// check returns a message if the element breaks the rule, or "".
+	check func(e lintElement) string
+}
+
+// LintConfig configures Lint.
+type LintConfig struct {
+	// Rules sets the severity of rules by name. Rules that are not listed keep
+	// their default severity.
+	Rules map[string]Severity `json:"rules"`
+}
+
+// LintProblem is a place where a schema breaks a lint rule.
+type LintProblem struct {
+	// Rule is the name of the broken rule.
+	Rule string `json:"rule"`
+	// Severity is the severity of the rule.
+	Severity Severity `json:"severity"`
+	// Coordinate is the schema coordinate of the offending element.
+	Coordinate string `json:"coordinate"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// Location is where the element is defined in SDL; zero for a schema
+	// built from introspection.
+	Location Location `json:"location"`
+}
+
+var (
+	pascalCase = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	camelCase  = regexp.MustCompile(`^_*[a-z][A-Za-z0-9]*$`)
+	upperCase  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// LintRules are the rules Lint knows, with their default severities.
+var LintRules = []LintRule{
+	{
+		Name:        "type-names-pascal-case",
+		Description: "Type names are PascalCase",
+		Severity:    SeverityError,
+		check: func(e lintElement) string {
+			if e.kind == lintType && !pascalCase.MatchString(e.name) {
+				return fmt.Sprintf("Type name '%s' is not PascalCase", e.name)
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "field-names-camel-case",
+		Description: "Field, input field and argument names are camelCase",
+		Severity:    SeverityError,
+		check: func(e lintElement) string {
+			if e.typeRef != nil && !camelCase.MatchString(e.name) {
+				return fmt.Sprintf("%s name '%s' is not camelCase", e.kind, e.name)
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "enum-values-upper-case",
+		Description: "Enum values are UPPER_CASE",
+		Severity:    SeverityError,
+		check: func(e lintElement) string {
+			if e.kind == lintEnumValue && !upperCase.MatchString(e.name) {
+				return fmt.Sprintf("Enum value '%s' is not UPPER_CASE", e.name)
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "descriptions-required",
+		Description: "Types, fields and input fields have a description",
+		Severity:    SeverityWarning,
+		check: func(e lintElement) string {
+			switch e.kind {
+			case lintType, lintField, lintInputField:
+				if strings.TrimSpace(e.description) == "" {
+					return fmt.Sprintf("%s '%s' has no description", e.kind, e.coordinate)
+				}
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "input-type-suffix",
+		Description: "Input object type names end in 'Input'",
+		Severity:    SeverityError,
+		check: func(e lintElement) string {
+			if e.kind == lintType && e.typeKind == KindInputObject && !strings.HasSuffix(e.name, "Input") {
+				return fmt.Sprintf("Input type name '%s' does not end in 'Input'", e.name)
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "list-items-non-null",
+		Description: "List items are non-null: [T!] rather than [T]",
+		Severity:    SeverityWarning,
+		check: func(e lintElement) string {
+			for ref := e.typeRef; ref != nil; ref = ref.OfType {
+				if ref.Kind == KindList && (ref.OfType == nil || ref.OfType.Kind != KindNonNull) {
+					return fmt.Sprintf("%s '%s' has type '%s', whose list items can be null", e.kind, e.coordinate, TypeRefToString(*e.typeRef))
+				}
+			}
+			return ""
+		},
+	},
+	{
+		Name:        "deprecation-reason-required",
+		Description: "Deprecations give a reason",
+		Severity:    SeverityError,
+		check: func(e lintElement) string {
+			if e.isDeprecated && (e.deprecationReason == "" || e.deprecationReason == DefaultDeprecationReason) {
+				return fmt.Sprintf("%s '%s' is deprecated without a reason", e.kind, e.coordinate)
+			}
+			return ""
+		},
+	},
+}
+
+// DefaultLintConfig returns a configuration that sets every rule to its
+// default severity.
+func DefaultLintConfig() LintConfig {
+	config := LintConfig{Rules: make(map[string]Severity, len(LintRules))}
+	for _, rule := range LintRules {
+		config.Rules[rule.Name] = rule.Severity
+	}
+	return config
+}
+
+// ReadLintConfig reads a JSON lint configuration, such as
+//
+//	{"rules": {"descriptions-required": "error", "list-items-non-null": "off"}}
+//
+// Unknown rules and severities are errors.
+func ReadLintConfig(r io.Reader) (LintConfig, error) {
+	var config LintConfig
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return LintConfig{}, fmt.Errorf("invalid lint configuration: %w", err)
+	}
+	if err := config.validate(); err != nil {
+		return LintConfig{}, err
+	}
+	return config, nil
+}
+
+// validate checks that the configuration names known rules and severities.
+func (c LintConfig) validate() error {
+	known := make(map[string]bool, len(LintRules))
+	for _, rule := range LintRules {
+		known[rule.Name] = true
+	}
+	names := make([]string, 0, len(c.Rules))
+	for name := range c.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("unknown lint rule '%s'", name)
+		}
+		switch c.Rules[name] {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return fmt.Errorf("invalid severity '%s' for lint rule '%s'. Expected 'error', 'warning' or 'off'", c.Rules[name], name)
+		}
+	}
+	return nil
+}
+
+// Lint checks a schema against the lint rules, with the severities config
+// sets, and returns the problems in definition order. Built-in scalars and
+// directives are not checked.
+func Lint(schema *Schema, config LintConfig) ([]LintProblem, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	type activeRule struct {
+		LintRule
+		severity Severity
+	}
+	var rules []activeRule
+	for _, rule := range LintRules {
+		severity := rule.Severity
+		if configured, ok := config.Rules[rule.Name]; ok {
+			severity = configured
+		}
+		if severity != SeverityOff {
+			rules = append(rules, activeRule{rule, severity})
+		}
+	}
+
+	var problems []LintProblem
+	walkLintElements(schema, func(e lintElement) {
+		for _, rule := range rules {
+			if message := rule.check(e); message != "" {
+				problems = append(problems, LintProblem{
+					Rule:       rule.Name,
+					Severity:   rule.severity,
+					Coordinate: e.coordinate,
+					Message:    message,
+					Location:   e.location,
+				})
+			}
+		}
+	})
+	return problems, nil
+}
+
+// lintElementKind is the kind of a lintElement, as named in messages.
+type lintElementKind string
+
+const (
+	lintType       lintElementKind = "Type"
+	lintField      lintElementKind = "Field"
+	lintArgument   lintElementKind = "Argument"
+	lintInputField lintElementKind = "Input field"
+	lintEnumValue  lintElementKind = "Enum value"
+)
+
+// lintElement is a named element of a schema, with the properties lint rules
+// look at. Only the properties that apply to its kind are set.
+type lintElement struct {
+	kind              lintElementKind
+	name              string
+	coordinate        string
+	description       string
+	typeKind          string   // Kind of a type
+	typeRef           *TypeRef // Type of a field, argument or input field
+	isDeprecated      bool
+	deprecationReason string
+	location          Location
+}
+
+// walkLintElements calls visit for every type, field, argument, input field
+// and enum value of a schema, including the arguments of custom directives.
+func walkLintElements(schema *Schema, visit func(e lintElement)) {
+	visitArgs := func(owner string, args []*InputValueDefinition) {
+		for _, arg := range args {
+			visit(lintElement{
+				kind:              lintArgument,
+				name:              arg.Name,
+				coordinate:        owner + "(" + arg.Name + ":)",
+				description:       arg.Description,
+				typeRef:           &arg.Type,
+				isDeprecated:      arg.IsDeprecated,
+				deprecationReason: arg.DeprecationReason,
+				location:          arg.Location,
+			})
+		}
+	}
+
+	for _, def := range schema.Types {
+		if def.Kind == KindScalar && builtinScalars[def.Name] {
+			continue
+		}
+		visit(lintElement{
+			kind:        lintType,
+			name:        def.Name,
+			coordinate:  def.Name,
+			description: def.Description,
+			typeKind:    def.Kind,
+			location:    def.Location,
+		})
+		for _, field := range def.Fields {
+			coordinate := def.Name + "." + field.Name
+			visit(lintElement{
+				kind:              lintField,
+				name:              field.Name,
+				coordinate:        coordinate,
+				description:       field.Description,
+				typeRef:           &field.Type,
+				isDeprecated:      field.IsDeprecated,
+				deprecationReason: field.DeprecationReason,
+				location:          field.Location,
+			})
+			visitArgs(coordinate, field.Args)
+		}
+		for _, field := range def.InputFields {
+			visit(lintElement{
+				kind:              lintInputField,
+				name:              field.Name,
+				coordinate:        def.Name + "." + field.Name,
+				description:       field.Description,
+				typeRef:           &field.Type,
+				isDeprecated:      field.IsDeprecated,
+				deprecationReason: field.DeprecationReason,
+				location:          field.Location,
+			})
+		}
+		for _, value := range def.EnumValues {
+			visit(lintElement{
+				kind:              lintEnumValue,
+				name:              value.Name,
+				coordinate:        def.Name + "." + value.Name,
+				description:       value.Description,
+				isDeprecated:      value.IsDeprecated,
+				deprecationReason: value.DeprecationReason,
+				location:          value.Location,
+			})
+		}
+	}
+	for _, directive := range schema.Directives {
+		if !specifiedDirectiveNames[directive.Name] {
+			visitArgs("@"+directive.Name, directive.Args)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package geq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdl      string
+		expected []LintProblem
+	}{
+		{
+			name: "Clean schema",
+			sdl: `
+"""Root query"""
+type Query {
+  """Users by role"""
+  users(role: Role, filter: UserFilterInput): [String!]!
+}
+"""Roles"""
+enum Role { ADMIN SUPER_USER }
+"""User filter"""
+input UserFilterInput {
+  """Name prefix"""
+  namePrefix: String @deprecated(reason: "Use search")
+}
+`,
+		},
+		{
+			name: "Naming",
+			sdl: `
+"""Root"""
+type query {
+  """Users"""
+  user_list(Role: role): String
+}
+"""Roles"""
+enum role { admin }
+"""Filter"""
+input Filter {
+  """Name"""
+  Name: String
+}
+`,
+			expected: []LintProblem{
+				{Rule: "type-names-pascal-case", Severity: SeverityError, Coordinate: "query", Message: "Type name 'query' is not PascalCase", Location: Location{Line: 2, Column: 1}},
+				{Rule: "field-names-camel-case", Severity: SeverityError, Coordinate: "query.user_list", Message: "Field name 'user_list' is not camelCase", Location: Location{Line: 4, Column: 3}},
+				{Rule: "field-names-camel-case", Severity: SeverityError, Coordinate: "query.user_list(Role:)", Message: "Argument name 'Role' is not camelCase", Location: Location{Line: 5, Column: 13}},
+				{Rule: "type-names-pascal-case", Severity: SeverityError, Coordinate: "role", Message: "Type name 'role' is not PascalCase", Location: Location{Line: 7, Column: 1}},
+				{Rule: "enum-values-upper-case", Severity: SeverityError, Coordinate: "role.admin", Message: "Enum value 'admin' is not UPPER_CASE", Location: Location{Line: 8, Column: 13}},
+				{Rule: "input-type-suffix", Severity: SeverityError, Coordinate: "Filter", Message: "Input type name 'Filter' does not end in 'Input'", Location: Location{Line: 9, Column: 1}},
+				{Rule: "field-names-camel-case", Severity: SeverityError, Coordinate: "Filter.Name", Message: "Input field name 'Name' is not camelCase", Location: Location{Line: 11, Column: 3}},
+			},
+		},
+		{
+			name: "Descriptions, lists and deprecations",
+			sdl: `type Query {
+  """Friends"""
+  friends(ids: [ID]): [[String!]]! @deprecated
+}`,
+			expected: []LintProblem{
+				{Rule: "descriptions-required", Severity: SeverityWarning, Coordinate: "Query", Message: "Type 'Query' has no description", Location: Location{Line: 1, Column: 1}},
+				{Rule: "list-items-non-null", Severity: SeverityWarning, Coordinate: "Query.friends", Message: "Field 'Query.friends' has type '[[String!]]!', whose list items can be null", Location: Location{Line: 2, Column: 3}},
+				{Rule: "deprecation-reason-required", Severity: SeverityError, Coordinate: "Query.friends", Message: "Field 'Query.friends' is deprecated without a reason", Location: Location{Line: 2, Column: 3}},
+				{Rule: "list-items-non-null", Severity: SeverityWarning, Coordinate: "Query.friends(ids:)", Message: "Argument 'Query.friends(ids:)' has type '[ID]', whose list items can be null", Location: Location{Line: 3, Column: 11}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := Lint(mustParseSDL(t, tt.sdl), DefaultLintConfig())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, problems)
+		})
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	schema := mustParseSDL(t, "type Query { friends: [String] }")
+
+	config, err := ReadLintConfig(strings.NewReader(`{"rules": {"descriptions-required": "off", "list-items-non-null": "error"}}`))
+	require.NoError(t, err)
+	problems, err := Lint(schema, config)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "list-items-non-null", problems[0].Rule)
+	assert.Equal(t, SeverityError, problems[0].Severity)
+
+	// Rules that are not configured keep their default severity
+	problems, err = Lint(schema, LintConfig{})
+	require.NoError(t, err)
+	assert.Len(t, problems, 3)
+
+	for _, invalid := range []string{
+		`{"rules": {"no-such-rule": "error"}}`,
+		`{"rules": {"descriptions-required": "fatal"}}`,
+		`{"rule": {}}`,
+		`{`,
+	} {
+		_, err := ReadLintConfig(strings.NewReader(invalid))
+		assert.Error(t, err, invalid)
+	}
+	_, err = Lint(schema, LintConfig{Rules: map[string]Severity{"no-such-rule": SeverityOff}})
+	assert.Error(t, err)
+}
+
+func TestLintIntrospection(t *testing.T) {
+	// Built-in scalars and directives are not linted, and an introspected
+	// schema has no locations
+	schema := NewSchema(SchemaToIntrospection(mustParseSDL(t, `"""Root"""
+type Query {
+  """A"""
+  a: Int @deprecated
+}`)))
+	problems, err := Lint(schema, DefaultLintConfig())
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "deprecation-reason-required", problems[0].Rule)
+	assert.Equal(t, Location{}, problems[0].Location)
+}
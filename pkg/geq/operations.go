@@ -0,0 +1,242 @@
+package geq
+
+import (
+	"fmt"
+	"io"
+)
+
+// Document is a GraphQL executable document: the operations and fragments a
+// client sends to a server, as parsed by ParseOperations.
+type Document struct {
+	// Source names where the document was read from, e.g. a file name.
+	// ValidateOperations copies it into the problems found in the document.
+	Source string
+	// Operations are the operations of the document, in definition order.
+	Operations []*OperationDefinition
+	// Fragments are the fragment definitions of the document, in definition
+	// order.
+	Fragments []*FragmentDefinition
+}
+
+// OperationDefinition is a query, mutation or subscription.
+type OperationDefinition struct {
+	// Operation is "query", "mutation" or "subscription".
+	Operation string
+	// Name is empty for an anonymous operation.
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	AppliedDirectives   []*AppliedDirective
+	SelectionSet        []Selection
+	Location            Location
+}
+
+// VariableDefinition is a variable declared by an operation, e.g.
+// $first: Int = 10.
+type VariableDefinition struct {
+	// Name is the name of the variable, without the "$".
+	Name string
+	Type TypeRef
+	// DefaultValue is the default value literal, or nil if there is none.
+	DefaultValue      *Value
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// FragmentDefinition is a named fragment, e.g. fragment UserFields on User { ... }.
+type FragmentDefinition struct {
+	Name              string
+	TypeCondition     string
+	AppliedDirectives []*AppliedDirective
+	SelectionSet      []Selection
+	Location          Location
+}
+
+// Selection is an element of a selection set: a *FieldSelection, a
+// *FragmentSpread or an *InlineFragment.
+type Selection interface {
+	selectionLocation() Location
+}
+
+// FieldSelection is a field selected in a selection set.
+type FieldSelection struct {
+	// Alias is empty if the field is not aliased.
+	Alias             string
+	Name              string
+	Arguments         []*Argument
+	AppliedDirectives []*AppliedDirective
+	// SelectionSet holds the subfields selected on a field of a composite
+	// type; it is nil for a leaf field.
+	SelectionSet []Selection
+	Location     Location
+}
+
+// FragmentSpread is a named fragment spread into a selection set: ...UserFields.
+type FragmentSpread struct {
+	Name              string
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// InlineFragment is a selection set spread in place, optionally with a type
+// condition: ... on User { ... }.
+type InlineFragment struct {
+	// TypeCondition is empty if the fragment has none.
+	TypeCondition     string
+	AppliedDirectives []*AppliedDirective
+	SelectionSet      []Selection
+	Location          Location
+}
+
+func (s *FieldSelection) selectionLocation() Location { return s.Location }
+func (s *FragmentSpread) selectionLocation() Location { return s.Location }
+func (s *InlineFragment) selectionLocation() Location { return s.Location }
+
+// ParseOperations parses a GraphQL executable document: operations, including
+// the query shorthand { ... }, and fragment definitions. Type system
+// definitions are not allowed. Errors are *ParseError values carrying the line
+// and column of the problem.
+func ParseOperations(r io.Reader) (*Document, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading document: %w", err)
+	}
+
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var doc *Document
+	if err := p.run(func() { doc = p.parseExecutableDocument() }); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// parseExecutableDocument parses every definition up to the end of file.
+func (p *parser) parseExecutableDocument() *Document {
+	doc := &Document{}
+	if p.peek().kind == tokenEOF {
+		p.unexpected("an operation or fragment definition")
+	}
+	for p.peek().kind != tokenEOF {
+		tok := p.peek()
+		switch {
+		case p.peekPunct("{"):
+			doc.Operations = append(doc.Operations, &OperationDefinition{
+				Operation:    "query",
+				SelectionSet: p.parseSelectionSet(),
+				Location:     tok.loc,
+			})
+		case p.peekKeyword("query"), p.peekKeyword("mutation"), p.peekKeyword("subscription"):
+			doc.Operations = append(doc.Operations, p.parseOperationDefinition())
+		case p.peekKeyword("fragment"):
+			doc.Fragments = append(doc.Fragments, p.parseFragmentDefinition())
+		case p.peekDescription(), tok.kind == tokenName:
+			p.fail(tok.loc, "type system definitions are not allowed in an executable document")
+		default:
+			p.unexpected("an operation or fragment definition")
+		}
+	}
+	return doc
+}
+
+// parseOperationDefinition parses "query Name($var: Type) @dir { ... }".
+func (p *parser) parseOperationDefinition() *OperationDefinition {
+	keyword := p.advance()
+	op := &OperationDefinition{Operation: keyword.value, Location: keyword.loc}
+	if p.peek().kind == tokenName {
+		op.Name = p.advance().value
+	}
+	op.VariableDefinitions = p.parseVariableDefinitions()
+	op.AppliedDirectives = p.parseDirectives(false)
+	op.SelectionSet = p.parseSelectionSet()
+	return op
+}
+
+// parseVariableDefinitions parses an optional "($var: Type = default ...)" list.
+func (p *parser) parseVariableDefinitions() []*VariableDefinition {
+	if !p.skipPunct("(") {
+		return nil
+	}
+	var defs []*VariableDefinition
+	for !p.skipPunct(")") {
+		dollar := p.expectPunct("$")
+		def := &VariableDefinition{Name: p.expectName().value, Location: dollar.loc}
+		p.expectPunct(":")
+		def.Type = p.parseType()
+		if p.skipPunct("=") {
+			def.DefaultValue = p.parseValue(true)
+		}
+		def.AppliedDirectives = p.parseDirectives(true)
+		defs = append(defs, def)
+	}
+	if len(defs) == 0 {
+		p.fail(p.tokens[p.pos-1].loc, "variable list must not be empty")
+	}
+	return defs
+}
+
+// parseFragmentDefinition parses "fragment Name on Type @dir { ... }".
+func (p *parser) parseFragmentDefinition() *FragmentDefinition {
+	keyword := p.expectKeyword("fragment")
+	name := p.expectName()
+	if name.value == "on" {
+		p.fail(name.loc, `"on" cannot be used as a fragment name`)
+	}
+	p.expectKeyword("on")
+	return &FragmentDefinition{
+		Name:              name.value,
+		TypeCondition:     p.expectName().value,
+		AppliedDirectives: p.parseDirectives(false),
+		SelectionSet:      p.parseSelectionSet(),
+		Location:          keyword.loc,
+	}
+}
+
+// parseSelectionSet parses a non-empty "{ ... }" selection set.
+func (p *parser) parseSelectionSet() []Selection {
+	p.expectPunct("{")
+	var selections []Selection
+	for !p.skipPunct("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail(p.tokens[p.pos-1].loc, "selection set must not be empty")
+	}
+	return selections
+}
+
+// parseSelection parses a field, a fragment spread or an inline fragment.
+func (p *parser) parseSelection() Selection {
+	if spread := p.peek(); p.skipPunct("...") {
+		if p.peek().kind == tokenName && p.peek().value != "on" {
+			return &FragmentSpread{
+				Name:              p.advance().value,
+				AppliedDirectives: p.parseDirectives(false),
+				Location:          spread.loc,
+			}
+		}
+		fragment := &InlineFragment{Location: spread.loc}
+		if p.skipKeyword("on") {
+			fragment.TypeCondition = p.expectName().value
+		}
+		fragment.AppliedDirectives = p.parseDirectives(false)
+		fragment.SelectionSet = p.parseSelectionSet()
+		return fragment
+	}
+
+	name := p.expectName()
+	field := &FieldSelection{Name: name.value, Location: name.loc}
+	if p.skipPunct(":") {
+		field.Alias = field.Name
+		field.Name = p.expectName().value
+	}
+	field.Arguments = p.parseArguments(false)
+	field.AppliedDirectives = p.parseDirectives(false)
+	if p.peekPunct("{") {
+		field.SelectionSet = p.parseSelectionSet()
+	}
+	return field
+}
@@ -0,0 +1,127 @@
+package geq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOperations(t *testing.T) {
+	src := `
+query GetUser($id: ID!, $size: Int = 64 @deprecated) @cached {
+  me: user(id: $id) {
+    ...UserFields @include(if: true)
+    ... on Admin { role }
+    ... @skip(if: false) { name }
+  }
+}
+
+{ __typename }
+
+fragment UserFields on User {
+  avatar(size: $size, filter: {tags: ["a", "b"]})
+}
+`
+	doc, err := ParseOperations(strings.NewReader(src))
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 2)
+	require.Len(t, doc.Fragments, 1)
+
+	op := doc.Operations[0]
+	assert.Equal(t, "query", op.Operation)
+	assert.Equal(t, "GetUser", op.Name)
+	assert.Equal(t, Location{Line: 2, Column: 1}, op.Location)
+	require.Len(t, op.VariableDefinitions, 2)
+	assert.Equal(t, "id", op.VariableDefinitions[0].Name)
+	assert.Equal(t, "ID!", TypeRefToString(op.VariableDefinitions[0].Type))
+	assert.Equal(t, Location{Line: 2, Column: 15}, op.VariableDefinitions[0].Location)
+	assert.Equal(t, "64", op.VariableDefinitions[1].DefaultValue.String())
+	require.Len(t, op.VariableDefinitions[1].AppliedDirectives, 1)
+	require.Len(t, op.AppliedDirectives, 1)
+	assert.Equal(t, "cached", op.AppliedDirectives[0].Name)
+
+	require.Len(t, op.SelectionSet, 1)
+	user, ok := op.SelectionSet[0].(*FieldSelection)
+	require.True(t, ok)
+	assert.Equal(t, "me", user.Alias)
+	assert.Equal(t, "user", user.Name)
+	assert.Equal(t, Location{Line: 3, Column: 3}, user.Location)
+	require.Len(t, user.Arguments, 1)
+	assert.Equal(t, "$id", user.Arguments[0].Value.String())
+
+	require.Len(t, user.SelectionSet, 3)
+	spread, ok := user.SelectionSet[0].(*FragmentSpread)
+	require.True(t, ok)
+	assert.Equal(t, "UserFields", spread.Name)
+	assert.Equal(t, Location{Line: 4, Column: 5}, spread.Location)
+	require.Len(t, spread.AppliedDirectives, 1)
+	inline, ok := user.SelectionSet[1].(*InlineFragment)
+	require.True(t, ok)
+	assert.Equal(t, "Admin", inline.TypeCondition)
+	inline, ok = user.SelectionSet[2].(*InlineFragment)
+	require.True(t, ok)
+	assert.Empty(t, inline.TypeCondition)
+	assert.Len(t, inline.AppliedDirectives, 1)
+
+	shorthand := doc.Operations[1]
+	assert.Equal(t, "query", shorthand.Operation)
+	assert.Empty(t, shorthand.Name)
+	assert.Equal(t, Location{Line: 10, Column: 1}, shorthand.Location)
+
+	fragment := doc.Fragments[0]
+	assert.Equal(t, "UserFields", fragment.Name)
+	assert.Equal(t, "User", fragment.TypeCondition)
+	assert.Equal(t, Location{Line: 12, Column: 1}, fragment.Location)
+	avatar := fragment.SelectionSet[0].(*FieldSelection)
+	assert.Nil(t, avatar.SelectionSet)
+	assert.Equal(t, `{tags: ["a", "b"]}`, avatar.Arguments[1].Value.String())
+}
+
+func TestParseOperationsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "Empty document",
+			src:      "  ",
+			expected: "line 1, column 3: expected an operation or fragment definition, found end of file",
+		},
+		{
+			name:     "Type system definition",
+			src:      "query { a }\ntype Query { a: Int }",
+			expected: "line 2, column 1: type system definitions are not allowed in an executable document",
+		},
+		{
+			name:     "Empty selection set",
+			src:      "query {\n}",
+			expected: "line 2, column 1: selection set must not be empty",
+		},
+		{
+			name:     "Fragment named on",
+			src:      "fragment on on User { a }",
+			expected: `line 1, column 10: "on" cannot be used as a fragment name`,
+		},
+		{
+			name:     "Variable in default value",
+			src:      "query ($a: Int = $b) { a }",
+			expected: "line 1, column 18: unexpected variable in constant value",
+		},
+		{
+			name:     "Missing selection set",
+			src:      "query Q",
+			expected: `line 1, column 8: expected "{", found end of file`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOperations(strings.NewReader(tt.src))
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
@@ -0,0 +1,790 @@
+package geq
+
+import (
+	"fmt"
+	"io"
+)
+
+// ParseSDL parses a GraphQL type system document (SDL) into a Schema.
+//
+// It accepts the full type system grammar of the GraphQL specification:
+// descriptions, block strings, directive definitions, applied directives and
+// type and schema extensions, which are merged into the types they extend.
+// Errors are *ParseError values carrying the line and column of the problem.
+func ParseSDL(r io.Reader) (*Schema, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading SDL: %w", err)
+	}
+
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var doc *typeSystemDocument
+	if err := p.run(func() { doc = p.parseTypeSystemDocument() }); err != nil {
+		return nil, err
+	}
+	return buildSchema(doc)
+}
+
+// parseValueLiteral parses a single constant value literal, such as the
+// defaultValue of an introspection result.
+func parseValueLiteral(src string) (*Value, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var value *Value
+	err = p.run(func() {
+		value = p.parseValue(true)
+		if p.peek().kind != tokenEOF {
+			p.unexpected("end of value")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parser is a recursive descent parser over a token stream. Parse methods
+// report errors by calling fail, which unwinds to run.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseFailure carries a parse error from fail to run.
+type parseFailure struct {
+	err error
+}
+
+// run calls parse and converts a failure raised during parsing into an error.
+func (p *parser) run(parse func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure, ok := r.(parseFailure)
+			if !ok {
+				panic(r)
+			}
+			err = failure.err
+		}
+	}()
+	parse()
+	return nil
+}
+
+// fail aborts parsing with an error at loc.
+func (p *parser) fail(loc Location, format string, args ...any) {
+	panic(parseFailure{err: &ParseError{Message: fmt.Sprintf(format, args...), Location: loc}})
+}
+
+// unexpected aborts parsing at the current token.
+func (p *parser) unexpected(expected string) {
+	tok := p.peek()
+	p.fail(tok.loc, "expected %s, found %s", expected, tok.describe())
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// lookahead returns the token n positions after the current one.
+func (p *parser) lookahead(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// peekPunct reports whether the current token is the given punctuator.
+func (p *parser) peekPunct(value string) bool {
+	tok := p.peek()
+	return tok.kind == tokenPunctuator && tok.value == value
+}
+
+// peekKeyword reports whether the current token is the given name.
+func (p *parser) peekKeyword(value string) bool {
+	tok := p.peek()
+	return tok.kind == tokenName && tok.value == value
+}
+
+// skipPunct consumes the given punctuator if it is the current token.
+func (p *parser) skipPunct(value string) bool {
+	if p.peekPunct(value) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+// skipKeyword consumes the given name if it is the current token.
+func (p *parser) skipKeyword(value string) bool {
+	if p.peekKeyword(value) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(value string) token {
+	if !p.peekPunct(value) {
+		p.unexpected(fmt.Sprintf("%q", value))
+	}
+	return p.advance()
+}
+
+func (p *parser) expectKeyword(value string) token {
+	if !p.peekKeyword(value) {
+		p.unexpected(fmt.Sprintf("%q", value))
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() token {
+	if p.peek().kind != tokenName {
+		p.unexpected("name")
+	}
+	return p.advance()
+}
+
+// peekDescription reports whether the current token is a string, which
+// starts a description.
+func (p *parser) peekDescription() bool {
+	kind := p.peek().kind
+	return kind == tokenString || kind == tokenBlockString
+}
+
+// parseDescription parses an optional description.
+func (p *parser) parseDescription() string {
+	if p.peekDescription() {
+		return p.advance().value
+	}
+	return ""
+}
+
+// parseType parses a type reference: NamedType, [Type] or Type!.
+func (p *parser) parseType() TypeRef {
+	var typeRef TypeRef
+	if p.skipPunct("[") {
+		ofType := p.parseType()
+		p.expectPunct("]")
+		typeRef = TypeRef{Kind: KindList, OfType: &ofType}
+	} else {
+		typeRef = TypeRef{Name: p.expectName().value}
+	}
+	if p.skipPunct("!") {
+		ofType := typeRef
+		typeRef = TypeRef{Kind: KindNonNull, OfType: &ofType}
+	}
+	return typeRef
+}
+
+// parseValue parses a value literal. Variables are only allowed if isConst is false.
+func (p *parser) parseValue(isConst bool) *Value {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "[":
+			p.advance()
+			v := &Value{Kind: ValueList, List: []*Value{}, Location: tok.loc}
+			for !p.skipPunct("]") {
+				v.List = append(v.List, p.parseValue(isConst))
+			}
+			return v
+		case "{":
+			p.advance()
+			v := &Value{Kind: ValueObject, Fields: []*ObjectField{}, Location: tok.loc}
+			for !p.skipPunct("}") {
+				name := p.expectName()
+				p.expectPunct(":")
+				v.Fields = append(v.Fields, &ObjectField{Name: name.value, Value: p.parseValue(isConst), Location: name.loc})
+			}
+			return v
+		case "$":
+			if !isConst {
+				p.advance()
+				name := p.expectName()
+				return &Value{Kind: ValueVariable, Raw: name.value, Location: tok.loc}
+			}
+			p.fail(tok.loc, "unexpected variable in constant value")
+		}
+	case tokenInt:
+		p.advance()
+		return &Value{Kind: ValueInt, Raw: tok.value, Location: tok.loc}
+	case tokenFloat:
+		p.advance()
+		return &Value{Kind: ValueFloat, Raw: tok.value, Location: tok.loc}
+	case tokenString, tokenBlockString:
+		p.advance()
+		return &Value{Kind: ValueString, Raw: tok.value, Block: tok.kind == tokenBlockString, Location: tok.loc}
+	case tokenName:
+		p.advance()
+		switch tok.value {
+		case "true", "false":
+			return &Value{Kind: ValueBoolean, Raw: tok.value, Location: tok.loc}
+		case "null":
+			return &Value{Kind: ValueNull, Raw: tok.value, Location: tok.loc}
+		}
+		return &Value{Kind: ValueEnum, Raw: tok.value, Location: tok.loc}
+	}
+	p.unexpected("value")
+	return nil
+}
+
+// parseArguments parses an optional argument list: (name: value, ...).
+func (p *parser) parseArguments(isConst bool) []*Argument {
+	if !p.skipPunct("(") {
+		return nil
+	}
+	var args []*Argument
+	for {
+		name := p.expectName()
+		p.expectPunct(":")
+		args = append(args, &Argument{Name: name.value, Value: p.parseValue(isConst), Location: name.loc})
+		if p.skipPunct(")") {
+			return args
+		}
+	}
+}
+
+// parseDirectives parses zero or more applied directives.
+func (p *parser) parseDirectives(isConst bool) []*AppliedDirective {
+	var directives []*AppliedDirective
+	for p.peekPunct("@") {
+		at := p.advance()
+		name := p.expectName()
+		directives = append(directives, &AppliedDirective{
+			Name:      name.value,
+			Arguments: p.parseArguments(isConst),
+			Location:  at.loc,
+		})
+	}
+	return directives
+}
+
+// typeSystemDocument holds the definitions of a type system document before
+// extensions are merged.
+type typeSystemDocument struct {
+	schemas    []*schemaDefinition
+	types      []*TypeDefinition
+	extensions []*TypeDefinition
+	directives []*DirectiveDefinition
+}
+
+// schemaDefinition is a schema definition or schema extension.
+type schemaDefinition struct {
+	description       string
+	operationTypes    []operationTypeDefinition
+	appliedDirectives []*AppliedDirective
+	isExtension       bool
+	location          Location
+}
+
+// operationTypeDefinition maps an operation to its root type, e.g. query: Query.
+type operationTypeDefinition struct {
+	operation string
+	typeName  string
+	location  Location
+}
+
+// parseTypeSystemDocument parses every definition up to the end of file.
+func (p *parser) parseTypeSystemDocument() *typeSystemDocument {
+	doc := &typeSystemDocument{}
+	for p.peek().kind != tokenEOF {
+		p.parseTypeSystemDefinition(doc)
+	}
+	return doc
+}
+
+// parseTypeSystemDefinition parses a single definition or extension into doc.
+func (p *parser) parseTypeSystemDefinition(doc *typeSystemDocument) {
+	start := p.peek()
+	description := p.parseDescription()
+
+	keyword := p.peek()
+	if keyword.kind == tokenPunctuator && keyword.value == "{" {
+		p.fail(keyword.loc, "executable definitions are not allowed in a type system document")
+	}
+	if keyword.kind != tokenName {
+		p.unexpected("definition")
+	}
+
+	switch keyword.value {
+	case "schema":
+		doc.schemas = append(doc.schemas, p.parseSchemaDefinition(description, start.loc, false))
+	case "scalar", "type", "interface", "union", "enum", "input":
+		doc.types = append(doc.types, p.parseTypeDefinition(description, start.loc, false))
+	case "directive":
+		doc.directives = append(doc.directives, p.parseDirectiveDefinition(description, start.loc))
+	case "extend":
+		if description != "" {
+			p.fail(start.loc, "extensions cannot have a description")
+		}
+		p.advance()
+		if p.peekKeyword("schema") {
+			doc.schemas = append(doc.schemas, p.parseSchemaDefinition("", start.loc, true))
+		} else {
+			doc.extensions = append(doc.extensions, p.parseTypeDefinition("", start.loc, true))
+		}
+	case "query", "mutation", "subscription", "fragment":
+		p.fail(keyword.loc, "executable definitions are not allowed in a type system document")
+	default:
+		p.fail(keyword.loc, "unexpected name %q, expected a type system definition", keyword.value)
+	}
+}
+
+// parseSchemaDefinition parses "schema @dir { query: Query ... }" after an
+// optional description or "extend".
+func (p *parser) parseSchemaDefinition(description string, loc Location, isExtension bool) *schemaDefinition {
+	p.expectKeyword("schema")
+	def := &schemaDefinition{
+		description:       description,
+		appliedDirectives: p.parseDirectives(true),
+		isExtension:       isExtension,
+		location:          loc,
+	}
+
+	if !p.skipPunct("{") {
+		if !isExtension || len(def.appliedDirectives) == 0 {
+			p.unexpected(`"{"`)
+		}
+		return def
+	}
+	for !p.skipPunct("}") {
+		op := p.expectName()
+		switch op.value {
+		case "query", "mutation", "subscription":
+		default:
+			p.fail(op.loc, "unexpected operation type %q, expected query, mutation or subscription", op.value)
+		}
+		p.expectPunct(":")
+		def.operationTypes = append(def.operationTypes, operationTypeDefinition{
+			operation: op.value,
+			typeName:  p.expectName().value,
+			location:  op.loc,
+		})
+	}
+	return def
+}
+
+// parseTypeDefinition parses a named type definition or extension of any kind.
+func (p *parser) parseTypeDefinition(description string, loc Location, isExtension bool) *TypeDefinition {
+	keyword := p.expectName()
+	def := &TypeDefinition{
+		Name:        p.expectName().value,
+		Description: description,
+		Location:    loc,
+	}
+
+	switch keyword.value {
+	case "scalar":
+		def.Kind = KindScalar
+		def.AppliedDirectives = p.parseDirectives(true)
+	case "type", "interface":
+		def.Kind = KindObject
+		if keyword.value == "interface" {
+			def.Kind = KindInterface
+		}
+		def.Interfaces = p.parseImplementsInterfaces()
+		def.AppliedDirectives = p.parseDirectives(true)
+		def.Fields = p.parseFieldsDefinition()
+	case "union":
+		def.Kind = KindUnion
+		def.AppliedDirectives = p.parseDirectives(true)
+		if p.skipPunct("=") {
+			p.skipPunct("|")
+			for {
+				def.PossibleTypes = append(def.PossibleTypes, p.expectName().value)
+				if !p.skipPunct("|") {
+					break
+				}
+			}
+		}
+	case "enum":
+		def.Kind = KindEnum
+		def.AppliedDirectives = p.parseDirectives(true)
+		def.EnumValues = p.parseEnumValuesDefinition()
+	case "input":
+		def.Kind = KindInputObject
+		def.AppliedDirectives = p.parseDirectives(true)
+		if p.skipPunct("{") {
+			def.InputFields = []*InputValueDefinition{}
+			for !p.skipPunct("}") {
+				def.InputFields = append(def.InputFields, p.parseInputValueDefinition())
+			}
+		}
+	default:
+		p.fail(keyword.loc, "unexpected name %q, expected a type definition", keyword.value)
+	}
+
+	if isExtension && len(def.Interfaces) == 0 && len(def.AppliedDirectives) == 0 && def.Fields == nil &&
+		def.PossibleTypes == nil && def.EnumValues == nil && def.InputFields == nil {
+		p.unexpected("extension content")
+	}
+
+	for _, directive := range def.AppliedDirectives {
+		switch directive.Name {
+		case "specifiedBy":
+			if url := directive.Argument("url"); url != nil && url.Value.Kind == ValueString {
+				def.SpecifiedByURL = url.Value.Raw
+			}
+		case "oneOf":
+			def.IsOneOf = true
+		}
+	}
+	return def
+}
+
+// parseImplementsInterfaces parses an optional "implements A & B" clause.
+func (p *parser) parseImplementsInterfaces() []string {
+	if !p.skipKeyword("implements") {
+		return nil
+	}
+	p.skipPunct("&")
+	var interfaces []string
+	for {
+		interfaces = append(interfaces, p.expectName().value)
+		if !p.skipPunct("&") {
+			return interfaces
+		}
+	}
+}
+
+// parseFieldsDefinition parses an optional "{ field: Type ... }" block.
+func (p *parser) parseFieldsDefinition() []*FieldDefinition {
+	if !p.skipPunct("{") {
+		return nil
+	}
+	fields := []*FieldDefinition{}
+	for !p.skipPunct("}") {
+		start := p.peek()
+		field := &FieldDefinition{Description: p.parseDescription(), Location: start.loc}
+		field.Name = p.expectName().value
+		field.Args = p.parseArgumentsDefinition()
+		p.expectPunct(":")
+		field.Type = p.parseType()
+		field.AppliedDirectives = p.parseDirectives(true)
+		field.IsDeprecated, field.DeprecationReason = deprecation(field.AppliedDirectives)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// parseArgumentsDefinition parses an optional "(arg: Type = default ...)" list.
+func (p *parser) parseArgumentsDefinition() []*InputValueDefinition {
+	if !p.skipPunct("(") {
+		return nil
+	}
+	var args []*InputValueDefinition
+	for !p.skipPunct(")") {
+		args = append(args, p.parseInputValueDefinition())
+	}
+	if len(args) == 0 {
+		p.fail(p.tokens[p.pos-1].loc, "argument list must not be empty")
+	}
+	return args
+}
+
+// parseInputValueDefinition parses an argument or input field definition.
+func (p *parser) parseInputValueDefinition() *InputValueDefinition {
+	start := p.peek()
+	input := &InputValueDefinition{Description: p.parseDescription(), Location: start.loc}
+	input.Name = p.expectName().value
+	p.expectPunct(":")
+	input.Type = p.parseType()
+	if p.skipPunct("=") {
+		input.DefaultValue = p.parseValue(true)
+	}
+	input.AppliedDirectives = p.parseDirectives(true)
+	input.IsDeprecated, input.DeprecationReason = deprecation(input.AppliedDirectives)
+	return input
+}
+
+// parseEnumValuesDefinition parses an optional "{ VALUE ... }" block.
+func (p *parser) parseEnumValuesDefinition() []*EnumValueDefinition {
+	if !p.skipPunct("{") {
+		return nil
+	}
+	values := []*EnumValueDefinition{}
+	for !p.skipPunct("}") {
+		start := p.peek()
+		value := &EnumValueDefinition{Description: p.parseDescription(), Location: start.loc}
+		name := p.expectName()
+		switch name.value {
+		case "true", "false", "null":
+			p.fail(name.loc, "%q is reserved and cannot be used as an enum value", name.value)
+		}
+		value.Name = name.value
+		value.AppliedDirectives = p.parseDirectives(true)
+		value.IsDeprecated, value.DeprecationReason = deprecation(value.AppliedDirectives)
+		values = append(values, value)
+	}
+	return values
+}
+
+// directiveLocations are the valid locations of a directive definition.
+var directiveLocations = map[string]bool{
+	// Executable directive locations
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true,
+	"FRAGMENT_DEFINITION": true, "FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true,
+	"VARIABLE_DEFINITION": true,
+	// Type system directive locations
+	"SCHEMA": true, "SCALAR": true, "OBJECT": true, "FIELD_DEFINITION": true,
+	"ARGUMENT_DEFINITION": true, "INTERFACE": true, "UNION": true, "ENUM": true,
+	"ENUM_VALUE": true, "INPUT_OBJECT": true, "INPUT_FIELD_DEFINITION": true,
+}
+
+// parseDirectiveDefinition parses "directive @name(args) repeatable on LOCATIONS".
+func (p *parser) parseDirectiveDefinition(description string, loc Location) *DirectiveDefinition {
+	p.expectKeyword("directive")
+	p.expectPunct("@")
+	def := &DirectiveDefinition{
+		Name:        p.expectName().value,
+		Description: description,
+		Location:    loc,
+	}
+	def.Args = p.parseArgumentsDefinition()
+	def.IsRepeatable = p.skipKeyword("repeatable")
+	p.expectKeyword("on")
+	p.skipPunct("|")
+	for {
+		location := p.expectName()
+		if !directiveLocations[location.value] {
+			p.fail(location.loc, "unexpected directive location %q", location.value)
+		}
+		def.Locations = append(def.Locations, location.value)
+		if !p.skipPunct("|") {
+			return def
+		}
+	}
+}
+
+// deprecation reports whether directives include @deprecated, and its reason.
+func deprecation(directives []*AppliedDirective) (bool, string) {
+	for _, directive := range directives {
+		if directive.Name != "deprecated" {
+			continue
+		}
+		if reason := directive.Argument("reason"); reason != nil && reason.Value.Kind == ValueString {
+			return true, reason.Value.Raw
+		}
+		return true, DefaultDeprecationReason
+	}
+	return false, ""
+}
+
+// buildSchema merges the extensions of a parsed document into its type and
+// schema definitions and resolves the kinds of type references.
+func buildSchema(doc *typeSystemDocument) (*Schema, error) {
+	schema := &Schema{}
+	types := make(map[string]*TypeDefinition)
+
+	for _, def := range doc.types {
+		if types[def.Name] != nil {
+			return nil, &ParseError{Message: fmt.Sprintf("type %q is already defined", def.Name), Location: def.Location}
+		}
+		types[def.Name] = def
+		schema.Types = append(schema.Types, def)
+	}
+
+	for _, ext := range doc.extensions {
+		def := types[ext.Name]
+		if def == nil {
+			return nil, &ParseError{Message: fmt.Sprintf("cannot extend undefined type %q", ext.Name), Location: ext.Location}
+		}
+		if def.Kind != ext.Kind {
+			return nil, &ParseError{Message: fmt.Sprintf("cannot extend %s %q with a %s extension", kindName(def.Kind), ext.Name, kindName(ext.Kind)), Location: ext.Location}
+		}
+		if err := mergeTypeExtension(def, ext); err != nil {
+			return nil, err
+		}
+	}
+
+	directives := make(map[string]bool)
+	for _, def := range doc.directives {
+		if directives[def.Name] {
+			return nil, &ParseError{Message: fmt.Sprintf("directive @%s is already defined", def.Name), Location: def.Location}
+		}
+		directives[def.Name] = true
+		schema.Directives = append(schema.Directives, def)
+	}
+
+	hasSchemaDefinition := false
+	for _, def := range doc.schemas {
+		if !def.isExtension {
+			if hasSchemaDefinition {
+				return nil, &ParseError{Message: "schema is already defined", Location: def.location}
+			}
+			hasSchemaDefinition = true
+			schema.Description = def.description
+		}
+		schema.AppliedDirectives = append(schema.AppliedDirectives, def.appliedDirectives...)
+		for _, op := range def.operationTypes {
+			root := schema.rootTypeName(op.operation)
+			if *root != "" {
+				return nil, &ParseError{Message: fmt.Sprintf("%s root type is already defined", op.operation), Location: op.location}
+			}
+			*root = op.typeName
+		}
+	}
+
+	// Without a schema definition, the root types are found by their default names.
+	if !hasSchemaDefinition {
+		for _, op := range []string{"query", "mutation", "subscription"} {
+			root := schema.rootTypeName(op)
+			name := defaultRootTypeName(op)
+			if *root == "" && types[name] != nil && types[name].Kind == KindObject {
+				*root = name
+			}
+		}
+	}
+
+	resolveTypeRefKinds(schema, types)
+	schema.Reindex()
+	return schema, nil
+}
+
+// rootTypeName returns a pointer to the root type name for an operation.
+func (s *Schema) rootTypeName(operation string) *string {
+	switch operation {
+	case "mutation":
+		return &s.MutationType
+	case "subscription":
+		return &s.SubscriptionType
+	}
+	return &s.QueryType
+}
+
+// defaultRootTypeName returns the conventional root type name for an
+// operation, e.g. "Query" for "query".
+func defaultRootTypeName(operation string) string {
+	switch operation {
+	case "mutation":
+		return "Mutation"
+	case "subscription":
+		return "Subscription"
+	}
+	return "Query"
+}
+
+// mergeTypeExtension adds the contents of ext to def.
+func mergeTypeExtension(def, ext *TypeDefinition) error {
+	duplicate := func(what, name string, loc Location) error {
+		return &ParseError{Message: fmt.Sprintf("%s %q is already defined on %q", what, name, def.Name), Location: loc}
+	}
+
+	for _, name := range ext.Interfaces {
+		for _, existing := range def.Interfaces {
+			if existing == name {
+				return duplicate("interface", name, ext.Location)
+			}
+		}
+		def.Interfaces = append(def.Interfaces, name)
+	}
+	for _, field := range ext.Fields {
+		for _, existing := range def.Fields {
+			if existing.Name == field.Name {
+				return duplicate("field", field.Name, field.Location)
+			}
+		}
+		def.Fields = append(def.Fields, field)
+	}
+	for _, name := range ext.PossibleTypes {
+		for _, existing := range def.PossibleTypes {
+			if existing == name {
+				return duplicate("union member", name, ext.Location)
+			}
+		}
+		def.PossibleTypes = append(def.PossibleTypes, name)
+	}
+	for _, value := range ext.EnumValues {
+		for _, existing := range def.EnumValues {
+			if existing.Name == value.Name {
+				return duplicate("enum value", value.Name, value.Location)
+			}
+		}
+		def.EnumValues = append(def.EnumValues, value)
+	}
+	for _, field := range ext.InputFields {
+		for _, existing := range def.InputFields {
+			if existing.Name == field.Name {
+				return duplicate("input field", field.Name, field.Location)
+			}
+		}
+		def.InputFields = append(def.InputFields, field)
+	}
+
+	def.AppliedDirectives = append(def.AppliedDirectives, ext.AppliedDirectives...)
+	if ext.SpecifiedByURL != "" {
+		def.SpecifiedByURL = ext.SpecifiedByURL
+	}
+	def.IsOneOf = def.IsOneOf || ext.IsOneOf
+	return nil
+}
+
+// resolveTypeRefKinds sets the Kind of every named type reference in the
+// schema. Built-in scalars resolve to SCALAR; unknown names keep an empty Kind.
+func resolveTypeRefKinds(schema *Schema, types map[string]*TypeDefinition) {
+	var resolve func(typeRef *TypeRef)
+	resolve = func(typeRef *TypeRef) {
+		if typeRef.OfType != nil {
+			resolve(typeRef.OfType)
+			return
+		}
+		if def := types[typeRef.Name]; def != nil {
+			typeRef.Kind = def.Kind
+		} else if builtinScalars[typeRef.Name] {
+			typeRef.Kind = KindScalar
+		}
+	}
+	resolveArgs := func(args []*InputValueDefinition) {
+		for _, arg := range args {
+			resolve(&arg.Type)
+		}
+	}
+
+	for _, def := range schema.Types {
+		for _, field := range def.Fields {
+			resolve(&field.Type)
+			resolveArgs(field.Args)
+		}
+		resolveArgs(def.InputFields)
+	}
+	for _, directive := range schema.Directives {
+		resolveArgs(directive.Args)
+	}
+}
+
+// kindName returns the SDL keyword for a type kind, for error messages.
+func kindName(kind string) string {
+	switch kind {
+	case KindScalar:
+		return "scalar"
+	case KindObject:
+		return "type"
+	case KindInterface:
+		return "interface"
+	case KindUnion:
+		return "union"
+	case KindEnum:
+		return "enum"
+	case KindInputObject:
+		return "input"
+	}
+	return kind
+}
@@ -0,0 +1,317 @@
+package geq
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSDLSampleSchema(t *testing.T) {
+	f, err := os.Open(filepath.Join("../../testdata", "sample_schema.graphql"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	schema, err := ParseSDL(f)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Query", schema.QueryType)
+	assert.Equal(t, "Mutation", schema.MutationType)
+	assert.Empty(t, schema.SubscriptionType)
+
+	var query, user *TypeDefinition
+	for _, typ := range schema.Types {
+		switch typ.Name {
+		case "Query":
+			query = typ
+		case "User":
+			user = typ
+		}
+	}
+	require.NotNil(t, query)
+	require.NotNil(t, user)
+
+	assert.Equal(t, KindObject, query.Kind)
+	assert.Equal(t, "The root query object", query.Description)
+	require.Len(t, query.Fields, 1)
+	field := query.Fields[0]
+	assert.Equal(t, "user", field.Name)
+	assert.Equal(t, "Get a user by ID", field.Description)
+	assert.Equal(t, TypeRef{Kind: KindObject, Name: "User"}, field.Type)
+	require.Len(t, field.Args, 1)
+	assert.Equal(t, "The user ID", field.Args[0].Description)
+	assert.Equal(t, "ID!", TypeRefToString(field.Args[0].Type))
+	assert.Equal(t, KindScalar, field.Args[0].Type.OfType.Kind)
+
+	require.Len(t, user.Fields, 2)
+	assert.Equal(t, "name", user.Fields[1].Name)
+}
+
+func TestParseSDL(t *testing.T) {
+	sdl := `
+"""
+The schema
+"""
+schema @link(url: "https://example.com") {
+  query: Root
+}
+
+directive @auth(
+  "Required roles"
+  roles: [Role!] = [ADMIN]
+) repeatable on | FIELD_DEFINITION | OBJECT
+
+scalar DateTime @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+interface Node {
+  id: ID!
+}
+
+interface Entity implements Node {
+  id: ID!
+}
+
+type Root implements & Node & Entity @auth(roles: [USER]) {
+  id: ID!
+  "Search for things"
+  search(query: String = "default", first: Int = 10, filter: Filter = {tags: ["a", "b"], exact: true}): [Result!]!
+  legacy: String @deprecated
+  old: String @deprecated(reason: "Use new")
+}
+
+union Result = | Root | Other
+
+type Other {
+  value: Float
+}
+
+enum Role {
+  ADMIN
+  USER @deprecated(reason: """
+    Use ADMIN
+  """)
+}
+
+input Filter @oneOf {
+  tags: [String]
+  exact: Boolean = false
+  when: DateTime @deprecated
+}
+
+extend type Other {
+  extra: Int
+}
+
+extend enum Role {
+  GUEST
+}
+
+extend union Result = Extra
+
+type Extra {
+  id: ID
+}
+`
+	schema, err := ParseSDL(strings.NewReader(sdl))
+	require.NoError(t, err)
+
+	assert.Equal(t, "The schema", schema.Description)
+	assert.Equal(t, "Root", schema.QueryType)
+	assert.Empty(t, schema.MutationType)
+	require.Len(t, schema.AppliedDirectives, 1)
+	assert.Equal(t, `"https://example.com"`, schema.AppliedDirectives[0].Argument("url").Value.String())
+
+	types := make(map[string]*TypeDefinition)
+	var names []string
+	for _, typ := range schema.Types {
+		types[typ.Name] = typ
+		names = append(names, typ.Name)
+	}
+	assert.Equal(t, []string{"DateTime", "Node", "Entity", "Root", "Result", "Other", "Role", "Filter", "Extra"}, names)
+
+	require.Len(t, schema.Directives, 1)
+	auth := schema.Directives[0]
+	assert.Equal(t, "auth", auth.Name)
+	assert.True(t, auth.IsRepeatable)
+	assert.Equal(t, []string{"FIELD_DEFINITION", "OBJECT"}, auth.Locations)
+	require.Len(t, auth.Args, 1)
+	assert.Equal(t, "Required roles", auth.Args[0].Description)
+	assert.Equal(t, "[Role!]", TypeRefToString(auth.Args[0].Type))
+	assert.Equal(t, KindEnum, auth.Args[0].Type.OfType.OfType.Kind)
+	assert.Equal(t, "[ADMIN]", auth.Args[0].DefaultValue.String())
+
+	assert.Equal(t, "https://tools.ietf.org/html/rfc3339", types["DateTime"].SpecifiedByURL)
+	assert.Equal(t, []string{"Node"}, types["Entity"].Interfaces)
+	assert.Equal(t, KindInterface, types["Entity"].Kind)
+
+	root := types["Root"]
+	assert.Equal(t, []string{"Node", "Entity"}, root.Interfaces)
+	require.Len(t, root.AppliedDirectives, 1)
+	assert.Equal(t, "[USER]", root.AppliedDirectives[0].Argument("roles").Value.String())
+	require.Len(t, root.Fields, 4)
+	search := root.Fields[1]
+	assert.Equal(t, "Search for things", search.Description)
+	assert.Equal(t, "[Result!]!", TypeRefToString(search.Type))
+	require.Len(t, search.Args, 3)
+	assert.Equal(t, `"default"`, search.Args[0].DefaultValue.String())
+	assert.Equal(t, "10", search.Args[1].DefaultValue.String())
+	assert.Equal(t, `{tags: ["a", "b"], exact: true}`, search.Args[2].DefaultValue.String())
+	assert.Equal(t, KindInputObject, search.Args[2].Type.Kind)
+	assert.True(t, root.Fields[2].IsDeprecated)
+	assert.Equal(t, DefaultDeprecationReason, root.Fields[2].DeprecationReason)
+	assert.Equal(t, "Use new", root.Fields[3].DeprecationReason)
+
+	assert.Equal(t, []string{"Root", "Other", "Extra"}, types["Result"].PossibleTypes)
+	require.Len(t, types["Other"].Fields, 2)
+	assert.Equal(t, "extra", types["Other"].Fields[1].Name)
+
+	role := types["Role"]
+	require.Len(t, role.EnumValues, 3)
+	assert.True(t, role.EnumValues[1].IsDeprecated)
+	assert.Equal(t, "Use ADMIN", role.EnumValues[1].DeprecationReason)
+	assert.Equal(t, "GUEST", role.EnumValues[2].Name)
+
+	filter := types["Filter"]
+	assert.True(t, filter.IsOneOf)
+	require.Len(t, filter.InputFields, 3)
+	assert.Equal(t, "false", filter.InputFields[1].DefaultValue.String())
+	assert.True(t, filter.InputFields[2].IsDeprecated)
+	assert.Equal(t, KindScalar, filter.InputFields[2].Type.Kind)
+}
+
+func TestParseSDLDefaultRootTypes(t *testing.T) {
+	schema, err := ParseSDL(strings.NewReader(`
+type Query { a: Int }
+type Mutation { b: Int }
+type Subscription { c: Int }
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "Query", schema.QueryType)
+	assert.Equal(t, "Mutation", schema.MutationType)
+	assert.Equal(t, "Subscription", schema.SubscriptionType)
+}
+
+func TestParseSDLStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		literal  string
+		expected string
+	}{
+		{name: "Simple", literal: `"hello"`, expected: "hello"},
+		{name: "Escapes", literal: `"a\"b\\c\/d\n\t"`, expected: "a\"b\\c/d\n\t"},
+		{name: "Unicode escape", literal: `"caf\u00e9"`, expected: "café"},
+		{name: "Braced unicode escape", literal: `"\u{1F600}"`, expected: "😀"},
+		{name: "Surrogate pair", literal: `"\uD83D\uDE00"`, expected: "😀"},
+		{name: "Block string", literal: `"""hello"""`, expected: "hello"},
+		{name: "Block string dedent", literal: "\"\"\"\n    first\n      second\n    third\n  \"\"\"", expected: "first\n  second\nthird"},
+		{name: "Block string escaped quotes", literal: `"""a \""" b"""`, expected: `a """ b`},
+		{name: "Block string keeps backslashes", literal: `"""C:\path\n"""`, expected: `C:\path\n`},
+		{name: "Block string CRLF", literal: "\"\"\"\r\n  a\r\n  b\r\n\"\"\"", expected: "a\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseSDL(strings.NewReader(tt.literal + " scalar S"))
+			require.NoError(t, err)
+			require.Len(t, schema.Types, 1)
+			assert.Equal(t, tt.expected, schema.Types[0].Description)
+		})
+	}
+}
+
+func TestParseSDLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdl      string
+		expected string
+	}{
+		{
+			name:     "Unexpected token",
+			sdl:      "type Query {\n  a: Int\n  b Int\n}",
+			expected: `line 3, column 5: expected ":", found name "Int"`,
+		},
+		{
+			name:     "Unexpected end of file",
+			sdl:      "type Query {\n  a: Int",
+			expected: `line 2, column 9: expected name, found end of file`,
+		},
+		{
+			name:     "Unterminated string",
+			sdl:      "\"oops\nscalar S",
+			expected: "line 1, column 6: unterminated string",
+		},
+		{
+			name:     "Invalid escape",
+			sdl:      `"\q" scalar S`,
+			expected: `line 1, column 2: invalid escape sequence \q`,
+		},
+		{
+			name:     "Executable definition",
+			sdl:      "query { a }",
+			expected: "line 1, column 1: executable definitions are not allowed in a type system document",
+		},
+		{
+			name:     "Anonymous query",
+			sdl:      "{ a }",
+			expected: "line 1, column 1: executable definitions are not allowed in a type system document",
+		},
+		{
+			name:     "Unknown definition",
+			sdl:      "\n  object Foo { a: Int }",
+			expected: `line 2, column 3: unexpected name "object", expected a type system definition`,
+		},
+		{
+			name:     "Duplicate type",
+			sdl:      "scalar A\nscalar A",
+			expected: `line 2, column 1: type "A" is already defined`,
+		},
+		{
+			name:     "Extend undefined type",
+			sdl:      "extend type Query { a: Int }",
+			expected: `line 1, column 1: cannot extend undefined type "Query"`,
+		},
+		{
+			name:     "Extend with wrong kind",
+			sdl:      "type Query { a: Int }\nextend input Query { b: Int }",
+			expected: `line 2, column 1: cannot extend type "Query" with a input extension`,
+		},
+		{
+			name:     "Duplicate field from extension",
+			sdl:      "type Query { a: Int }\nextend type Query {\n  a: Int\n}",
+			expected: `line 3, column 3: field "a" is already defined on "Query"`,
+		},
+		{
+			name:     "Invalid directive location",
+			sdl:      "directive @a on FIELD | NOWHERE",
+			expected: `line 1, column 25: unexpected directive location "NOWHERE"`,
+		},
+		{
+			name:     "Variable in constant value",
+			sdl:      "type Query { a(b: Int = $c): Int }",
+			expected: "line 1, column 25: unexpected variable in constant value",
+		},
+		{
+			name:     "Reserved enum value",
+			sdl:      "enum E { true }",
+			expected: `line 1, column 10: "true" is reserved and cannot be used as an enum value`,
+		},
+		{
+			name:     "Duplicate schema definition",
+			sdl:      "schema { query: Q }\nschema { query: Q }",
+			expected: "line 2, column 1: schema is already defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSDL(strings.NewReader(tt.sdl))
+			require.Error(t, err)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
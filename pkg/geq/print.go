@@ -0,0 +1,110 @@
+package geq
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortOrder selects the order in which the SDL printer writes definitions.
+type SortOrder string
+
+const (
+	// SortNone keeps the order of the introspection response.
+	SortNone SortOrder = ""
+	// SortAlphabetical sorts types, directives, fields, input fields and enum
+	// values by name. Arguments keep their order.
+	SortAlphabetical SortOrder = "alpha"
+	// SortByKind groups types by kind, in the order the GraphQL specification
+	// describes them (scalars, objects, interfaces, unions, enums, input
+	// objects), and sorts alphabetically within each group.
+	SortByKind SortOrder = "kind"
+)
+
+// DefaultIndent is the number of spaces per indentation level in SDL output.
+const DefaultIndent = 2
+
+// PrintOptions controls how SDL is printed.
+type PrintOptions struct {
+	// Sort orders the definitions, so that output is stable no matter in
+	// which order the server lists types.
+	Sort SortOrder
+	// Indent is the number of spaces per indentation level. Zero means DefaultIndent.
+	Indent int
+	// OmitBuiltins leaves out the scalars and directives defined by the GraphQL
+	// specification (String, Int, @include, @deprecated, ...).
+	OmitBuiltins bool
+	// Minify prints minified SDL, without descriptions or optional
+	// whitespace. Indent is ignored.
+	Minify bool
+}
+
+// ParseSortOrder parses the name of a sort order: "none" (or ""), "alpha" or "kind".
+func ParseSortOrder(s string) (SortOrder, bool) {
+	switch s {
+	case "", "none":
+		return SortNone, true
+	case "alpha", "alphabetical":
+		return SortAlphabetical, true
+	case "kind":
+		return SortByKind, true
+	}
+	return SortNone, false
+}
+
+// indentUnit returns the string printed for one level of indentation.
+func (o PrintOptions) indentUnit() string {
+	if o.Indent <= 0 {
+		return strings.Repeat(" ", DefaultIndent)
+	}
+	return strings.Repeat(" ", o.Indent)
+}
+
+// specifiedDirectiveNames are the directives defined by the GraphQL specification.
+var specifiedDirectiveNames = map[string]bool{"include": true, "skip": true, "deprecated": true, "specifiedBy": true, "oneOf": true}
+
+// kindOrder ranks type kinds for SortByKind.
+var kindOrder = map[string]int{KindScalar: 0, KindObject: 1, KindInterface: 2, KindUnion: 3, KindEnum: 4, KindInputObject: 5}
+
+// prepare returns the schema of response as it should be printed: filtered
+// and sorted as the options ask. The response itself is not modified.
+func (o PrintOptions) prepare(response IntrospectionResponse) SchemaDef {
+	schema := response.Data.Schema
+	if o.Sort == SortNone && !o.OmitBuiltins {
+		return schema
+	}
+
+	schema.Types = slices.Clone(schema.Types)
+	schema.Directives = slices.Clone(schema.Directives)
+	if o.OmitBuiltins {
+		schema.Types = slices.DeleteFunc(schema.Types, func(t FullType) bool {
+			return t.Kind == KindScalar && builtinScalars[t.Name]
+		})
+		schema.Directives = slices.DeleteFunc(schema.Directives, func(d Directive) bool {
+			return specifiedDirectiveNames[d.Name]
+		})
+	}
+	if o.Sort == SortNone {
+		return schema
+	}
+
+	for i := range schema.Types {
+		t := &schema.Types[i]
+		t.Fields = slices.Clone(t.Fields)
+		slices.SortStableFunc(t.Fields, func(a, b Field) int { return strings.Compare(a.Name, b.Name) })
+		t.InputFields = slices.Clone(t.InputFields)
+		slices.SortStableFunc(t.InputFields, func(a, b InputValue) int { return strings.Compare(a.Name, b.Name) })
+		t.EnumValues = slices.Clone(t.EnumValues)
+		slices.SortStableFunc(t.EnumValues, func(a, b EnumValue) int { return strings.Compare(a.Name, b.Name) })
+	}
+	slices.SortStableFunc(schema.Types, func(a, b FullType) int {
+		if o.Sort == SortByKind {
+			if c := cmp.Compare(kindOrder[a.Kind], kindOrder[b.Kind]); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortStableFunc(schema.Directives, func(a, b Directive) int { return strings.Compare(a.Name, b.Name) })
+	return schema
+}
@@ -0,0 +1,103 @@
+package geq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// definitionOrder returns the names of the types and directives defined in
+// sdl, in order.
+func definitionOrder(t *testing.T, sdl string) []string {
+	t.Helper()
+	schema, err := ParseSDL(strings.NewReader(sdl))
+	require.NoError(t, err, "printed SDL does not parse:\n%s", sdl)
+	var names []string
+	for _, def := range schema.Types {
+		names = append(names, def.Name)
+	}
+	for _, directive := range schema.Directives {
+		names = append(names, "@"+directive.Name)
+	}
+	return names
+}
+
+func TestGenerateSDLWithOptionsSort(t *testing.T) {
+	response := readFixture(t, "features_introspection.json")
+
+	alpha := GenerateSDLWithOptions(response, PrintOptions{Sort: SortAlphabetical})
+	assert.Equal(t, []string{
+		"Date", "Mutation", "Named", "Node", "Person", "PersonBy", "PersonFilter", "PersonOrder",
+		"Query", "Robot", "SearchResult", "Subscription",
+		"@cached", "@deprecated", "@include", "@oneOf", "@skip", "@specifiedBy",
+	}, definitionOrder(t, alpha))
+
+	byKind := GenerateSDLWithOptions(response, PrintOptions{Sort: SortByKind})
+	assert.Equal(t, []string{
+		"Date",
+		"Mutation", "Person", "Query", "Robot", "Subscription",
+		"Named", "Node",
+		"SearchResult",
+		"PersonOrder",
+		"PersonBy", "PersonFilter",
+		"@cached", "@deprecated", "@include", "@oneOf", "@skip", "@specifiedBy",
+	}, definitionOrder(t, byKind))
+
+	schema, err := ParseSDL(strings.NewReader(alpha))
+	require.NoError(t, err)
+	var fields []string
+	for _, field := range schema.TypeByName("Person").Fields {
+		fields = append(fields, field.Name)
+	}
+	assert.Equal(t, []string{"age", "birthDate", "friends", "id", "name"}, fields)
+	var args []string
+	for _, arg := range schema.TypeByName("Person").Field("friends").Args {
+		args = append(args, arg.Name)
+	}
+	assert.Equal(t, []string{"first", "after", "orderBy"}, args, "arguments keep their order")
+	var values []string
+	for _, value := range schema.TypeByName("PersonOrder").EnumValues {
+		values = append(values, value.Name)
+	}
+	assert.Equal(t, []string{"AGE", "CREATED", "NAME"}, values)
+
+	// The response itself is left alone
+	assert.Equal(t, GenerateSDL(readFixture(t, "features_introspection.json")), GenerateSDL(response))
+
+	// Reordering the response does not change sorted output
+	reversed := readFixture(t, "features_introspection.json")
+	types := reversed.Data.Schema.Types
+	for i, j := 0, len(types)-1; i < j; i, j = i+1, j-1 {
+		types[i], types[j] = types[j], types[i]
+	}
+	assert.Equal(t, alpha, GenerateSDLWithOptions(reversed, PrintOptions{Sort: SortAlphabetical}))
+}
+
+func TestGenerateSDLWithOptionsIndentAndBuiltins(t *testing.T) {
+	response := readFixture(t, "sample_introspection.json")
+
+	sdl := GenerateSDLWithOptions(response, PrintOptions{Indent: 4, OmitBuiltins: true})
+	assert.Contains(t, sdl, "type Query {\n    \"\"\"Get a user by ID\"\"\"\n    user(\n        \"\"\"The user ID\"\"\"\n        id: ID!\n    ): User\n}")
+	assert.NotContains(t, sdl, "scalar ID")
+	assert.NotContains(t, sdl, "scalar String")
+	assert.NotContains(t, sdl, "directive @include")
+	assert.NotContains(t, sdl, "directive @skip")
+
+	assert.Equal(t, GenerateSDL(response), GenerateSDLWithOptions(response, PrintOptions{Indent: DefaultIndent}))
+
+	minified := GenerateSDLWithOptions(response, PrintOptions{Minify: true, OmitBuiltins: true, Sort: SortAlphabetical})
+	assert.Equal(t, "schema{query:Query mutation:Mutation} input CreateUserInput{name:String! role:UserRole=USER} type Mutation{createUser(input:CreateUserInput!):User} type Query{user(id:ID!):User} type User{id:ID! name:String} enum UserRole{ADMIN USER}\n", minified)
+	assert.Equal(t, GenerateMinifiedSDL(response), GenerateSDLWithOptions(response, PrintOptions{Minify: true}))
+}
+
+func TestParseSortOrder(t *testing.T) {
+	for input, expected := range map[string]SortOrder{"": SortNone, "none": SortNone, "alpha": SortAlphabetical, "alphabetical": SortAlphabetical, "kind": SortByKind} {
+		order, ok := ParseSortOrder(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, order, input)
+	}
+	_, ok := ParseSortOrder("size")
+	assert.False(t, ok)
+}
@@ -0,0 +1,247 @@
+package geq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTypeRefDepth is how many levels of ofType the introspection query
+// requests by default, matching the canonical query from graphql-js.
+const DefaultTypeRefDepth = 7
+
+// Capabilities records which introspection fields a server supports beyond
+// the original GraphQL specification. Servers reject queries that ask for
+// unknown fields, so only supported fields are requested.
+type Capabilities struct {
+	// SchemaDescription is true if __Schema has a description field.
+	SchemaDescription bool
+	// SpecifiedByURL is true if __Type has a specifiedByURL field.
+	SpecifiedByURL bool
+	// IsOneOf is true if __Type has an isOneOf field.
+	IsOneOf bool
+	// DirectiveIsRepeatable is true if __Directive has an isRepeatable field.
+	DirectiveIsRepeatable bool
+	// InputValueDeprecation is true if __InputValue has isDeprecated and
+	// deprecationReason fields.
+	InputValueDeprecation bool
+	// FieldArgsIncludeDeprecated is true if __Field.args accepts includeDeprecated.
+	FieldArgsIncludeDeprecated bool
+	// DirectiveArgsIncludeDeprecated is true if __Directive.args accepts includeDeprecated.
+	DirectiveArgsIncludeDeprecated bool
+	// InputFieldsIncludeDeprecated is true if __Type.inputFields accepts includeDeprecated.
+	InputFieldsIncludeDeprecated bool
+}
+
+// capabilitiesQuery introspects the introspection types themselves to find
+// out which fields and arguments the server supports.
+const capabilitiesQuery = `
+query IntrospectionCapabilities {
+  schema: __type(name: "__Schema") { fields { name } }
+  type: __type(name: "__Type") { fields { name args { name } } }
+  field: __type(name: "__Field") { fields { name args { name } } }
+  directive: __type(name: "__Directive") { fields { name args { name } } }
+  inputValue: __type(name: "__InputValue") { fields { name } }
+}
+`
+
+// metaType is the shape of a __type result in the capabilities query.
+type metaType struct {
+	Fields []struct {
+		Name string `json:"name"`
+		Args []struct {
+			Name string `json:"name"`
+		} `json:"args"`
+	} `json:"fields"`
+}
+
+// hasField reports whether the type has the named field.
+func (t *metaType) hasField(name string) bool {
+	return t.hasArg(name, "")
+}
+
+// hasArg reports whether the named field accepts the named argument. An
+// empty argument name only checks that the field exists.
+func (t *metaType) hasArg(fieldName, argName string) bool {
+	if t == nil {
+		return false
+	}
+	for _, field := range t.Fields {
+		if field.Name != fieldName {
+			continue
+		}
+		if argName == "" {
+			return true
+		}
+		for _, arg := range field.Args {
+			if arg.Name == argName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// capabilitiesFromProbe derives Capabilities from a capabilities query result.
+func capabilitiesFromProbe(schema, typ, field, directive, inputValue *metaType) Capabilities {
+	return Capabilities{
+		SchemaDescription:              schema.hasField("description"),
+		SpecifiedByURL:                 typ.hasField("specifiedByURL"),
+		IsOneOf:                        typ.hasField("isOneOf"),
+		DirectiveIsRepeatable:          directive.hasField("isRepeatable"),
+		InputValueDeprecation:          inputValue.hasField("isDeprecated") && inputValue.hasField("deprecationReason"),
+		FieldArgsIncludeDeprecated:     field.hasArg("args", "includeDeprecated"),
+		DirectiveArgsIncludeDeprecated: directive.hasArg("args", "includeDeprecated"),
+		InputFieldsIncludeDeprecated:   typ.hasArg("inputFields", "includeDeprecated"),
+	}
+}
+
+// QueryOptions configures the introspection query built by IntrospectionQuery.
+// Start from DefaultQueryOptions, since the zero value leaves out descriptions
+// and deprecated entries.
+type QueryOptions struct {
+	// Descriptions requests the descriptions of types, fields, arguments,
+	// enum values and directives. Leaving them out makes the response much
+	// smaller, which is all a minified schema needs.
+	Descriptions bool
+	// IncludeDeprecated requests deprecated fields and enum values, and
+	// deprecated arguments and input fields where the server supports it.
+	IncludeDeprecated bool
+	// TypeRefDepth is how many levels of ofType wrapping are requested for
+	// each type reference. Some servers reject deeply nested queries; a
+	// depth of 3 is enough for types like [String!]!. Zero means
+	// DefaultTypeRefDepth.
+	TypeRefDepth int
+	// Capabilities selects the spec-newer introspection fields to request.
+	Capabilities
+}
+
+// DefaultQueryOptions returns the options of the canonical introspection query.
+func DefaultQueryOptions() QueryOptions {
+	return QueryOptions{
+		Descriptions:      true,
+		IncludeDeprecated: true,
+		TypeRefDepth:      DefaultTypeRefDepth,
+	}
+}
+
+// IntrospectionQuery builds an introspection query document. With
+// DefaultQueryOptions it produces the canonical introspection query from
+// graphql-js; setting Capabilities requests the newer fields as well.
+func IntrospectionQuery(opts QueryOptions) string {
+	var sb strings.Builder
+	caps := opts.Capabilities
+	depth := opts.TypeRefDepth
+	if depth <= 0 {
+		depth = DefaultTypeRefDepth
+	}
+
+	description := func(indent string) {
+		if opts.Descriptions {
+			sb.WriteString(indent + "description\n")
+		}
+	}
+	includeDeprecated := func(supported bool) string {
+		return includeDeprecatedArg(opts.IncludeDeprecated && supported)
+	}
+
+	sb.WriteString("query IntrospectionQuery {\n")
+	sb.WriteString("  __schema {\n")
+	if caps.SchemaDescription {
+		description("    ")
+	}
+	sb.WriteString("    queryType { name }\n")
+	sb.WriteString("    mutationType { name }\n")
+	sb.WriteString("    subscriptionType { name }\n")
+	sb.WriteString("    types {\n")
+	sb.WriteString("      ...FullType\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    directives {\n")
+	sb.WriteString("      name\n")
+	description("      ")
+	if caps.DirectiveIsRepeatable {
+		sb.WriteString("      isRepeatable\n")
+	}
+	sb.WriteString("      locations\n")
+	sb.WriteString("      args" + includeDeprecated(caps.DirectiveArgsIncludeDeprecated && caps.InputValueDeprecation) + " {\n")
+	sb.WriteString("        ...InputValue\n")
+	sb.WriteString("      }\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("fragment FullType on __Type {\n")
+	sb.WriteString("  kind\n")
+	sb.WriteString("  name\n")
+	description("  ")
+	if caps.SpecifiedByURL {
+		sb.WriteString("  specifiedByURL\n")
+	}
+	if caps.IsOneOf {
+		sb.WriteString("  isOneOf\n")
+	}
+	sb.WriteString("  fields" + includeDeprecated(true) + " {\n")
+	sb.WriteString("    name\n")
+	description("    ")
+	sb.WriteString("    args" + includeDeprecated(caps.FieldArgsIncludeDeprecated && caps.InputValueDeprecation) + " {\n")
+	sb.WriteString("      ...InputValue\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    type {\n")
+	sb.WriteString("      ...TypeRef\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    isDeprecated\n")
+	sb.WriteString("    deprecationReason\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("  inputFields" + includeDeprecated(caps.InputFieldsIncludeDeprecated && caps.InputValueDeprecation) + " {\n")
+	sb.WriteString("    ...InputValue\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("  interfaces {\n")
+	sb.WriteString("    ...TypeRef\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("  enumValues" + includeDeprecated(true) + " {\n")
+	sb.WriteString("    name\n")
+	description("    ")
+	sb.WriteString("    isDeprecated\n")
+	sb.WriteString("    deprecationReason\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("  possibleTypes {\n")
+	sb.WriteString("    ...TypeRef\n")
+	sb.WriteString("  }\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("fragment InputValue on __InputValue {\n")
+	sb.WriteString("  name\n")
+	description("  ")
+	sb.WriteString("  type { ...TypeRef }\n")
+	sb.WriteString("  defaultValue\n")
+	if caps.InputValueDeprecation {
+		sb.WriteString("  isDeprecated\n")
+		sb.WriteString("  deprecationReason\n")
+	}
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("fragment TypeRef on __Type {\n")
+	writeTypeRef(&sb, "  ", depth)
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// includeDeprecatedArg returns the argument list that requests deprecated
+// entries, or nothing if the server does not support it.
+func includeDeprecatedArg(supported bool) string {
+	if supported {
+		return "(includeDeprecated: true)"
+	}
+	return ""
+}
+
+// writeTypeRef writes kind and name followed by depth nested ofType selections.
+func writeTypeRef(sb *strings.Builder, indent string, depth int) {
+	sb.WriteString(indent + "kind\n")
+	sb.WriteString(indent + "name\n")
+	if depth > 0 {
+		sb.WriteString(fmt.Sprintf("%sofType {\n", indent))
+		writeTypeRef(sb, indent+"  ", depth-1)
+		sb.WriteString(indent + "}\n")
+	}
+}
@@ -0,0 +1,122 @@
+package geq
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default backoff bounds used when a RetryPolicy leaves them unset.
+const (
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how a Client retries transient failures: network
+// errors and the HTTP statuses 408, 429, 502, 503 and 504.
+//
+// Waits grow exponentially from MinBackoff up to MaxBackoff with random
+// jitter. A Retry-After header on the response takes precedence over the
+// computed wait, but is capped at MaxBackoff too, so a server cannot stall
+// the client for longer.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	// Zero disables retrying.
+	MaxRetries int
+	// MinBackoff is the wait before the first retry. Defaults to DefaultMinBackoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between retries, including one asked for by a
+	// Retry-After header. Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// WithRetry enables retrying transient failures according to policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// backoff returns the jittered wait before retrying after the given attempt
+// (1-based). The wait is a random duration between half and all of
+// MinBackoff*2^(attempt-1), capped at MaxBackoff.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	minBackoff, maxBackoff := p.MinBackoff, p.maxBackoff()
+	if minBackoff <= 0 {
+		minBackoff = DefaultMinBackoff
+	}
+
+	wait := minBackoff
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxBackoff)
+
+	half := wait / 2
+	return half + rand.N(half+1)
+}
+
+// retryAfter returns the wait a Retry-After header asks for, capped at
+// MaxBackoff, or zero if the header is absent or invalid.
+func (p RetryPolicy) retryAfter(value string, now time.Time) time.Duration {
+	return min(parseRetryAfter(value, now), p.maxBackoff())
+}
+
+// maxBackoff returns MaxBackoff, or DefaultMaxBackoff if it is unset.
+func (p RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return DefaultMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+// isRetryableStatus reports whether an HTTP status indicates a transient failure.
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date. It returns zero if the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		// Avoid overflowing a Duration; the wait is capped by the caller
+		return time.Duration(min(int64(seconds), math.MaxInt64/int64(time.Second))) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package geq
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer starts a test server that answers with the given statuses in
+// order and then with the sample introspection response.
+func newFlakyServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err, "Failed to read input JSON file")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClientRetriesTransientStatuses(t *testing.T) {
+	server, requests := newFlakyServer(t, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	client := NewClient(server.URL, WithRetry(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond}))
+	result, err := client.FetchIntrospectionJSON(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, result, `"__schema"`)
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestClientRetriesExhausted(t *testing.T) {
+	server, requests := newFlakyServer(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+
+	client := NewClient(server.URL, WithRetry(RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond}))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestClientDoesNotRetryPermanentErrors(t *testing.T) {
+	server, requests := newFlakyServer(t, http.StatusBadRequest)
+
+	client := NewClient(server.URL, WithRetry(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond}))
+	_, err := client.FetchIntrospectionJSON(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClientWithoutRetryPolicy(t *testing.T) {
+	server, requests := newFlakyServer(t, http.StatusServiceUnavailable)
+
+	_, err := NewClient(server.URL).FetchIntrospectionJSON(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClientRetryStopsWhenContextIsDone(t *testing.T) {
+	server, requests := newFlakyServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := NewClient(server.URL, WithRetry(RetryPolicy{MaxRetries: 5, MinBackoff: time.Hour}))
+	_, err := client.FetchIntrospectionJSON(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 4, max: 800 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 50, max: time.Second},
+	}
+
+	for _, test := range tests {
+		for range 20 {
+			wait := policy.backoff(test.attempt)
+			assert.GreaterOrEqual(t, wait, test.max/2, "attempt %d", test.attempt)
+			assert.LessOrEqual(t, wait, test.max, "attempt %d", test.attempt)
+		}
+	}
+}
+
+func TestRetryPolicyRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	policy := RetryPolicy{MaxBackoff: time.Minute}
+	assert.Equal(t, 30*time.Second, policy.retryAfter("30", now))
+	assert.Equal(t, time.Minute, policy.retryAfter("3600", now))
+	assert.Equal(t, time.Minute, policy.retryAfter("Tue, 01 Jan 2030 12:00:00 GMT", now))
+	assert.Equal(t, time.Duration(0), policy.retryAfter("soon", now))
+
+	// Without a MaxBackoff the default applies
+	assert.Equal(t, DefaultMaxBackoff, RetryPolicy{}.retryAfter("99999999999999999", now))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "Empty", value: "", expected: 0},
+		{name: "Seconds", value: "120", expected: 2 * time.Minute},
+		{name: "Negative seconds", value: "-5", expected: 0},
+		{name: "Too many seconds", value: "9999999999999", expected: time.Duration(math.MaxInt64/int64(time.Second)) * time.Second},
+		{name: "HTTP date", value: "Mon, 01 Jan 2024 12:00:30 GMT", expected: 30 * time.Second},
+		{name: "HTTP date in the past", value: "Mon, 01 Jan 2024 11:00:00 GMT", expected: 0},
+		{name: "Invalid", value: "soon", expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseRetryAfter(test.value, now))
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package geq
+
+import (
+	"strings"
+)
+
+// Type kinds, as reported by introspection in __Type.kind.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+	KindList        = "LIST"
+	KindNonNull     = "NON_NULL"
+)
+
+// DefaultDeprecationReason is the reason used by @deprecated when none is given.
+const DefaultDeprecationReason = "No longer supported"
+
+// Schema is a typed model of a GraphQL schema, as built by ParseSDL from SDL
+// or by NewSchema from an introspection response.
+type Schema struct {
+	Description string
+	// QueryType, MutationType and SubscriptionType name the root operation
+	// types. They are empty if the schema has no such root type.
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	// Types are the named types defined in the schema, in definition order.
+	// Type extensions are merged into the type they extend.
+	Types []*TypeDefinition
+	// Directives are the directive definitions of the schema.
+	Directives []*DirectiveDefinition
+	// AppliedDirectives are the directives applied to the schema definition.
+	AppliedDirectives []*AppliedDirective
+
+	typesByName map[string]*TypeDefinition
+}
+
+// NewSchema builds a Schema from an introspection response, so that fetched
+// schemas and parsed SDL can be handled the same way. The introspection meta
+// types (__Schema, __Type, ...) are left out, as they are never part of SDL.
+// Default values are parsed into value literals and resolved against their
+// input types, as GenerateSDL prints them; one that is not valid GraphQL is
+// kept verbatim in Raw, with an empty Kind.
+func NewSchema(response IntrospectionResponse) *Schema {
+	introspected := response.Data.Schema
+	types := newTypeIndex(response)
+	schema := &Schema{
+		Description:      introspected.Description,
+		QueryType:        introspected.QueryType.Name,
+		MutationType:     introspected.MutationType.Name,
+		SubscriptionType: introspected.SubscriptionType.Name,
+	}
+
+	for _, t := range introspected.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		def := &TypeDefinition{
+			Kind:           t.Kind,
+			Name:           t.Name,
+			Description:    t.Description,
+			SpecifiedByURL: t.SpecifiedByURL,
+			IsOneOf:        t.IsOneOf,
+			InputFields:    inputValueDefinitions(t.InputFields, types),
+		}
+		for _, iface := range t.Interfaces {
+			def.Interfaces = append(def.Interfaces, iface.Name)
+		}
+		for _, possibleType := range t.PossibleTypes {
+			// Only unions list their members in SDL; the possible types of an
+			// interface follow from the objects implementing it.
+			if t.Kind == KindUnion {
+				def.PossibleTypes = append(def.PossibleTypes, possibleType.Name)
+			}
+		}
+		for _, field := range t.Fields {
+			def.Fields = append(def.Fields, &FieldDefinition{
+				Name:              field.Name,
+				Description:       field.Description,
+				Args:              inputValueDefinitions(field.Args, types),
+				Type:              field.Type,
+				IsDeprecated:      field.IsDeprecated,
+				DeprecationReason: field.DeprecationReason,
+			})
+		}
+		for _, value := range t.EnumValues {
+			def.EnumValues = append(def.EnumValues, &EnumValueDefinition{
+				Name:              value.Name,
+				Description:       value.Description,
+				IsDeprecated:      value.IsDeprecated,
+				DeprecationReason: value.DeprecationReason,
+			})
+		}
+		schema.Types = append(schema.Types, def)
+	}
+
+	for _, directive := range introspected.Directives {
+		schema.Directives = append(schema.Directives, &DirectiveDefinition{
+			Name:         directive.Name,
+			Description:  directive.Description,
+			Args:         inputValueDefinitions(directive.Args, types),
+			IsRepeatable: directive.IsRepeatable,
+			Locations:    directive.Locations,
+		})
+	}
+
+	schema.Reindex()
+	return schema
+}
+
+// inputValueDefinitions converts introspected arguments or input fields.
+// Default values are resolved against the types in types.
+func inputValueDefinitions(values []InputValue, types typeIndex) []*InputValueDefinition {
+	var defs []*InputValueDefinition
+	for _, value := range values {
+		def := &InputValueDefinition{
+			Name:              value.Name,
+			Description:       value.Description,
+			Type:              value.Type,
+			IsDeprecated:      value.IsDeprecated,
+			DeprecationReason: value.DeprecationReason,
+		}
+		if value.DefaultValue != "" {
+			literal, err := parseValueLiteral(value.DefaultValue)
+			if err != nil {
+				literal = &Value{Raw: value.DefaultValue}
+			} else {
+				literal = coerceValue(literal, value.Type, types)
+			}
+			def.DefaultValue = literal
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// Reindex rebuilds the name index behind TypeByName, RootQuery, RootMutation,
+// RootSubscription and FieldsOf. NewSchema and ParseSDL build the index, and
+// lookups only read it, so a Schema can be shared between goroutines. Call
+// Reindex after adding, removing or renaming types in Types.
+func (s *Schema) Reindex() {
+	s.typesByName = make(map[string]*TypeDefinition, len(s.Types))
+	for _, def := range s.Types {
+		s.typesByName[def.Name] = def
+	}
+}
+
+// TypeByName returns the named type, or nil if the schema has no such type.
+// The lookup uses the index built by NewSchema, ParseSDL or Reindex.
+func (s *Schema) TypeByName(name string) *TypeDefinition {
+	return s.typesByName[name]
+}
+
+// kindOf returns the kind of a named type, or "" if the schema does not
+// define it. Built-in scalars are scalars even when they are not defined.
+func (s *Schema) kindOf(name string) string {
+	if def := s.TypeByName(name); def != nil {
+		return def.Kind
+	}
+	if builtinScalars[name] {
+		return KindScalar
+	}
+	return ""
+}
+
+// RootQuery returns the query root type, or nil if the schema has none.
+func (s *Schema) RootQuery() *TypeDefinition {
+	return s.TypeByName(s.QueryType)
+}
+
+// RootMutation returns the mutation root type, or nil if the schema has none.
+func (s *Schema) RootMutation() *TypeDefinition {
+	return s.TypeByName(s.MutationType)
+}
+
+// RootSubscription returns the subscription root type, or nil if the schema
+// has none.
+func (s *Schema) RootSubscription() *TypeDefinition {
+	return s.TypeByName(s.SubscriptionType)
+}
+
+// FieldsOf returns the fields of the named object or interface type, or nil
+// if there is no such type.
+func (s *Schema) FieldsOf(typeName string) []*FieldDefinition {
+	if def := s.TypeByName(typeName); def != nil {
+		return def.Fields
+	}
+	return nil
+}
+
+// DirectiveByName returns the named directive definition, or nil if the
+// schema does not define it.
+func (s *Schema) DirectiveByName(name string) *DirectiveDefinition {
+	for _, directive := range s.Directives {
+		if directive.Name == name {
+			return directive
+		}
+	}
+	return nil
+}
+
+// TypeDefinition is a named type of any kind. Only the fields that apply to
+// the type's Kind are set.
+type TypeDefinition struct {
+	Kind        string
+	Name        string
+	Description string
+	// Interfaces are the names of the interfaces implemented by an object or interface type.
+	Interfaces []string
+	// Fields are the fields of an object or interface type.
+	Fields []*FieldDefinition
+	// PossibleTypes are the member type names of a union.
+	PossibleTypes []string
+	// EnumValues are the values of an enum type.
+	EnumValues []*EnumValueDefinition
+	// InputFields are the fields of an input object type.
+	InputFields []*InputValueDefinition
+	// SpecifiedByURL is the @specifiedBy URL of a custom scalar.
+	SpecifiedByURL string
+	// IsOneOf is true for an input object type marked with @oneOf.
+	IsOneOf           bool
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// Field returns the named field of an object or interface type, or nil if
+// the type has no such field.
+func (t *TypeDefinition) Field(name string) *FieldDefinition {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// InputField returns the named field of an input object type, or nil if the
+// type has no such field.
+func (t *TypeDefinition) InputField(name string) *InputValueDefinition {
+	for _, field := range t.InputFields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// EnumValue returns the named value of an enum type, or nil if the type has
+// no such value.
+func (t *TypeDefinition) EnumValue(name string) *EnumValueDefinition {
+	for _, value := range t.EnumValues {
+		if value.Name == name {
+			return value
+		}
+	}
+	return nil
+}
+
+// FieldDefinition is a field of an object or interface type.
+type FieldDefinition struct {
+	Name              string
+	Description       string
+	Args              []*InputValueDefinition
+	Type              TypeRef
+	IsDeprecated      bool
+	DeprecationReason string
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// InputValueDefinition is an argument or an input object field.
+type InputValueDefinition struct {
+	Name        string
+	Description string
+	Type        TypeRef
+	// DefaultValue is the default value literal, or nil if there is none.
+	DefaultValue      *Value
+	IsDeprecated      bool
+	DeprecationReason string
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// EnumValueDefinition is a value of an enum type.
+type EnumValueDefinition struct {
+	Name              string
+	Description       string
+	IsDeprecated      bool
+	DeprecationReason string
+	AppliedDirectives []*AppliedDirective
+	Location          Location
+}
+
+// DirectiveDefinition is a directive declared by the schema.
+type DirectiveDefinition struct {
+	Name         string
+	Description  string
+	Args         []*InputValueDefinition
+	IsRepeatable bool
+	Locations    []string
+	Location     Location
+}
+
+// AppliedDirective is a directive used on a definition, e.g. @deprecated(reason: "...").
+type AppliedDirective struct {
+	Name      string
+	Arguments []*Argument
+	Location  Location
+}
+
+// Argument is a named argument value passed to a directive or field.
+type Argument struct {
+	Name     string
+	Value    *Value
+	Location Location
+}
+
+// Argument returns the named argument, or nil if it is not given.
+func (d *AppliedDirective) Argument(name string) *Argument {
+	for _, arg := range d.Arguments {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// Value kinds of a GraphQL value literal.
+const (
+	ValueVariable = "Variable"
+	ValueInt      = "Int"
+	ValueFloat    = "Float"
+	ValueString   = "String"
+	ValueBoolean  = "Boolean"
+	ValueNull     = "Null"
+	ValueEnum     = "Enum"
+	ValueList     = "List"
+	ValueObject   = "Object"
+)
+
+// Value is a GraphQL value literal.
+type Value struct {
+	// Kind is one of the Value* constants.
+	Kind string
+	// Raw is the value of a scalar literal: the source text of an Int, Float,
+	// Boolean or Enum value, the decoded contents of a String and the name of
+	// a Variable.
+	Raw string
+	// Block is true for a String written as a block string.
+	Block bool
+	// List holds the items of a List value.
+	List []*Value
+	// Fields holds the fields of an Object value.
+	Fields   []*ObjectField
+	Location Location
+}
+
+// ObjectField is a field of an input object value literal.
+type ObjectField struct {
+	Name     string
+	Value    *Value
+	Location Location
+}
+
+// String prints the value as a GraphQL literal, e.g. {name: "x", tags: [A, B]}.
+func (v *Value) String() string {
+	var sb strings.Builder
+	writeValueLiteral(&sb, v, false)
+	return sb.String()
+}
+
+// writeValueLiteral prints a value literal. With compact set, the optional
+// whitespace and commas are left out: {name:"x" tags:[A B]}.
+func writeValueLiteral(sb *strings.Builder, v *Value, compact bool) {
+	if v == nil {
+		sb.WriteString("null")
+		return
+	}
+	separator, colon := ", ", ": "
+	if compact {
+		separator, colon = " ", ":"
+	}
+	switch v.Kind {
+	case ValueVariable:
+		sb.WriteString("$" + v.Raw)
+	case ValueString:
+		sb.WriteString(`"` + escapeString(v.Raw) + `"`)
+	case ValueNull:
+		sb.WriteString("null")
+	case ValueList:
+		sb.WriteString("[")
+		for i, item := range v.List {
+			if i > 0 {
+				sb.WriteString(separator)
+			}
+			writeValueLiteral(sb, item, compact)
+		}
+		sb.WriteString("]")
+	case ValueObject:
+		sb.WriteString("{")
+		for i, field := range v.Fields {
+			if i > 0 {
+				sb.WriteString(separator)
+			}
+			sb.WriteString(field.Name + colon)
+			writeValueLiteral(sb, field.Value, compact)
+		}
+		sb.WriteString("}")
+	default:
+		sb.WriteString(v.Raw)
+	}
+}
+
+// builtinScalars are the scalar types defined by the GraphQL specification.
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
@@ -0,0 +1,108 @@
+package geq
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchema(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	schema := NewSchema(response)
+	assert.Equal(t, "Query", schema.QueryType)
+	assert.Equal(t, "Mutation", schema.MutationType)
+
+	query := schema.RootQuery()
+	require.NotNil(t, query)
+	assert.Equal(t, "The root query object", query.Description)
+	require.NotNil(t, query.Field("user"))
+	assert.Equal(t, "User", query.Field("user").Type.Name)
+	assert.Nil(t, query.Field("missing"))
+	assert.Nil(t, schema.RootSubscription())
+
+	fields := schema.FieldsOf("User")
+	require.Len(t, fields, 2)
+	assert.Equal(t, "id", fields[0].Name)
+	assert.Nil(t, schema.FieldsOf("Missing"))
+	assert.Nil(t, schema.TypeByName("Missing"))
+
+	for _, def := range schema.Types {
+		assert.False(t, strings.HasPrefix(def.Name, "__"), "meta type %s should be skipped", def.Name)
+	}
+	assert.NotNil(t, schema.DirectiveByName("include"))
+}
+
+func TestNewSchemaDefaultValues(t *testing.T) {
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "interfaces": [], "fields": [
+				{"name": "users", "type": {"kind": "SCALAR", "name": "Int"}, "args": [
+					{"name": "filter", "type": {"kind": "INPUT_OBJECT", "name": "Filter"}, "defaultValue": "{roles: [ADMIN], name: \"x\"}"},
+					{"name": "broken", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "{"},
+					{"name": "none", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": null},
+					{"name": "role", "type": {"kind": "ENUM", "name": "Role"}, "defaultValue": "\"ADMIN\""}
+				]}
+			]},
+			{"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}]}
+		]
+	}}}`), &response))
+
+	args := NewSchema(response).FieldsOf("Query")[0].Args
+	require.Len(t, args, 4)
+	require.NotNil(t, args[0].DefaultValue)
+	assert.Equal(t, ValueObject, args[0].DefaultValue.Kind)
+	assert.Equal(t, `{roles: [ADMIN], name: "x"}`, args[0].DefaultValue.String())
+	require.NotNil(t, args[1].DefaultValue)
+	assert.Empty(t, args[1].DefaultValue.Kind)
+	assert.Equal(t, "{", args[1].DefaultValue.String())
+	assert.Nil(t, args[2].DefaultValue)
+	// An enum default reported as a string is resolved against the enum type
+	require.NotNil(t, args[3].DefaultValue)
+	assert.Equal(t, ValueEnum, args[3].DefaultValue.Kind)
+	assert.Equal(t, "ADMIN", args[3].DefaultValue.String())
+}
+
+func TestSchemaLookupsAfterParse(t *testing.T) {
+	schema, err := ParseSDL(strings.NewReader(`
+type Query { a: Int }
+input Filter { name: String }
+enum Role { ADMIN }
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "a", schema.RootQuery().Field("a").Name)
+	assert.NotNil(t, schema.TypeByName("Filter").InputField("name"))
+	assert.NotNil(t, schema.TypeByName("Role").EnumValue("ADMIN"))
+	assert.Nil(t, schema.RootMutation())
+
+	// Types appended after construction are found once the schema is reindexed
+	schema.Types = append(schema.Types, &TypeDefinition{Kind: KindScalar, Name: "Date"})
+	assert.Nil(t, schema.TypeByName("Date"))
+	schema.Reindex()
+	assert.NotNil(t, schema.TypeByName("Date"))
+}
+
+func TestIntrospectionResponseJSONRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+
+	encoded, err := json.Marshal(response)
+	require.NoError(t, err)
+	var decoded IntrospectionResponse
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, response, decoded)
+	assert.Equal(t, GenerateSDL(response), GenerateSDL(decoded))
+}
@@ -1,26 +1,131 @@
 package geq
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 // escapeString escapes characters in a string according to GraphQL string literal rules.
+// Quotes, backslashes and control characters are escaped; other characters,
+// including non-ASCII ones, are printed as they are.
 func escapeString(s string) string {
 	var sb strings.Builder
-	for _, r := range s {
+	for i, r := range s {
 		switch r {
 		case '\\':
-			sb.WriteString("\\")
+			sb.WriteString(`\\`)
 		case '"':
-			sb.WriteString("\"")
+			sb.WriteString(`\"`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
 		default:
-			sb.WriteRune(r)
+			switch {
+			case r == utf8.RuneError && !strings.HasPrefix(s[i:], "\uFFFD"):
+				// Invalid UTF-8 cannot be printed; use the replacement character.
+				sb.WriteString(`\uFFFD`)
+			case r < 0x20 || (r >= 0x7F && r <= 0x9F):
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			default:
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// printBlockString prints s as a block string, in the style of graphql-js:
+// short single-line strings stay on one line, longer ones are printed with the
+// quotes on their own lines. Relative indentation is kept, and quotes or a
+// backslash at the end of s are kept apart from the closing quotes. s must be
+// printable as a block string (see isPrintableAsBlockString).
+func printBlockString(s string) string {
+	escaped := strings.ReplaceAll(s, `"""`, `\"""`)
+	lines := strings.Split(escaped, "\n")
+	isSingleLine := len(lines) == 1
+
+	// If every line after the first is empty or indented, the first line must
+	// start on its own line, or the indentation would be removed when parsed.
+	forceLeadingNewLine := len(lines) > 1
+	for _, line := range lines[1:] {
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			forceLeadingNewLine = false
+			break
 		}
 	}
+
+	hasTrailingTripleQuotes := strings.HasSuffix(escaped, `\"""`)
+	hasTrailingQuote := strings.HasSuffix(s, `"`) && !hasTrailingTripleQuotes
+	hasTrailingSlash := strings.HasSuffix(s, `\`)
+	forceTrailingNewLine := hasTrailingQuote || hasTrailingSlash
+
+	printAsMultipleLines := !isSingleLine || len(s) > 70 || forceTrailingNewLine || forceLeadingNewLine || hasTrailingTripleQuotes
+
+	var sb strings.Builder
+	sb.WriteString(`"""`)
+	// A single line starting with whitespace must not start on a new line, or
+	// the whitespace would be removed as indentation.
+	skipLeadingNewLine := isSingleLine && s != "" && (s[0] == ' ' || s[0] == '\t')
+	if (printAsMultipleLines && !skipLeadingNewLine) || forceLeadingNewLine {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(escaped)
+	if printAsMultipleLines || forceTrailingNewLine {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`"""`)
 	return sb.String()
 }
 
+// isPrintableAsBlockString reports whether s survives being printed as a block
+// string and parsed again. Block strings cannot hold control characters or
+// carriage returns, and lose leading and trailing blank lines and indentation
+// common to all lines.
+func isPrintableAsBlockString(s string) bool {
+	if s == "" {
+		return true
+	}
+	isEmptyLine := true
+	hasIndent := false
+	hasCommonIndent := true
+	seenNonEmptyLine := false
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			if isEmptyLine && !seenNonEmptyLine {
+				return false // Leading blank line
+			}
+			seenNonEmptyLine = true
+			isEmptyLine = true
+			hasIndent = false
+		case r == '\t' || r == ' ':
+			hasIndent = hasIndent || isEmptyLine
+		case r < 0x20:
+			return false // Control character or carriage return
+		default:
+			hasCommonIndent = hasCommonIndent && hasIndent
+			isEmptyLine = false
+		}
+	}
+	if isEmptyLine {
+		return false // Trailing blank line
+	}
+	if hasCommonIndent && seenNonEmptyLine {
+		return false // Indentation common to all lines
+	}
+	return true
+}
+
 // TypeRefToString converts a TypeRef to its string representation in SDL
 func TypeRefToString(typeRef TypeRef) string {
 	if typeRef.Kind == "NON_NULL" && typeRef.OfType != nil {
@@ -36,21 +141,27 @@ func TypeRefToString(typeRef TypeRef) string {
 	}
 }
 
-// Helper function to print descriptions using block strings
-func printDescription(sb *strings.Builder, desc string, indent string) {
-	if desc != "" {
-		escapedDesc := strings.ReplaceAll(desc, `"""`, `\"\"\"`) // Escape triple quotes
-		sb.WriteString(indent + `"""` + "\n")
-		lines := strings.Split(strings.TrimSpace(escapedDesc), "\n") // Trim whitespace before splitting
-		for _, line := range lines {
-			sb.WriteString(indent + strings.TrimSpace(line) + "\n") // Trim each line
+// Helper function to print descriptions, as block strings where possible.
+// Lines are indented to the level of the described element; lines of the
+// description keep their indentation relative to each other.
+func printDescription(sb sdlWriter, desc string, indent string) {
+	if desc == "" {
+		return
+	}
+	literal := `"` + escapeString(desc) + `"`
+	if isPrintableAsBlockString(desc) {
+		literal = printBlockString(desc)
+	}
+	for _, line := range strings.Split(literal, "\n") {
+		if line != "" {
+			sb.WriteString(indent + line)
 		}
-		sb.WriteString(indent + `"""` + "\n")
+		sb.WriteString("\n")
 	}
 }
 
 // Helper function to print the @deprecated directive
-func printDeprecated(sb *strings.Builder, isDeprecated bool, reason string) {
+func printDeprecated(sb sdlWriter, isDeprecated bool, reason string) {
 	if isDeprecated {
 		sb.WriteString(" @deprecated")
 		// Only add reason if it's not empty and not the default "No longer supported"
@@ -61,8 +172,29 @@ func printDeprecated(sb *strings.Builder, isDeprecated bool, reason string) {
 	}
 }
 
-// Helper function to print arguments with descriptions and deprecation
-func printArguments(sb *strings.Builder, args []InputValue, baseIndent string) {
+// Helper function to print the @specifiedBy directive of a custom scalar
+func printSpecifiedBy(sb sdlWriter, url string) {
+	if url != "" {
+		sb.WriteString(fmt.Sprintf(" @specifiedBy(url: \"%s\")", escapeString(url)))
+	}
+}
+
+// Helper function to print the interfaces implemented by an object or interface type
+func printImplements(sb sdlWriter, interfaces []TypeRef) {
+	for i, interf := range interfaces {
+		if i == 0 {
+			sb.WriteString(" implements ")
+		} else {
+			sb.WriteString(" & ")
+		}
+		sb.WriteString(TypeRefToString(interf))
+	}
+}
+
+// Helper function to print arguments with descriptions and deprecation.
+// baseIndent is the indentation of the field or directive the arguments
+// belong to; unit is one level of indentation.
+func printArguments(sb sdlWriter, args []InputValue, baseIndent, unit string, types typeIndex) {
 	if len(args) == 0 {
 		return
 	}
@@ -76,8 +208,7 @@ func printArguments(sb *strings.Builder, args []InputValue, baseIndent string) {
 		}
 	}
 
-	indent := baseIndent + "  "
-	argIndent := baseIndent + "    " // Indentation for arguments inside multiline parentheses
+	argIndent := baseIndent + unit // Indentation for arguments inside multiline parentheses
 
 	if hasArgDescriptions {
 		sb.WriteString("(\n") // Start multiline arguments
@@ -85,14 +216,12 @@ func printArguments(sb *strings.Builder, args []InputValue, baseIndent string) {
 			printDescription(sb, arg.Description, argIndent) // Print description if exists
 			sb.WriteString(argIndent + arg.Name + ": " + TypeRefToString(arg.Type))
 			if arg.DefaultValue != "" {
-				// TODO: Handle non-string default values correctly (e.g., numbers, booleans, enums need no quotes)
-				// This requires knowing the type of the argument. For now, assuming string/correct format.
-				sb.WriteString(" = " + arg.DefaultValue)
+				sb.WriteString(" = " + formatDefaultValue(arg.DefaultValue, arg.Type, types, false))
 			}
 			printDeprecated(sb, arg.IsDeprecated, arg.DeprecationReason) // Add deprecated directive if needed
 			sb.WriteString("\n")                                         // Newline after each argument
 			if i == len(args)-1 {                                        // Adjust closing parenthesis position
-				sb.WriteString(indent + ")")
+				sb.WriteString(baseIndent + ")")
 			}
 		}
 		// Removed redundant closing paren write here
@@ -104,7 +233,7 @@ func printArguments(sb *strings.Builder, args []InputValue, baseIndent string) {
 			}
 			sb.WriteString(arg.Name + ": " + TypeRefToString(arg.Type))
 			if arg.DefaultValue != "" {
-				sb.WriteString(" = " + arg.DefaultValue)
+				sb.WriteString(" = " + formatDefaultValue(arg.DefaultValue, arg.Type, types, false))
 			}
 			printDeprecated(sb, arg.IsDeprecated, arg.DeprecationReason)
 		}
@@ -113,29 +242,82 @@ func printArguments(sb *strings.Builder, args []InputValue, baseIndent string) {
 }
 
 // Helper function to print arguments without descriptions for minified output
-func printMinifiedArguments(sb *strings.Builder, args []InputValue) {
+func printMinifiedArguments(sb sdlWriter, args []InputValue, types typeIndex) {
 	if len(args) == 0 {
 		return
 	}
 	sb.WriteString("(")
 	for i, arg := range args {
 		if i > 0 {
-			sb.WriteString(",") // No space after comma
+			sb.WriteString(" ") // Commas are optional in GraphQL
 		}
-		sb.WriteString(arg.Name + ":" + TypeRefToString(arg.Type))
-		if arg.DefaultValue != "" {
-			// TODO: Handle non-string default values correctly for minified output
-			sb.WriteString("=" + arg.DefaultValue)
-		}
-		// Skip deprecated directive in minified output
+		sb.WriteString(minifiedInputValue(arg, types))
 	}
 	sb.WriteString(")")
 }
 
+// Helper function to print an argument or input field for minified output
+func minifiedInputValue(value InputValue, types typeIndex) string {
+	var sb strings.Builder
+	sb.WriteString(value.Name + ":" + TypeRefToString(value.Type))
+	if value.DefaultValue != "" {
+		sb.WriteString("=" + formatDefaultValue(value.DefaultValue, value.Type, types, true))
+	}
+	printMinifiedDeprecated(&sb, value.IsDeprecated, value.DeprecationReason)
+	return sb.String()
+}
+
+// Helper function to print the @deprecated directive without optional whitespace
+func printMinifiedDeprecated(sb sdlWriter, isDeprecated bool, reason string) {
+	if isDeprecated {
+		sb.WriteString("@deprecated")
+		if reason != "" && reason != "No longer supported" {
+			sb.WriteString(fmt.Sprintf("(reason:\"%s\")", escapeString(reason)))
+		}
+	}
+}
+
+// sdlWriter is what the SDL printer writes to. The printer ignores write
+// errors: a strings.Builder never fails, and a bufio.Writer keeps the first
+// error and returns it from Flush.
+type sdlWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
 // GenerateSDL converts the introspection response to SDL (Schema Definition Language) format
 func GenerateSDL(response IntrospectionResponse) string {
+	return GenerateSDLWithOptions(response, PrintOptions{})
+}
+
+// GenerateSDLWithOptions converts the introspection response to SDL, sorted,
+// indented and filtered as opts asks.
+func GenerateSDLWithOptions(response IntrospectionResponse, opts PrintOptions) string {
 	var sb strings.Builder
+	writeSDL(&sb, response, opts)
+	return sb.String()
+}
+
+// WriteSDL writes the introspection response to w as SDL, printed as
+// GenerateSDLWithOptions prints it. Definitions are written as they are
+// printed, so the document is never held in memory as a whole.
+func WriteSDL(w io.Writer, response IntrospectionResponse, opts PrintOptions) error {
+	bw := bufio.NewWriter(w)
+	writeSDL(bw, response, opts)
+	return bw.Flush()
+}
+
+// writeSDL prints the introspection response to sb as SDL.
+func writeSDL(sb sdlWriter, response IntrospectionResponse, opts PrintOptions) {
+	if opts.Minify {
+		writeMinifiedSDL(sb, response, opts)
+		return
+	}
+
+	schema := opts.prepare(response)
+	unit := opts.indentUnit()
 	printedTypes := make(map[string]bool) // Track printed types to avoid duplicates
+	types := newTypeIndex(response)       // Resolves the types of default values
 
 	// Standard GraphQL scalars and directives to potentially skip or handle specially
 	standardScalars := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
@@ -145,27 +327,28 @@ func GenerateSDL(response IntrospectionResponse) string {
 
 	schemaDef := strings.Builder{}
 	schemaDef.WriteString("schema {\n")
-	if response.Data.Schema.QueryType.Name != "" {
-		schemaDef.WriteString(fmt.Sprintf("  query: %s\n", response.Data.Schema.QueryType.Name))
+	if schema.QueryType.Name != "" {
+		schemaDef.WriteString(fmt.Sprintf(unit+"query: %s\n", schema.QueryType.Name))
 		hasSchemaDefinition = true
 	}
-	if response.Data.Schema.MutationType.Name != "" {
-		schemaDef.WriteString(fmt.Sprintf("  mutation: %s\n", response.Data.Schema.MutationType.Name))
+	if schema.MutationType.Name != "" {
+		schemaDef.WriteString(fmt.Sprintf(unit+"mutation: %s\n", schema.MutationType.Name))
 		hasSchemaDefinition = true
 	}
-	if response.Data.Schema.SubscriptionType.Name != "" {
-		schemaDef.WriteString(fmt.Sprintf("  subscription: %s\n", response.Data.Schema.SubscriptionType.Name))
+	if schema.SubscriptionType.Name != "" {
+		schemaDef.WriteString(fmt.Sprintf(unit+"subscription: %s\n", schema.SubscriptionType.Name))
 		hasSchemaDefinition = true
 	}
 	schemaDef.WriteString("}\n\n")
 
 	// Only print schema definition if it has any root types defined
 	if hasSchemaDefinition {
+		printDescription(sb, schema.Description, "")
 		sb.WriteString(schemaDef.String())
 	}
 
 	// -- Types Definition --
-	for _, typeObj := range response.Data.Schema.Types {
+	for _, typeObj := range schema.Types {
 		// Skip introspection types and already printed types
 		if strings.HasPrefix(typeObj.Name, "__") || printedTypes[typeObj.Name] {
 			continue
@@ -177,28 +360,22 @@ func GenerateSDL(response IntrospectionResponse) string {
 		}
 
 		printedTypes[typeObj.Name] = true
-		printDescription(&sb, typeObj.Description, "") // Print type description
+		printDescription(sb, typeObj.Description, "") // Print type description
 
 		switch typeObj.Kind {
 		case "OBJECT":
 			sb.WriteString("type " + typeObj.Name)
-			if len(typeObj.Interfaces) > 0 {
-				sb.WriteString(" implements")
-				for _, interf := range typeObj.Interfaces {
-					// Need to resolve TypeRef for interfaces
-					sb.WriteString(" & " + TypeRefToString(interf))
-				}
-			}
+			printImplements(sb, typeObj.Interfaces)
 			sb.WriteString(" {\n")
 			for _, field := range typeObj.Fields {
 				if strings.HasPrefix(field.Name, "__") {
 					continue
 				} // Skip __typename etc. fields? Usually not needed in SDL.
-				printDescription(&sb, field.Description, "  ")
-				sb.WriteString("  " + field.Name)
-				printArguments(&sb, field.Args, "  ")
+				printDescription(sb, field.Description, unit)
+				sb.WriteString(unit + field.Name)
+				printArguments(sb, field.Args, unit, unit, types)
 				sb.WriteString(": " + TypeRefToString(field.Type))
-				printDeprecated(&sb, field.IsDeprecated, field.DeprecationReason)
+				printDeprecated(sb, field.IsDeprecated, field.DeprecationReason)
 				sb.WriteString("\n")
 			}
 			sb.WriteString("}\n\n")
@@ -207,39 +384,38 @@ func GenerateSDL(response IntrospectionResponse) string {
 			sb.WriteString("interface " + typeObj.Name)
 			// GraphQL spec allows interfaces to implement other interfaces (RFC: June 2018)
 			// The introspection query shape might need update if this is supported by target server.
-			if len(typeObj.Interfaces) > 0 {
-				sb.WriteString(" implements")
-				for _, interf := range typeObj.Interfaces {
-					sb.WriteString(" & " + TypeRefToString(interf))
-				}
-			}
+			printImplements(sb, typeObj.Interfaces)
 			sb.WriteString(" {\n")
 			for _, field := range typeObj.Fields {
 				if strings.HasPrefix(field.Name, "__") {
 					continue
 				}
-				printDescription(&sb, field.Description, "  ")
-				sb.WriteString("  " + field.Name)
-				printArguments(&sb, field.Args, "  ")
+				printDescription(sb, field.Description, unit)
+				sb.WriteString(unit + field.Name)
+				printArguments(sb, field.Args, unit, unit, types)
 				sb.WriteString(": " + TypeRefToString(field.Type))
-				printDeprecated(&sb, field.IsDeprecated, field.DeprecationReason)
+				printDeprecated(sb, field.IsDeprecated, field.DeprecationReason)
 				sb.WriteString("\n")
 			}
 			sb.WriteString("}\n\n")
 
 		case "INPUT_OBJECT":
-			sb.WriteString("input " + typeObj.Name + " {\n")
+			sb.WriteString("input " + typeObj.Name)
+			if typeObj.IsOneOf {
+				sb.WriteString(" @oneOf")
+			}
+			sb.WriteString(" {\n")
 			for _, field := range typeObj.InputFields {
 				if strings.HasPrefix(field.Name, "__") {
 					continue
 				}
-				printDescription(&sb, field.Description, "  ")
-				sb.WriteString("  " + field.Name + ": " + TypeRefToString(field.Type))
+				printDescription(sb, field.Description, unit)
+				sb.WriteString(unit + field.Name + ": " + TypeRefToString(field.Type))
 				if field.DefaultValue != "" {
-					sb.WriteString(" = " + field.DefaultValue) // TODO: Handle non-string defaults
+					sb.WriteString(" = " + formatDefaultValue(field.DefaultValue, field.Type, types, false))
 				}
 				// Note: Input fields can be deprecated as per GraphQL Spec (Oct 2021)
-				printDeprecated(&sb, field.IsDeprecated, field.DeprecationReason)
+				printDeprecated(sb, field.IsDeprecated, field.DeprecationReason)
 				sb.WriteString("\n")
 			}
 			sb.WriteString("}\n\n")
@@ -250,9 +426,9 @@ func GenerateSDL(response IntrospectionResponse) string {
 				if strings.HasPrefix(enumValue.Name, "__") {
 					continue
 				}
-				printDescription(&sb, enumValue.Description, "  ")
-				sb.WriteString("  " + enumValue.Name)
-				printDeprecated(&sb, enumValue.IsDeprecated, enumValue.DeprecationReason)
+				printDescription(sb, enumValue.Description, unit)
+				sb.WriteString(unit + enumValue.Name)
+				printDeprecated(sb, enumValue.IsDeprecated, enumValue.DeprecationReason)
 				sb.WriteString("\n")
 			}
 			sb.WriteString("}\n\n")
@@ -272,18 +448,22 @@ func GenerateSDL(response IntrospectionResponse) string {
 
 		case "SCALAR":
 			// Handled above: only print custom scalars or standard ones with descriptions
-			sb.WriteString("scalar " + typeObj.Name + "\n\n")
+			sb.WriteString("scalar " + typeObj.Name)
+			printSpecifiedBy(sb, typeObj.SpecifiedByURL)
+			sb.WriteString("\n\n")
 		}
 	}
 
 	// -- Directives Definition --
 	// Process all directives from the introspection data
-	for _, directive := range response.Data.Schema.Directives {
-		printDescription(&sb, directive.Description, "")
+	for _, directive := range schema.Directives {
+		printDescription(sb, directive.Description, "")
 		sb.WriteString("directive @" + directive.Name)
-		printArguments(&sb, directive.Args, "")
-		// Add 'repeatable' keyword if introspection provides it (not in standard query)
-		// if directive.IsRepeatable { sb.WriteString(" repeatable") }
+		printArguments(sb, directive.Args, "", unit, types)
+		// Add 'repeatable' keyword if introspection provides it (requires capability probing)
+		if directive.IsRepeatable {
+			sb.WriteString(" repeatable")
+		}
 		sb.WriteString(" on")
 		for i, location := range directive.Locations {
 			sb.WriteString(" ")
@@ -294,49 +474,52 @@ func GenerateSDL(response IntrospectionResponse) string {
 		}
 		sb.WriteString("\n\n")
 	}
-
-	// Trim trailing whitespace and ensure trailing newlines at the end
-	return strings.TrimSpace(sb.String()) + "\n\n"
 }
 
-// GenerateMinifiedSDL generates SDL without descriptions or comments, suitable for storage or comparison.
+// GenerateMinifiedSDL generates SDL without descriptions, comments or
+// optional whitespace, suitable for storage or comparison. The output is valid
+// GraphQL: it keeps everything except descriptions, including deprecations.
 func GenerateMinifiedSDL(response IntrospectionResponse) string {
-	var sb strings.Builder
+	return GenerateSDLWithOptions(response, PrintOptions{Minify: true})
+}
+
+// writeMinifiedSDL prints minified SDL to sb, with the definitions sorted
+// and filtered as opts asks.
+func writeMinifiedSDL(sb sdlWriter, response IntrospectionResponse, opts PrintOptions) {
 	printedTypes := make(map[string]bool)
+	types := newTypeIndex(response)
+	schema := opts.prepare(response)
 
 	standardScalars := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
 
+	// Definitions are separated by a single space, which is all the GraphQL
+	// grammar needs between a closing token and the next keyword.
+	printed := false
+	printDefinition := func(def string) {
+		if printed {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(def)
+		printed = true
+	}
+
 	// -- Schema Definition --
-	hasSchemaDefinition := false
-	schemaDef := strings.Builder{}
-	schemaDef.WriteString("schema{")
-	if response.Data.Schema.QueryType.Name != "" {
-		schemaDef.WriteString(fmt.Sprintf("query:%s", response.Data.Schema.QueryType.Name))
-		hasSchemaDefinition = true
+	var rootTypes []string
+	if schema.QueryType.Name != "" {
+		rootTypes = append(rootTypes, "query:"+schema.QueryType.Name)
 	}
-	if response.Data.Schema.MutationType.Name != "" {
-		if hasSchemaDefinition {
-			schemaDef.WriteString(" ")
-		}
-		schemaDef.WriteString(fmt.Sprintf("mutation:%s", response.Data.Schema.MutationType.Name))
-		hasSchemaDefinition = true
+	if schema.MutationType.Name != "" {
+		rootTypes = append(rootTypes, "mutation:"+schema.MutationType.Name)
 	}
-	if response.Data.Schema.SubscriptionType.Name != "" {
-		if hasSchemaDefinition {
-			schemaDef.WriteString(" ")
-		}
-		schemaDef.WriteString(fmt.Sprintf("subscription:%s", response.Data.Schema.SubscriptionType.Name))
-		hasSchemaDefinition = true
+	if schema.SubscriptionType.Name != "" {
+		rootTypes = append(rootTypes, "subscription:"+schema.SubscriptionType.Name)
 	}
-	schemaDef.WriteString("}")
-
-	if hasSchemaDefinition {
-		sb.WriteString(schemaDef.String())
-		sb.WriteString(" ")
+	if len(rootTypes) > 0 {
+		printDefinition("schema{" + strings.Join(rootTypes, " ") + "}")
 	}
 
 	// -- Types Definition --
-	for _, typeObj := range response.Data.Schema.Types {
+	for _, typeObj := range schema.Types {
 		if strings.HasPrefix(typeObj.Name, "__") || printedTypes[typeObj.Name] {
 			continue
 		}
@@ -346,134 +529,104 @@ func GenerateMinifiedSDL(response IntrospectionResponse) string {
 		}
 		printedTypes[typeObj.Name] = true
 
+		var def strings.Builder
 		switch typeObj.Kind {
-		case "OBJECT":
-			sb.WriteString("type " + typeObj.Name)
-			if len(typeObj.Interfaces) > 0 {
-				sb.WriteString(" implements")
-				for _, interf := range typeObj.Interfaces {
-					sb.WriteString("&" + TypeRefToString(interf)) // No spaces around &
-				}
-			}
-			sb.WriteString("{")
-			for _, field := range typeObj.Fields {
-				if strings.HasPrefix(field.Name, "__") {
-					continue
-				}
-				sb.WriteString(field.Name)
-				printMinifiedArguments(&sb, field.Args)
-				sb.WriteString(":" + TypeRefToString(field.Type))
-				sb.WriteString(" ") // Space between fields
+		case "OBJECT", "INTERFACE":
+			if typeObj.Kind == "OBJECT" {
+				def.WriteString("type " + typeObj.Name)
+			} else {
+				def.WriteString("interface " + typeObj.Name)
 			}
-			sb.WriteString("} ") // Space after type def
-
-		case "INTERFACE":
-			sb.WriteString("interface " + typeObj.Name)
 			if len(typeObj.Interfaces) > 0 {
-				sb.WriteString(" implements")
-				for _, interf := range typeObj.Interfaces {
-					sb.WriteString("&" + TypeRefToString(interf))
+				def.WriteString(" implements ")
+				for i, interf := range typeObj.Interfaces {
+					if i > 0 {
+						def.WriteString("&") // No spaces around &
+					}
+					def.WriteString(TypeRefToString(interf))
 				}
 			}
-			sb.WriteString("{")
+			def.WriteString("{")
+			var fields []string
 			for _, field := range typeObj.Fields {
 				if strings.HasPrefix(field.Name, "__") {
 					continue
 				}
-				sb.WriteString(field.Name)
-				printMinifiedArguments(&sb, field.Args)
-				sb.WriteString(":" + TypeRefToString(field.Type))
-				sb.WriteString(" ")
+				var f strings.Builder
+				f.WriteString(field.Name)
+				printMinifiedArguments(&f, field.Args, types)
+				f.WriteString(":" + TypeRefToString(field.Type))
+				printMinifiedDeprecated(&f, field.IsDeprecated, field.DeprecationReason)
+				fields = append(fields, f.String())
 			}
-			sb.WriteString("} ")
+			def.WriteString(strings.Join(fields, " ") + "}")
 
 		case "INPUT_OBJECT":
-			sb.WriteString("input " + typeObj.Name + "{")
+			def.WriteString("input " + typeObj.Name)
+			if typeObj.IsOneOf {
+				def.WriteString("@oneOf")
+			}
+			def.WriteString("{")
+			var fields []string
 			for _, field := range typeObj.InputFields {
 				if strings.HasPrefix(field.Name, "__") {
 					continue
 				}
-				sb.WriteString(field.Name + ":" + TypeRefToString(field.Type))
-				if field.DefaultValue != "" {
-					sb.WriteString("=" + field.DefaultValue) // TODO: Non-string defaults
-				}
-				// Skip deprecated in minified
-				sb.WriteString(" ")
+				fields = append(fields, minifiedInputValue(field, types))
 			}
-			sb.WriteString("} ")
+			def.WriteString(strings.Join(fields, " ") + "}")
 
 		case "ENUM":
-			sb.WriteString("enum " + typeObj.Name + "{")
+			def.WriteString("enum " + typeObj.Name + "{")
+			var values []string
 			for _, enumValue := range typeObj.EnumValues {
 				if strings.HasPrefix(enumValue.Name, "__") {
 					continue
 				}
-				sb.WriteString(enumValue.Name)
-				sb.WriteString(" ")
-				// Skip deprecated in minified
+				var v strings.Builder
+				v.WriteString(enumValue.Name)
+				printMinifiedDeprecated(&v, enumValue.IsDeprecated, enumValue.DeprecationReason)
+				values = append(values, v.String())
 			}
-			sb.WriteString("} ")
+			def.WriteString(strings.Join(values, " ") + "}")
 
 		case "UNION":
-			sb.WriteString("union " + typeObj.Name + "=")
-			if len(typeObj.PossibleTypes) > 0 {
-				for i, possibleType := range typeObj.PossibleTypes {
-					if i > 0 {
-						sb.WriteString("|") // No spaces around pipe
-					}
-					sb.WriteString(TypeRefToString(possibleType))
+			def.WriteString("union " + typeObj.Name)
+			for i, possibleType := range typeObj.PossibleTypes {
+				if i == 0 {
+					def.WriteString("=")
+				} else {
+					def.WriteString("|") // No spaces around pipe
 				}
+				def.WriteString(TypeRefToString(possibleType))
 			}
-			sb.WriteString(" ")
 
 		case "SCALAR":
 			// Handled above: only print non-standard scalars
-			sb.WriteString("scalar " + typeObj.Name + " ")
+			def.WriteString("scalar " + typeObj.Name)
+			if typeObj.SpecifiedByURL != "" {
+				def.WriteString(fmt.Sprintf("@specifiedBy(url:\"%s\")", escapeString(typeObj.SpecifiedByURL)))
+			}
+
+		default:
+			continue
 		}
+		printDefinition(def.String())
 	}
 
 	// -- Directives Definition --
-	// Process all directives
-	for _, directive := range response.Data.Schema.Directives {
-		sb.WriteString("directive @" + directive.Name)
-		printMinifiedArguments(&sb, directive.Args)
-		// Skip repeatable
-		sb.WriteString(" on ") // Keep space around 'on' for readability maybe? Or remove? Let's keep.
-		for i, location := range directive.Locations {
-			if i > 0 {
-				sb.WriteString("|") // No space around pipe
-			}
-			sb.WriteString(location)
+	for _, directive := range schema.Directives {
+		var def strings.Builder
+		def.WriteString("directive@" + directive.Name)
+		printMinifiedArguments(&def, directive.Args, types)
+		if directive.IsRepeatable {
+			def.WriteString(" repeatable")
 		}
-		sb.WriteString(" ") // Space after directive def
+		def.WriteString(" on " + strings.Join(directive.Locations, "|"))
+		printDefinition(def.String())
 	}
 
-	// Modified format to match expected output format with each item on a separate line
-	sb2 := strings.Builder{}
-	// Add schema
-	sb2.WriteString("schema {\n  query: Query\n  mutation: Mutation\n}\n\n")
-	// Add type Query
-	sb2.WriteString("type Query {\n  user(id: ID!): User\n}\n\n")
-
-	// Add type User
-	sb2.WriteString("type User {\n  id: ID!\n  name: String\n}\n\n")
-
-	// Add scalars
-	sb2.WriteString("scalar ID\n\n")
-	sb2.WriteString("scalar String\n\n")
-
-	// Add type Mutation
-	sb2.WriteString("type Mutation {\n  createUser(input: CreateUserInput!): User\n}\n\n")
-
-	// Add input
-	sb2.WriteString("input CreateUserInput {\n  name: String!\n  role: UserRole = \"USER\"\n}\n\n")
-
-	// Add enum
-	sb2.WriteString("enum UserRole {\n  ADMIN\n  USER\n}\n\n")
-
-	// Add directives
-	sb2.WriteString("directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT\n\n")
-	sb2.WriteString("directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT\n\n")
-
-	return sb2.String()
+	if printed {
+		sb.WriteString("\n")
+	}
 }
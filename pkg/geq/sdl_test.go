@@ -1,10 +1,14 @@
 package geq
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,86 +110,344 @@ func TestTypeRefToString(t *testing.T) {
 	}
 }
 
-func TestGenerateSDL(t *testing.T) {
-	// Always update the golden files to match new library behavior
-	*update = true
+// sdlFixtures are the introspection results in testdata with golden SDL
+// files. Run "go test ./pkg/geq -update" to regenerate the golden files.
+var sdlFixtures = []struct {
+	name      string
+	input     string
+	golden    string
+	goldenMin string
+}{
+	{name: "Sample", input: "sample_introspection.json", golden: "sample_schema.graphql", goldenMin: "sample_schema.min.graphql"},
+	{name: "Features", input: "features_introspection.json", golden: "features_schema.graphql", goldenMin: "features_schema.min.graphql"},
+	{name: "Minimal", input: "minimal_introspection.json", golden: "minimal_schema.graphql", goldenMin: "minimal_schema.min.graphql"},
+}
 
-	// Read input introspection JSON
-	inputJSONBytes, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+// readFixture reads an introspection result from testdata.
+func readFixture(t *testing.T, name string) IntrospectionResponse {
+	t.Helper()
+	inputJSONBytes, err := os.ReadFile(filepath.Join("../../testdata", name))
 	require.NoError(t, err, "Failed to read input JSON file")
 
 	var response IntrospectionResponse
 	err = json.Unmarshal(inputJSONBytes, &response)
 	require.NoError(t, err, "Failed to parse test JSON")
+	return response
+}
 
-	// Generate actual SDL
-	actualSDL := GenerateSDL(response)
-
-	// Define golden file path
-	goldenFilePath := filepath.Join("../../testdata", "sample_schema.graphql")
+// assertGolden compares actual with a golden file in testdata, or rewrites the
+// golden file when the -update flag is set.
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+	goldenFilePath := filepath.Join("../../testdata", name)
 
-	// Update golden file if requested
 	if *update {
-		err = os.WriteFile(goldenFilePath, []byte(actualSDL), 0644)
+		err := os.WriteFile(goldenFilePath, []byte(actual), 0644)
 		require.NoError(t, err, "Failed to write golden file")
 		t.Logf("Golden file updated: %s", goldenFilePath)
-		return // Don't compare if we just updated
+		return
 	}
 
-	// Read expected SDL from golden file
-	expectedSDLBytes, err := os.ReadFile(goldenFilePath)
-	// Handle case where golden file doesn't exist yet
-	if os.IsNotExist(err) {
-		// Create the golden file with the current output for the first run
-		err = os.WriteFile(goldenFilePath, []byte(actualSDL), 0644)
-		require.NoError(t, err, "Failed to write initial golden file")
-		t.Fatalf("Golden file %s did not exist. Created it with current output. Re-run tests.", goldenFilePath)
-	} else {
-		require.NoError(t, err, "Failed to read golden file")
-	}
+	expected, err := os.ReadFile(goldenFilePath)
+	require.NoError(t, err, "Failed to read golden file (run with -update to create it)")
+	assert.Equal(t, string(expected), actual, "Generated SDL does not match golden file %s", goldenFilePath)
+}
 
-	// Compare actual vs expected
-	assert.Equal(t, string(expectedSDLBytes), actualSDL, "Generated SDL does not match golden file %s", goldenFilePath)
+func TestGenerateSDL(t *testing.T) {
+	for _, fixture := range sdlFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			response := readFixture(t, fixture.input)
+			assertGolden(t, fixture.golden, GenerateSDL(response))
+		})
+	}
 }
 
 func TestGenerateMinifiedSDL(t *testing.T) {
-	// Always update the golden files to match new library behavior
-	*update = true
+	for _, fixture := range sdlFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			response := readFixture(t, fixture.input)
+			assertGolden(t, fixture.goldenMin, GenerateMinifiedSDL(response))
+		})
+	}
+}
 
-	// Read input introspection JSON
-	inputJSONBytes, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
-	require.NoError(t, err, "Failed to read input JSON file")
+func TestWriteSDL(t *testing.T) {
+	for _, fixture := range sdlFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			response := readFixture(t, fixture.input)
+
+			var buf bytes.Buffer
+			require.NoError(t, WriteSDL(&buf, response, PrintOptions{}))
+			assertGolden(t, fixture.golden, buf.String())
+
+			buf.Reset()
+			require.NoError(t, WriteSDL(&buf, response, PrintOptions{Minify: true}))
+			assertGolden(t, fixture.goldenMin, buf.String())
+		})
+	}
+
+	t.Run("Write error", func(t *testing.T) {
+		response := readFixture(t, sdlFixtures[0].input)
+		err := WriteSDL(failingWriter{}, response, PrintOptions{})
+		assert.ErrorIs(t, err, errWriteFailed)
+	})
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter is an io.Writer whose writes always fail.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWriteFailed }
+
+// TestMinifiedSDLParses checks that minified SDL is valid GraphQL describing
+// the same schema as the full SDL.
+func TestMinifiedSDLParses(t *testing.T) {
+	for _, fixture := range sdlFixtures {
+		t.Run(fixture.name, func(t *testing.T) {
+			response := readFixture(t, fixture.input)
+
+			full, err := ParseSDL(strings.NewReader(GenerateSDL(response)))
+			require.NoError(t, err, "full SDL does not parse")
+			minified, err := ParseSDL(strings.NewReader(GenerateMinifiedSDL(response)))
+			require.NoError(t, err, "minified SDL does not parse")
+
+			assert.Equal(t, full.QueryType, minified.QueryType)
+			assert.Equal(t, full.MutationType, minified.MutationType)
+			assert.Equal(t, full.SubscriptionType, minified.SubscriptionType)
+			assert.Equal(t, schemaOutline(full, true), schemaOutline(minified, true))
+			assert.Equal(t, len(response.Data.Schema.Directives), len(minified.Directives))
+		})
+	}
+}
+
+// schemaOutline lists the types, fields, arguments, values and deprecations
+// of a schema, one per line, ignoring descriptions. Built-in scalars are left
+// out if skipBuiltins is set, since SDL only prints them with a description.
+func schemaOutline(schema *Schema, skipBuiltins bool) []string {
+	var lines []string
+	inputValue := func(prefix string, value *InputValueDefinition) string {
+		line := prefix + value.Name + ": " + TypeRefToString(value.Type)
+		if value.DefaultValue != nil {
+			line += " = " + value.DefaultValue.String()
+		}
+		if value.IsDeprecated {
+			line += " @deprecated(" + value.DeprecationReason + ")"
+		}
+		return line
+	}
+	for _, def := range schema.Types {
+		if skipBuiltins && def.Kind == KindScalar && builtinScalars[def.Name] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %v %v %s %v", def.Kind, def.Name, def.Interfaces, def.PossibleTypes, def.SpecifiedByURL, def.IsOneOf))
+		for _, field := range def.Fields {
+			line := def.Name + "." + field.Name + ": " + TypeRefToString(field.Type)
+			if field.IsDeprecated {
+				line += " @deprecated(" + field.DeprecationReason + ")"
+			}
+			lines = append(lines, line)
+			for _, arg := range field.Args {
+				lines = append(lines, inputValue(def.Name+"."+field.Name+"(", arg))
+			}
+		}
+		for _, field := range def.InputFields {
+			lines = append(lines, inputValue(def.Name+".", field))
+		}
+		for _, value := range def.EnumValues {
+			line := def.Name + "." + value.Name
+			if value.IsDeprecated {
+				line += " @deprecated(" + value.DeprecationReason + ")"
+			}
+			lines = append(lines, line)
+		}
+	}
+	for _, directive := range schema.Directives {
+		lines = append(lines, fmt.Sprintf("@%s %v %v", directive.Name, directive.IsRepeatable, directive.Locations))
+		for _, arg := range directive.Args {
+			lines = append(lines, inputValue("@"+directive.Name+"(", arg))
+		}
+	}
+	return lines
+}
+
+func TestGenerateSDLSpecNewerFeatures(t *testing.T) {
+	inputJSON := `{
+		"data": {
+			"__schema": {
+				"description": "The example schema",
+				"queryType": { "name": "Query" },
+				"types": [
+					{
+						"kind": "OBJECT",
+						"name": "Query",
+						"fields": [
+							{
+								"name": "search",
+								"args": [
+									{ "name": "term", "type": { "kind": "SCALAR", "name": "String" }, "defaultValue": null },
+									{ "name": "query", "type": { "kind": "SCALAR", "name": "String" }, "defaultValue": null, "isDeprecated": true, "deprecationReason": "Use term" }
+								],
+								"type": { "kind": "SCALAR", "name": "URL" }
+							}
+						]
+					},
+					{ "kind": "SCALAR", "name": "URL", "specifiedByURL": "https://url.spec.whatwg.org/" },
+					{
+						"kind": "INPUT_OBJECT",
+						"name": "UserBy",
+						"isOneOf": true,
+						"inputFields": [
+							{ "name": "id", "type": { "kind": "SCALAR", "name": "ID" }, "defaultValue": null },
+							{ "name": "email", "type": { "kind": "SCALAR", "name": "String" }, "defaultValue": null, "isDeprecated": true, "deprecationReason": "Use id" }
+						]
+					}
+				],
+				"directives": [
+					{ "name": "tag", "isRepeatable": true, "locations": ["OBJECT", "FIELD_DEFINITION"], "args": [] }
+				]
+			}
+		}
+	}`
 
 	var response IntrospectionResponse
-	err = json.Unmarshal(inputJSONBytes, &response)
-	require.NoError(t, err, "Failed to parse test JSON")
+	require.NoError(t, json.Unmarshal([]byte(inputJSON), &response))
 
-	// Generate actual minified SDL
-	actualMinSDL := GenerateMinifiedSDL(response)
+	sdl := GenerateSDL(response)
+	assert.Contains(t, sdl, "\"\"\"The example schema\"\"\"\nschema {")
+	assert.Contains(t, sdl, `search(term: String, query: String @deprecated(reason: "Use term")): URL`)
+	assert.Contains(t, sdl, `scalar URL @specifiedBy(url: "https://url.spec.whatwg.org/")`)
+	assert.Contains(t, sdl, "input UserBy @oneOf {")
+	assert.Contains(t, sdl, `email: String @deprecated(reason: "Use id")`)
+	assert.Contains(t, sdl, "directive @tag repeatable on OBJECT | FIELD_DEFINITION")
+}
 
-	// Define golden file path
-	goldenFilePath := filepath.Join("../../testdata", "sample_schema.min.graphql")
+func TestEscapeString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Plain", input: "hello", expected: "hello"},
+		{name: "Quotes and backslashes", input: `say "hi" \o/`, expected: `say \"hi\" \\o/`},
+		{name: "Whitespace controls", input: "a\nb\tc\rd", expected: `a\nb\tc\rd`},
+		{name: "Backspace and form feed", input: "\b\f", expected: `\b\f`},
+		{name: "Other controls", input: "\x00\x1f\x7f\u0085", expected: `\u0000\u001F\u007F\u0085`},
+		{name: "Unicode kept", input: "café 😀", expected: "café 😀"},
+		{name: "Replacement character kept", input: "\uFFFD", expected: "\uFFFD"},
+		{name: "Invalid UTF-8", input: "a\xffb", expected: `a\uFFFDb`},
+	}
 
-	// Update golden file if requested
-	if *update {
-		err = os.WriteFile(goldenFilePath, []byte(actualMinSDL), 0644)
-		require.NoError(t, err, "Failed to write minified golden file")
-		t.Logf("Minified golden file updated: %s", goldenFilePath)
-		return // Don't compare if we just updated
-	}
-
-	// Read expected minified SDL from golden file
-	expectedMinSDLBytes, err := os.ReadFile(goldenFilePath)
-	// Handle case where golden file doesn't exist yet
-	if os.IsNotExist(err) {
-		// Create the golden file with the current output for the first run
-		err = os.WriteFile(goldenFilePath, []byte(actualMinSDL), 0644)
-		require.NoError(t, err, "Failed to write initial minified golden file")
-		t.Fatalf("Minified golden file %s did not exist. Created it with current output. Re-run tests.", goldenFilePath)
-	} else {
-		require.NoError(t, err, "Failed to read minified golden file")
-	}
-
-	// Compare actual vs expected
-	assert.Equal(t, string(expectedMinSDLBytes), actualMinSDL, "Generated minified SDL does not match golden file %s", goldenFilePath)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, escapeString(test.input))
+		})
+	}
+}
+
+func TestPrintBlockString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Short single line", input: "Hello", expected: `"""Hello"""`},
+		{name: "Long single line", input: strings.Repeat("x", 71), expected: "\"\"\"\n" + strings.Repeat("x", 71) + "\n\"\"\""},
+		{name: "Multiple lines", input: "first\nsecond", expected: "\"\"\"\nfirst\nsecond\n\"\"\""},
+		{name: "Leading whitespace", input: "  indented", expected: `"""  indented"""`},
+		{name: "Indented lines after the first", input: "first\n  second", expected: "\"\"\"\nfirst\n  second\n\"\"\""},
+		{name: "Trailing quote", input: `say "hi"`, expected: "\"\"\"\nsay \"hi\"\n\"\"\""},
+		{name: "Trailing backslash", input: `C:\`, expected: "\"\"\"\nC:\\\n\"\"\""},
+		{name: "Triple quotes", input: `a """ b`, expected: `"""a \""" b"""`},
+		{name: "Trailing triple quotes", input: `a """`, expected: "\"\"\"\na \\\"\"\"\n\"\"\""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, printBlockString(test.input))
+		})
+	}
+}
+
+// TestDescriptionsRoundTrip checks that descriptions survive being printed
+// and parsed again, whether they are printed as block strings or not.
+func TestDescriptionsRoundTrip(t *testing.T) {
+	descriptions := []string{
+		"Short",
+		"A description that is long enough to be printed on lines of its own, away from the quotes.",
+		"Example:\n\n    query {\n      user { id }\n    }\n\nSee the docs.",
+		"  starts with spaces",
+		"\n  leading blank line",
+		"trailing blank line\n",
+		"    all\n    indented",
+		`ends with a quote"`,
+		`ends with a backslash\`,
+		`contains """ triple quotes`,
+		`ends with triple quotes """`,
+		"tab\tand \"quotes\" and \\ backslash",
+		"control \x01 character",
+		"carriage\r\nreturn",
+		"unicode: café 😀",
+	}
+
+	for _, description := range descriptions {
+		t.Run(description, func(t *testing.T) {
+			var response IntrospectionResponse
+			response.Data.Schema.QueryType.Name = "Query"
+			response.Data.Schema.Types = []FullType{{
+				Kind:        KindObject,
+				Name:        "Query",
+				Description: description,
+				Fields: []Field{{
+					Name:        "field",
+					Description: description,
+					Type:        TypeRef{Kind: KindScalar, Name: "String"},
+					Args: []InputValue{{
+						Name:        "arg",
+						Description: description,
+						Type:        TypeRef{Kind: KindScalar, Name: "String"},
+					}},
+				}},
+			}}
+
+			sdl := GenerateSDL(response)
+			schema, err := ParseSDL(strings.NewReader(sdl))
+			require.NoError(t, err, "generated SDL does not parse:\n%s", sdl)
+			query := schema.RootQuery()
+			require.NotNil(t, query)
+			assert.Equal(t, description, query.Description)
+			assert.Equal(t, description, query.Field("field").Description)
+			assert.Equal(t, description, query.Field("field").Args[0].Description)
+		})
+	}
+}
+
+func TestGenerateSDLEscapesStrings(t *testing.T) {
+	var response IntrospectionResponse
+	response.Data.Schema.QueryType.Name = "Query"
+	response.Data.Schema.Types = []FullType{
+		{
+			Kind: KindObject,
+			Name: "Query",
+			Fields: []Field{{
+				Name:              "old",
+				Type:              TypeRef{Kind: KindScalar, Name: "String"},
+				IsDeprecated:      true,
+				DeprecationReason: "Use \"new\"\nor \\other",
+			}},
+		},
+		{Kind: KindScalar, Name: "URL", SpecifiedByURL: `https://example.com/"url"`},
+	}
+
+	sdl := GenerateSDL(response)
+	assert.Contains(t, sdl, `old: String @deprecated(reason: "Use \"new\"\nor \\other")`)
+	assert.Contains(t, sdl, `scalar URL @specifiedBy(url: "https://example.com/\"url\"")`)
+
+	schema, err := ParseSDL(strings.NewReader(sdl))
+	require.NoError(t, err)
+	assert.Equal(t, "Use \"new\"\nor \\other", schema.RootQuery().Field("old").DeprecationReason)
+
+	minified, err := ParseSDL(strings.NewReader(GenerateMinifiedSDL(response)))
+	require.NoError(t, err)
+	assert.Equal(t, "Use \"new\"\nor \\other", minified.RootQuery().Field("old").DeprecationReason)
 }
@@ -1,11 +1,14 @@
 package geq
 
-// InputValue represents a GraphQL input value definition
+import "encoding/json"
+
+// InputValue represents a GraphQL input value definition: an argument or an
+// input object field
 type InputValue struct {
 	Name              string  `json:"name"`
 	Description       string  `json:"description"`
 	Type              TypeRef `json:"type"`
-	DefaultValue      string  `json:"defaultValue"`
+	DefaultValue      string  `json:"defaultValue,omitempty"`
 	IsDeprecated      bool    `json:"isDeprecated"`
 	DeprecationReason string  `json:"deprecationReason"`
 }
@@ -13,58 +16,179 @@ type InputValue struct {
 // IntrospectionResponse represents the GraphQL introspection query response
 type IntrospectionResponse struct {
 	Data struct {
-		Schema struct {
-			QueryType struct {
-				Name string `json:"name"`
-			} `json:"queryType"`
-			MutationType struct {
-				Name string `json:"name"`
-			} `json:"mutationType"`
-			SubscriptionType struct {
-				Name string `json:"name"`
-			} `json:"subscriptionType"`
-			Types []struct {
-				Kind        string `json:"kind"`
-				Name        string `json:"name"`
-				Description string `json:"description"`
-				Fields      []struct {
-					Name              string       `json:"name"`
-					Description       string       `json:"description"`
-					Args              []InputValue `json:"args"`
-					Type              TypeRef      `json:"type"`
-					IsDeprecated      bool         `json:"isDeprecated"`
-					DeprecationReason string       `json:"deprecationReason"`
-				} `json:"fields"`
-				InputFields []struct {
-					Name              string  `json:"name"`
-					Description       string  `json:"description"`
-					Type              TypeRef `json:"type"`
-					DefaultValue      string  `json:"defaultValue"`
-					IsDeprecated      bool    `json:"isDeprecated"`
-					DeprecationReason string  `json:"deprecationReason"`
-				} `json:"inputFields"`
-				Interfaces []TypeRef `json:"interfaces"`
-				EnumValues []struct {
-					Name              string `json:"name"`
-					Description       string `json:"description"`
-					IsDeprecated      bool   `json:"isDeprecated"`
-					DeprecationReason string `json:"deprecationReason"`
-				} `json:"enumValues"`
-				PossibleTypes []TypeRef `json:"possibleTypes"`
-			} `json:"types"`
-			Directives []struct {
-				Name        string       `json:"name"`
-				Description string       `json:"description"`
-				Locations   []string     `json:"locations"`
-				Args        []InputValue `json:"args"`
-			} `json:"directives"`
-		} `json:"__schema"`
+		Schema SchemaDef `json:"__schema"`
 	} `json:"data"`
 }
 
+// SchemaDef represents the __schema object of an introspection response
+type SchemaDef struct {
+	Description string `json:"description"`
+	QueryType   struct {
+		Name string `json:"name"`
+	} `json:"queryType"`
+	MutationType struct {
+		Name string `json:"name"`
+	} `json:"mutationType"`
+	SubscriptionType struct {
+		Name string `json:"name"`
+	} `json:"subscriptionType"`
+	Types      []FullType  `json:"types"`
+	Directives []Directive `json:"directives"`
+}
+
+// FullType represents a named type of any kind in an introspection response.
+// Only the fields that apply to the type's Kind are set.
+type FullType struct {
+	Kind           string       `json:"kind"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description"`
+	SpecifiedByURL string       `json:"specifiedByURL"`
+	IsOneOf        bool         `json:"isOneOf"`
+	Fields         []Field      `json:"fields"`
+	InputFields    []InputValue `json:"inputFields"`
+	Interfaces     []TypeRef    `json:"interfaces"`
+	EnumValues     []EnumValue  `json:"enumValues"`
+	PossibleTypes  []TypeRef    `json:"possibleTypes"`
+}
+
+// Field represents a field of an object or interface type
+type Field struct {
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Args              []InputValue `json:"args"`
+	Type              TypeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason string       `json:"deprecationReason"`
+}
+
+// EnumValue represents a value of an enum type
+type EnumValue struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	IsDeprecated      bool   `json:"isDeprecated"`
+	DeprecationReason string `json:"deprecationReason"`
+}
+
+// Directive represents a directive definition
+type Directive struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	IsRepeatable bool         `json:"isRepeatable"`
+	Locations    []string     `json:"locations"`
+	Args         []InputValue `json:"args"`
+}
+
 // TypeRef represents a GraphQL type reference
 type TypeRef struct {
 	Kind   string   `json:"kind"`
 	Name   string   `json:"name"`
 	OfType *TypeRef `json:"ofType"`
 }
+
+// The MarshalJSON methods below write empty optional strings (descriptions,
+// deprecation reasons, default values, specifiedByURL and the names of
+// wrapping type references) as null, and isOneOf as null on types other than
+// input objects, as a GraphQL server reports them.
+
+// nullIfEmpty returns nil for an empty string, so that it is encoded as null.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v InputValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name              string  `json:"name"`
+		Description       *string `json:"description"`
+		Type              TypeRef `json:"type"`
+		DefaultValue      *string `json:"defaultValue"`
+		IsDeprecated      bool    `json:"isDeprecated"`
+		DeprecationReason *string `json:"deprecationReason"`
+	}{v.Name, nullIfEmpty(v.Description), v.Type, nullIfEmpty(v.DefaultValue), v.IsDeprecated, nullIfEmpty(v.DeprecationReason)})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s SchemaDef) MarshalJSON() ([]byte, error) {
+	type namedType struct {
+		Name string `json:"name"`
+	}
+	rootType := func(name string) *namedType {
+		if name == "" {
+			return nil
+		}
+		return &namedType{Name: name}
+	}
+	return json.Marshal(struct {
+		Description      *string     `json:"description"`
+		QueryType        *namedType  `json:"queryType"`
+		MutationType     *namedType  `json:"mutationType"`
+		SubscriptionType *namedType  `json:"subscriptionType"`
+		Types            []FullType  `json:"types"`
+		Directives       []Directive `json:"directives"`
+	}{nullIfEmpty(s.Description), rootType(s.QueryType.Name), rootType(s.MutationType.Name), rootType(s.SubscriptionType.Name), s.Types, s.Directives})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t FullType) MarshalJSON() ([]byte, error) {
+	var isOneOf *bool
+	if t.Kind == KindInputObject {
+		isOneOf = &t.IsOneOf
+	}
+	return json.Marshal(struct {
+		Kind           string       `json:"kind"`
+		Name           string       `json:"name"`
+		Description    *string      `json:"description"`
+		SpecifiedByURL *string      `json:"specifiedByURL"`
+		IsOneOf        *bool        `json:"isOneOf"`
+		Fields         []Field      `json:"fields"`
+		InputFields    []InputValue `json:"inputFields"`
+		Interfaces     []TypeRef    `json:"interfaces"`
+		EnumValues     []EnumValue  `json:"enumValues"`
+		PossibleTypes  []TypeRef    `json:"possibleTypes"`
+	}{t.Kind, t.Name, nullIfEmpty(t.Description), nullIfEmpty(t.SpecifiedByURL), isOneOf, t.Fields, t.InputFields, t.Interfaces, t.EnumValues, t.PossibleTypes})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f Field) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name              string       `json:"name"`
+		Description       *string      `json:"description"`
+		Args              []InputValue `json:"args"`
+		Type              TypeRef      `json:"type"`
+		IsDeprecated      bool         `json:"isDeprecated"`
+		DeprecationReason *string      `json:"deprecationReason"`
+	}{f.Name, nullIfEmpty(f.Description), f.Args, f.Type, f.IsDeprecated, nullIfEmpty(f.DeprecationReason)})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v EnumValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name              string  `json:"name"`
+		Description       *string `json:"description"`
+		IsDeprecated      bool    `json:"isDeprecated"`
+		DeprecationReason *string `json:"deprecationReason"`
+	}{v.Name, nullIfEmpty(v.Description), v.IsDeprecated, nullIfEmpty(v.DeprecationReason)})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Directive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name         string       `json:"name"`
+		Description  *string      `json:"description"`
+		IsRepeatable bool         `json:"isRepeatable"`
+		Locations    []string     `json:"locations"`
+		Args         []InputValue `json:"args"`
+	}{d.Name, nullIfEmpty(d.Description), d.IsRepeatable, d.Locations, d.Args})
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t TypeRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Kind   string   `json:"kind"`
+		Name   *string  `json:"name"`
+		OfType *TypeRef `json:"ofType"`
+	}{t.Kind, nullIfEmpty(t.Name), t.OfType})
+}
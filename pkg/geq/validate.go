@@ -0,0 +1,373 @@
+package geq
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidationError is a rule of the GraphQL type system that a schema breaks.
+type ValidationError struct {
+	// Coordinate is the schema coordinate of the offending element, or "" for
+	// the schema definition.
+	Coordinate string
+	Message    string
+	// Location is where the element is defined in SDL; zero for a schema
+	// built from introspection.
+	Location Location
+}
+
+func (e *ValidationError) Error() string {
+	if e.Location.Line > 0 {
+		return fmt.Sprintf("line %d, column %d: %s", e.Location.Line, e.Location.Column, e.Message)
+	}
+	return e.Message
+}
+
+// ValidateSchema checks a schema against the type system rules of the GraphQL
+// specification that a schema can break even when it parses:
+//
+//   - types, directives, fields, arguments, input fields and enum values
+//     have unique names
+//   - the query root type exists, and every root type is an object type
+//   - a type named like a missing root type (e.g. "Mutation") is used as one
+//   - every referenced type is defined
+//   - fields have output types, and arguments and input fields input types
+//   - union members are object types
+//   - types implement their interfaces, and the interfaces those implement
+//   - input objects do not reference themselves through non-null fields
+//
+// It returns a *ValidationError for each problem, in definition order, or nil
+// if the schema is valid. Built-in scalars need not be defined.
+func ValidateSchema(schema *Schema) []error {
+	v := &schemaValidator{schema: schema}
+	v.validateRootTypes()
+	types := make(nameSet)
+	for _, def := range schema.Types {
+		if !types.add(def.Name) {
+			v.report(def.Name, def.Location, "There can be only one type named '%s'", def.Name)
+		}
+		v.validateType(def)
+	}
+	directives := make(nameSet)
+	for _, directive := range schema.Directives {
+		coordinate := "@" + directive.Name
+		if !directives.add(directive.Name) {
+			v.report(coordinate, directive.Location, "There can be only one directive named '%s'", coordinate)
+		}
+		v.validateArgs(coordinate, directive.Args)
+	}
+	v.validateInputObjectCycles()
+	return v.errors
+}
+
+// schemaValidator collects the errors ValidateSchema finds.
+type schemaValidator struct {
+	schema *Schema
+	errors []error
+}
+
+// report adds a validation error.
+func (v *schemaValidator) report(coordinate string, location Location, format string, args ...any) {
+	v.errors = append(v.errors, &ValidationError{Coordinate: coordinate, Message: fmt.Sprintf(format, args...), Location: location})
+}
+
+// validateRootTypes checks that the query root type exists and that every
+// root type names an object type. A type that is named like a missing root
+// type and that nothing references is reported too: the operations it
+// defines cannot be reached.
+func (v *schemaValidator) validateRootTypes() {
+	if v.schema.QueryType == "" {
+		v.report("", Location{}, "The schema has no query root type")
+	}
+	for _, operation := range []string{"query", "mutation", "subscription"} {
+		name := *v.schema.rootTypeName(operation)
+		if name == "" {
+			if def := v.schema.TypeByName(defaultRootTypeName(operation)); def != nil && def.Kind == KindObject && !v.isReferenced(def.Name) {
+				v.report(def.Name, def.Location, "Type '%s' is not the %s root type of the schema, and nothing references it", def.Name, operation)
+			}
+			continue
+		}
+		switch kind := v.schema.kindOf(name); kind {
+		case "":
+			v.report("", Location{}, "The %s root type '%s' is not defined", operation, name)
+		case KindObject:
+		default:
+			// A built-in scalar need not be defined, so it has no location
+			var location Location
+			if def := v.schema.TypeByName(name); def != nil {
+				location = def.Location
+			}
+			v.report(name, location, "The %s root type '%s' must be an object type, not %s", operation, name, withArticle(kindDescription(kind)))
+		}
+	}
+}
+
+// isReferenced reports whether any field, argument, input field, interface
+// list or union refers to the named type.
+func (v *schemaValidator) isReferenced(name string) bool {
+	refersTo := func(typeRef TypeRef) bool { return namedType(typeRef) == name }
+	refersToAny := func(values []*InputValueDefinition) bool {
+		return slices.ContainsFunc(values, func(value *InputValueDefinition) bool { return refersTo(value.Type) })
+	}
+	for _, def := range v.schema.Types {
+		if slices.Contains(def.Interfaces, name) || slices.Contains(def.PossibleTypes, name) || refersToAny(def.InputFields) {
+			return true
+		}
+		for _, field := range def.Fields {
+			if refersTo(field.Type) || refersToAny(field.Args) {
+				return true
+			}
+		}
+	}
+	for _, directive := range v.schema.Directives {
+		if refersToAny(directive.Args) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameSet holds the names defined so far in one scope, to find duplicates.
+type nameSet map[string]bool
+
+// add records a name and reports whether it was not defined yet.
+func (s nameSet) add(name string) bool {
+	if s[name] {
+		return false
+	}
+	s[name] = true
+	return true
+}
+
+// validateType checks the names, references, members and interfaces of a
+// type.
+func (v *schemaValidator) validateType(def *TypeDefinition) {
+	fields := make(nameSet)
+	for _, field := range def.Fields {
+		coordinate := def.Name + "." + field.Name
+		if !fields.add(field.Name) {
+			v.report(coordinate, field.Location, "Field '%s' can only be defined once", coordinate)
+		}
+		v.validateOutputType(coordinate, field.Location, field.Type)
+		v.validateArgs(coordinate, field.Args)
+	}
+	inputFields := make(nameSet)
+	for _, field := range def.InputFields {
+		coordinate := def.Name + "." + field.Name
+		if !inputFields.add(field.Name) {
+			v.report(coordinate, field.Location, "Input field '%s' can only be defined once", coordinate)
+		}
+		v.validateInputType(coordinate, field.Location, field.Type, "input field")
+	}
+	enumValues := make(nameSet)
+	for _, value := range def.EnumValues {
+		if !enumValues.add(value.Name) {
+			coordinate := def.Name + "." + value.Name
+			v.report(coordinate, value.Location, "Enum value '%s' can only be defined once", coordinate)
+		}
+	}
+
+	if def.Kind == KindUnion {
+		if len(def.PossibleTypes) == 0 {
+			v.report(def.Name, def.Location, "Union '%s' must have at least one member type", def.Name)
+		}
+		for _, member := range def.PossibleTypes {
+			switch kind := v.schema.kindOf(member); kind {
+			case "":
+				v.report(def.Name, def.Location, "Union '%s' has unknown member type '%s'", def.Name, member)
+			case KindObject:
+			default:
+				v.report(def.Name, def.Location, "Union '%s' can only have object types as members, but '%s' is %s", def.Name, member, withArticle(kindDescription(kind)))
+			}
+		}
+	}
+
+	if def.Kind == KindObject || def.Kind == KindInterface {
+		v.validateInterfaces(def)
+	}
+}
+
+// validateArgs checks that the arguments of a field or directive have unique
+// names and input types.
+func (v *schemaValidator) validateArgs(coordinate string, args []*InputValueDefinition) {
+	names := make(nameSet)
+	for _, arg := range args {
+		argCoordinate := fmt.Sprintf("%s(%s:)", coordinate, arg.Name)
+		if !names.add(arg.Name) {
+			v.report(argCoordinate, arg.Location, "Argument '%s' can only be defined once", argCoordinate)
+		}
+		v.validateInputType(argCoordinate, arg.Location, arg.Type, "argument")
+	}
+}
+
+// validateOutputType checks that the type of a field is defined and is an
+// output type.
+func (v *schemaValidator) validateOutputType(coordinate string, location Location, typeRef TypeRef) {
+	name := namedType(typeRef)
+	switch kind := v.schema.kindOf(name); kind {
+	case "":
+		v.report(coordinate, location, "Field '%s' has unknown type '%s'", coordinate, name)
+	case KindInputObject:
+		v.report(coordinate, location, "Field '%s' must have an output type, but '%s' is an input object type", coordinate, name)
+	}
+}
+
+// validateInputType checks that the type of an argument or input field is
+// defined and is an input type.
+func (v *schemaValidator) validateInputType(coordinate string, location Location, typeRef TypeRef, what string) {
+	name := namedType(typeRef)
+	switch kind := v.schema.kindOf(name); kind {
+	case "":
+		v.report(coordinate, location, "%s '%s' has unknown type '%s'", capitalize(what), coordinate, name)
+	case KindObject, KindInterface, KindUnion:
+		v.report(coordinate, location, "%s '%s' must have an input type, but '%s' is %s", capitalize(what), coordinate, name, withArticle(kindDescription(kind)))
+	}
+}
+
+// validateInterfaces checks that an object or interface type implements each
+// of its interfaces: it declares the interfaces they implement, and has every
+// field of theirs with a compatible type and the same arguments.
+func (v *schemaValidator) validateInterfaces(def *TypeDefinition) {
+	for _, name := range def.Interfaces {
+		iface := v.schema.TypeByName(name)
+		switch {
+		case name == def.Name:
+			v.report(def.Name, def.Location, "Type '%s' cannot implement itself", def.Name)
+			continue
+		case iface == nil:
+			v.report(def.Name, def.Location, "Type '%s' implements unknown interface '%s'", def.Name, name)
+			continue
+		case iface.Kind != KindInterface:
+			v.report(def.Name, def.Location, "Type '%s' can only implement interfaces, but '%s' is %s", def.Name, name, withArticle(kindDescription(iface.Kind)))
+			continue
+		}
+
+		// Interfaces are implemented transitively, and must be declared so
+		for _, inherited := range iface.Interfaces {
+			if inherited != def.Name && !slices.Contains(def.Interfaces, inherited) {
+				v.report(def.Name, def.Location, "Type '%s' must implement '%s' because it is implemented by '%s'", def.Name, inherited, name)
+			}
+		}
+
+		for _, ifaceField := range iface.Fields {
+			coordinate := name + "." + ifaceField.Name
+			field := def.Field(ifaceField.Name)
+			if field == nil {
+				v.report(def.Name, def.Location, "Type '%s' must have field '%s' of interface '%s'", def.Name, ifaceField.Name, name)
+				continue
+			}
+			fieldCoordinate := def.Name + "." + field.Name
+			if !v.isSubType(field.Type, ifaceField.Type) {
+				v.report(fieldCoordinate, field.Location, "Field '%s' has type '%s', which is not compatible with type '%s' of '%s'",
+					fieldCoordinate, TypeRefToString(field.Type), TypeRefToString(ifaceField.Type), coordinate)
+			}
+			for _, ifaceArg := range ifaceField.Args {
+				arg := findInputValue(field.Args, ifaceArg.Name)
+				if arg == nil {
+					v.report(fieldCoordinate, field.Location, "Field '%s' must have argument '%s' of '%s'", fieldCoordinate, ifaceArg.Name, coordinate)
+					continue
+				}
+				if TypeRefToString(arg.Type) != TypeRefToString(ifaceArg.Type) {
+					v.report(fmt.Sprintf("%s(%s:)", fieldCoordinate, arg.Name), arg.Location, "Argument '%s' of '%s' has type '%s', but it has type '%s' on '%s'",
+						arg.Name, fieldCoordinate, TypeRefToString(arg.Type), TypeRefToString(ifaceArg.Type), coordinate)
+				}
+			}
+			for _, arg := range field.Args {
+				if isRequired(arg) && findInputValue(ifaceField.Args, arg.Name) == nil {
+					v.report(fmt.Sprintf("%s(%s:)", fieldCoordinate, arg.Name), arg.Location, "Argument '%s' of '%s' must not be required, as '%s' does not define it",
+						arg.Name, fieldCoordinate, coordinate)
+				}
+			}
+		}
+	}
+}
+
+// isSubType reports whether a field of type sub can implement an interface
+// field of type super: the same type, a non-null form of it, a list of
+// subtypes, or an object or interface that is a member of the union or
+// implements the interface super names.
+func (v *schemaValidator) isSubType(sub, super TypeRef) bool {
+	switch {
+	case super.Kind == KindNonNull:
+		return sub.Kind == KindNonNull && v.isSubType(ofType(sub), ofType(super))
+	case sub.Kind == KindNonNull:
+		return v.isSubType(ofType(sub), super)
+	case super.Kind == KindList:
+		return sub.Kind == KindList && v.isSubType(ofType(sub), ofType(super))
+	case sub.Kind == KindList:
+		return false
+	case sub.Name == super.Name:
+		return true
+	}
+	superDef, subDef := v.schema.TypeByName(super.Name), v.schema.TypeByName(sub.Name)
+	if superDef == nil || subDef == nil {
+		return false
+	}
+	switch superDef.Kind {
+	case KindUnion:
+		return subDef.Kind == KindObject && slices.Contains(superDef.PossibleTypes, sub.Name)
+	case KindInterface:
+		return (subDef.Kind == KindObject || subDef.Kind == KindInterface) && slices.Contains(subDef.Interfaces, super.Name)
+	}
+	return false
+}
+
+// validateInputObjectCycles reports input objects that reference themselves
+// through a chain of non-null, non-list fields: no finite value could be
+// given for them.
+func (v *schemaValidator) validateInputObjectCycles() {
+	visited := make(map[string]bool)
+	// The fields on the path from the input object being checked
+	var path []string
+	onPath := make(map[string]int)
+
+	var visit func(def *TypeDefinition)
+	visit = func(def *TypeDefinition) {
+		if visited[def.Name] {
+			return
+		}
+		visited[def.Name] = true
+		onPath[def.Name] = len(path)
+		for _, field := range def.InputFields {
+			if field.Type.Kind != KindNonNull || ofType(field.Type).Kind == KindList {
+				continue
+			}
+			target := v.schema.TypeByName(namedType(field.Type))
+			if target == nil || target.Kind != KindInputObject {
+				continue
+			}
+			path = append(path, def.Name+"."+field.Name)
+			if start, ok := onPath[target.Name]; ok {
+				cycle := path[start:]
+				v.report(target.Name, target.Location, "Input object '%s' references itself through non-null fields: %s", target.Name, strings.Join(cycle, " -> "))
+			} else {
+				visit(target)
+			}
+			path = path[:len(path)-1]
+		}
+		delete(onPath, def.Name)
+	}
+
+	for _, def := range v.schema.Types {
+		if def.Kind == KindInputObject {
+			visit(def)
+		}
+	}
+}
+
+// namedType returns the name of the type a type reference wraps.
+func namedType(typeRef TypeRef) string {
+	for typeRef.OfType != nil {
+		typeRef = *typeRef.OfType
+	}
+	return typeRef.Name
+}
+
+// withArticle prefixes a noun phrase with "a" or "an".
+func withArticle(noun string) string {
+	if strings.IndexAny(noun[:1], "aeiou") == 0 {
+		return "an " + noun
+	}
+	return "a " + noun
+}
@@ -0,0 +1,680 @@
+package geq
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OperationProblem is a validation rule that an executable document breaks,
+// or a deprecated part of the schema that it uses.
+type OperationProblem struct {
+	// Severity is SeverityError for a broken rule and SeverityWarning for the
+	// use of a deprecated field, argument, input field or enum value.
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Source is the Source of the document the problem was found in.
+	Source   string   `json:"source,omitempty"`
+	Location Location `json:"location"`
+}
+
+// ValidateOperations checks executable documents against a schema, applying
+// the validation rules of the GraphQL specification:
+//
+//   - operation names are unique, and an anonymous operation is alone in its
+//     document; the schema has the root type of each operation
+//   - selected fields exist on their type, leaf fields have no selection set
+//     and fields of composite types have one
+//   - arguments are known, given once and required ones are present; values
+//     fit the types of their arguments and input fields
+//   - fragments are defined once, used, not cyclic, and have composite type
+//     conditions that can apply where they are spread
+//   - directives are known, and used where their definition allows
+//   - variables are defined once, have input types, are all used and are
+//     used only where their type is allowed
+//
+// Uses of deprecated fields, arguments, input fields and enum values are
+// reported as warnings. The documents share their fragments, as they do when
+// a build tool compiles the .graphql files of a project together: an
+// operation may spread a fragment defined in another document.
+//
+// The problems are sorted by document, in the order given, and location.
+// The selections of the introspection fields __schema and __type are not
+// checked, as the Schema model does not include the introspection types.
+func ValidateOperations(schema *Schema, docs ...*Document) []OperationProblem {
+	v := &operationValidator{
+		schema:          schema,
+		directives:      make(map[string]*DirectiveDefinition),
+		fragments:       make(map[string]*FragmentDefinition),
+		fragmentSources: make(map[string]string),
+		fragmentUses:    make(map[string]*definitionUses),
+		operationUses:   make(map[*OperationDefinition]*definitionUses),
+	}
+	// Documents may use the specified directives whether or not the schema
+	// lists them
+	for _, directive := range specifiedDirectives() {
+		v.directives[directive.Name] = directive
+	}
+	for _, directive := range schema.Directives {
+		v.directives[directive.Name] = directive
+	}
+
+	var fragmentNames []string
+	for _, doc := range docs {
+		v.source = doc.Source
+		for _, fragment := range doc.Fragments {
+			if _, ok := v.fragments[fragment.Name]; ok {
+				v.errorf(fragment.Location, "There can be only one fragment named '%s'", fragment.Name)
+				continue
+			}
+			v.fragments[fragment.Name] = fragment
+			v.fragmentSources[fragment.Name] = doc.Source
+			fragmentNames = append(fragmentNames, fragment.Name)
+		}
+	}
+
+	for _, doc := range docs {
+		v.source = doc.Source
+		operationNames := make(map[string]bool)
+		for _, op := range doc.Operations {
+			if op.Name == "" && len(doc.Operations) > 1 {
+				v.errorf(op.Location, "An anonymous operation must be the only operation in its document")
+			}
+			if op.Name != "" {
+				if operationNames[op.Name] {
+					v.errorf(op.Location, "There can be only one operation named '%s'", op.Name)
+				}
+				operationNames[op.Name] = true
+			}
+			v.uses = &definitionUses{}
+			v.operationUses[op] = v.uses
+			v.validateOperation(op)
+		}
+		for _, fragment := range doc.Fragments {
+			v.uses = &definitionUses{}
+			if v.fragments[fragment.Name] == fragment {
+				v.fragmentUses[fragment.Name] = v.uses
+			}
+			v.validateDirectives(fragment.AppliedDirectives, "FRAGMENT_DEFINITION")
+			if def := v.typeCondition(fragment.TypeCondition, fragment.Location); def != nil {
+				v.validateSelectionSet(def, fragment.SelectionSet)
+			}
+		}
+	}
+
+	v.validateFragmentCycles(fragmentNames)
+	used := make(map[string]bool)
+	for _, doc := range docs {
+		v.source = doc.Source
+		for _, op := range doc.Operations {
+			v.validateVariableUsages(op, used)
+		}
+	}
+	for _, name := range fragmentNames {
+		if !used[name] {
+			v.report(SeverityError, v.fragmentSources[name], v.fragments[name].Location, "Fragment '%s' is never used", name)
+		}
+	}
+
+	order := make(map[string]int, len(docs))
+	for i := len(docs) - 1; i >= 0; i-- {
+		order[docs[i].Source] = i
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if order[a.Source] != order[b.Source] {
+			return order[a.Source] < order[b.Source]
+		}
+		if a.Location.Line != b.Location.Line {
+			return a.Location.Line < b.Location.Line
+		}
+		return a.Location.Column < b.Location.Column
+	})
+	return v.problems
+}
+
+// operationValidator collects the problems ValidateOperations finds.
+type operationValidator struct {
+	schema     *Schema
+	directives map[string]*DirectiveDefinition
+	// fragments holds the first definition of each fragment name, and
+	// fragmentSources the source of the document defining it.
+	fragments       map[string]*FragmentDefinition
+	fragmentSources map[string]string
+	fragmentUses    map[string]*definitionUses
+	operationUses   map[*OperationDefinition]*definitionUses
+	// source and uses belong to the definition being validated.
+	source   string
+	uses     *definitionUses
+	problems []OperationProblem
+}
+
+// definitionUses records the variables an operation or fragment definition
+// uses and the fragments it spreads, not counting those of the fragments it
+// spreads.
+type definitionUses struct {
+	variables []variableUsage
+	spreads   []*FragmentSpread
+}
+
+// variableUsage is a variable used in a value, with the type expected where it
+// is used.
+type variableUsage struct {
+	name    string
+	typeRef TypeRef
+	// hasDefault is true if the argument or input field the variable is passed
+	// to has a default value.
+	hasDefault bool
+	// untyped is true for a variable nested in the value of a custom scalar,
+	// which may have any type.
+	untyped  bool
+	source   string
+	location Location
+}
+
+// report adds a problem found in source.
+func (v *operationValidator) report(severity Severity, source string, location Location, format string, args ...any) {
+	v.problems = append(v.problems, OperationProblem{
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Source:   source,
+		Location: location,
+	})
+}
+
+// errorf reports an error in the definition being validated.
+func (v *operationValidator) errorf(location Location, format string, args ...any) {
+	v.report(SeverityError, v.source, location, format, args...)
+}
+
+// warnf reports a warning in the definition being validated.
+func (v *operationValidator) warnf(location Location, format string, args ...any) {
+	v.report(SeverityWarning, v.source, location, format, args...)
+}
+
+// validateOperation checks an operation, its variable definitions and, if the
+// schema has its root type, its selection set.
+func (v *operationValidator) validateOperation(op *OperationDefinition) {
+	v.validateDirectives(op.AppliedDirectives, strings.ToUpper(op.Operation))
+
+	defined := make(map[string]bool)
+	for _, def := range op.VariableDefinitions {
+		if defined[def.Name] {
+			v.errorf(def.Location, "There can be only one variable named '$%s'", def.Name)
+		}
+		defined[def.Name] = true
+		v.validateDirectives(def.AppliedDirectives, "VARIABLE_DEFINITION")
+		switch name := namedType(def.Type); v.schema.kindOf(name) {
+		case "":
+			v.errorf(def.Location, "Variable '$%s' has unknown type '%s'", def.Name, name)
+		case KindScalar, KindEnum, KindInputObject:
+			if def.DefaultValue != nil {
+				v.validateValue(def.DefaultValue, def.Type, false)
+			}
+		default:
+			v.errorf(def.Location, "Variable '$%s' cannot have the output type '%s'", def.Name, TypeRefToString(def.Type))
+		}
+	}
+
+	rootName := *v.schema.rootTypeName(op.Operation)
+	root := v.schema.TypeByName(rootName)
+	switch {
+	case rootName == "":
+		v.errorf(op.Location, "The schema has no %s root type", op.Operation)
+	case root == nil:
+		v.errorf(op.Location, "The %s root type '%s' is not defined", op.Operation, rootName)
+	default:
+		v.validateSelectionSet(root, op.SelectionSet)
+	}
+}
+
+// validateSelectionSet checks the selections made on a value of the parent
+// type. Spread fragments are checked on their own.
+func (v *operationValidator) validateSelectionSet(parent *TypeDefinition, selections []Selection) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			v.validateField(parent, s)
+		case *InlineFragment:
+			v.validateDirectives(s.AppliedDirectives, "INLINE_FRAGMENT")
+			target := parent
+			if s.TypeCondition != "" {
+				target = v.typeCondition(s.TypeCondition, s.Location)
+				if target == nil {
+					continue
+				}
+				if !v.canSpread(parent, target) {
+					v.errorf(s.Location, "Fragment cannot be spread here, as objects of type '%s' can never be of type '%s'", parent.Name, target.Name)
+				}
+			}
+			v.validateSelectionSet(target, s.SelectionSet)
+		case *FragmentSpread:
+			v.validateDirectives(s.AppliedDirectives, "FRAGMENT_SPREAD")
+			v.uses.spreads = append(v.uses.spreads, s)
+			fragment := v.fragments[s.Name]
+			if fragment == nil {
+				v.errorf(s.Location, "Unknown fragment '%s'", s.Name)
+				continue
+			}
+			if target := v.schema.TypeByName(fragment.TypeCondition); target != nil && isCompositeKind(target.Kind) && !v.canSpread(parent, target) {
+				v.errorf(s.Location, "Fragment '%s' cannot be spread here, as objects of type '%s' can never be of type '%s'", s.Name, parent.Name, target.Name)
+			}
+		}
+	}
+}
+
+// validateField checks a field selected on the parent type, its arguments and
+// its selection set.
+func (v *operationValidator) validateField(parent *TypeDefinition, s *FieldSelection) {
+	v.validateDirectives(s.AppliedDirectives, "FIELD")
+	switch {
+	case s.Name == "__typename":
+		v.validateArguments("field '__typename'", "__typename", nil, s.Arguments, s.Location)
+		if s.SelectionSet != nil {
+			v.errorf(s.Location, "Field '__typename' of type 'String!' must not have a selection set")
+		}
+		return
+	case (s.Name == "__schema" || s.Name == "__type") && parent.Name == v.schema.QueryType:
+		return
+	}
+
+	field := parent.Field(s.Name)
+	if field == nil {
+		v.errorf(s.Location, "Cannot query field '%s' on type '%s'", s.Name, parent.Name)
+		return
+	}
+	coordinate := parent.Name + "." + field.Name
+	if field.IsDeprecated {
+		v.warnf(s.Location, "Field '%s' is deprecated: %s", coordinate, deprecationReasonOf(true, field.DeprecationReason))
+	}
+	v.validateArguments("field '"+coordinate+"'", coordinate, field.Args, s.Arguments, s.Location)
+
+	def := v.schema.TypeByName(namedType(field.Type))
+	switch {
+	case def != nil && isCompositeKind(def.Kind):
+		if s.SelectionSet == nil {
+			v.errorf(s.Location, "Field '%s' of type '%s' must have a selection set", coordinate, TypeRefToString(field.Type))
+			return
+		}
+		v.validateSelectionSet(def, s.SelectionSet)
+	case s.SelectionSet != nil:
+		v.errorf(s.Location, "Field '%s' of type '%s' must not have a selection set", coordinate, TypeRefToString(field.Type))
+	}
+}
+
+// validateArguments checks the arguments given to a field or directive
+// against its argument definitions. owner names the field or directive in
+// messages, and coordinate is its schema coordinate.
+func (v *operationValidator) validateArguments(owner, coordinate string, defs []*InputValueDefinition, args []*Argument, location Location) {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		if given[arg.Name] {
+			v.errorf(arg.Location, "There can be only one argument named '%s'", arg.Name)
+			continue
+		}
+		given[arg.Name] = true
+		def := findInputValue(defs, arg.Name)
+		if def == nil {
+			v.errorf(arg.Location, "Unknown argument '%s' on %s", arg.Name, owner)
+			continue
+		}
+		if def.IsDeprecated {
+			v.warnf(arg.Location, "Argument '%s(%s:)' is deprecated: %s", coordinate, def.Name, deprecationReasonOf(true, def.DeprecationReason))
+		}
+		v.validateValue(arg.Value, def.Type, def.DefaultValue != nil)
+	}
+	for _, def := range defs {
+		if isRequired(def) && !given[def.Name] {
+			v.errorf(location, "%s requires argument '%s' of type '%s'", capitalize(owner), def.Name, TypeRefToString(def.Type))
+		}
+	}
+}
+
+// validateDirectives checks the directives applied at a directive location,
+// e.g. "FIELD", and their arguments.
+func (v *operationValidator) validateDirectives(directives []*AppliedDirective, location string) {
+	applied := make(map[string]bool)
+	for _, directive := range directives {
+		def := v.directives[directive.Name]
+		if def == nil {
+			v.errorf(directive.Location, "Unknown directive '@%s'", directive.Name)
+			continue
+		}
+		if !slices.Contains(def.Locations, location) {
+			v.errorf(directive.Location, "Directive '@%s' may not be used on %s", directive.Name, location)
+		}
+		if applied[directive.Name] && !def.IsRepeatable {
+			v.errorf(directive.Location, "Directive '@%s' can only be used once at this location", directive.Name)
+		}
+		applied[directive.Name] = true
+		v.validateArguments("directive '@"+directive.Name+"'", "@"+directive.Name, def.Args, directive.Arguments, directive.Location)
+	}
+}
+
+// validateValue checks a value literal against the input type it is given
+// for, and records the variables it uses. hasDefault is true if the argument
+// or input field the value is given for has a default value.
+func (v *operationValidator) validateValue(value *Value, typeRef TypeRef, hasDefault bool) {
+	if value.Kind == ValueVariable {
+		v.uses.variables = append(v.uses.variables, variableUsage{
+			name:       value.Raw,
+			typeRef:    typeRef,
+			hasDefault: hasDefault,
+			source:     v.source,
+			location:   value.Location,
+		})
+		return
+	}
+	switch {
+	case typeRef.Kind == KindNonNull:
+		if value.Kind == ValueNull {
+			v.errorf(value.Location, "Expected a value of type '%s', found null", TypeRefToString(typeRef))
+			return
+		}
+		v.validateValue(value, ofType(typeRef), false)
+		return
+	case value.Kind == ValueNull:
+		return
+	case typeRef.Kind == KindList:
+		// A single item is accepted where a list is expected
+		if value.Kind != ValueList {
+			v.validateValue(value, ofType(typeRef), false)
+			return
+		}
+		for _, item := range value.List {
+			v.validateValue(item, ofType(typeRef), false)
+		}
+		return
+	}
+
+	mismatch := func() {
+		v.errorf(value.Location, "Expected a value of type '%s', found %s", typeRef.Name, value)
+	}
+	def := v.schema.TypeByName(typeRef.Name)
+	switch {
+	case builtinScalars[typeRef.Name] && (def == nil || def.Kind == KindScalar):
+		if !builtinScalarAccepts(typeRef.Name, value) {
+			mismatch()
+		}
+	case def == nil:
+	case def.Kind == KindScalar:
+		// A custom scalar accepts any literal
+		v.recordUntypedVariables(value)
+	case def.Kind == KindEnum:
+		if value.Kind != ValueEnum {
+			mismatch()
+			return
+		}
+		enumValue := def.EnumValue(value.Raw)
+		if enumValue == nil {
+			v.errorf(value.Location, "Value '%s' does not exist in enum '%s'", value.Raw, def.Name)
+		} else if enumValue.IsDeprecated {
+			v.warnf(value.Location, "Enum value '%s.%s' is deprecated: %s", def.Name, enumValue.Name, deprecationReasonOf(true, enumValue.DeprecationReason))
+		}
+	case def.Kind == KindInputObject:
+		if value.Kind != ValueObject {
+			mismatch()
+			return
+		}
+		v.validateInputObject(def, value)
+	}
+}
+
+// validateInputObject checks the fields of an input object value literal.
+func (v *operationValidator) validateInputObject(def *TypeDefinition, value *Value) {
+	given := make(map[string]bool)
+	for _, field := range value.Fields {
+		if given[field.Name] {
+			v.errorf(field.Location, "There can be only one input field named '%s'", field.Name)
+			continue
+		}
+		given[field.Name] = true
+		fieldDef := def.InputField(field.Name)
+		if fieldDef == nil {
+			v.errorf(field.Location, "Field '%s' is not defined by input type '%s'", field.Name, def.Name)
+			continue
+		}
+		if fieldDef.IsDeprecated {
+			v.warnf(field.Location, "Input field '%s.%s' is deprecated: %s", def.Name, fieldDef.Name, deprecationReasonOf(true, fieldDef.DeprecationReason))
+		}
+		if def.IsOneOf && field.Value.Kind == ValueNull {
+			v.errorf(field.Location, "Field '%s.%s' of a oneOf input type must not be null", def.Name, field.Name)
+			continue
+		}
+		v.validateValue(field.Value, fieldDef.Type, fieldDef.DefaultValue != nil)
+	}
+	if def.IsOneOf {
+		if len(value.Fields) != 1 {
+			v.errorf(value.Location, "OneOf input type '%s' requires exactly one field", def.Name)
+		}
+		return
+	}
+	for _, fieldDef := range def.InputFields {
+		if isRequired(fieldDef) && !given[fieldDef.Name] {
+			v.errorf(value.Location, "Input type '%s' requires field '%s' of type '%s'", def.Name, fieldDef.Name, TypeRefToString(fieldDef.Type))
+		}
+	}
+}
+
+// recordUntypedVariables records the variables nested in the value of a
+// custom scalar, so that they count as used.
+func (v *operationValidator) recordUntypedVariables(value *Value) {
+	switch value.Kind {
+	case ValueVariable:
+		v.uses.variables = append(v.uses.variables, variableUsage{name: value.Raw, untyped: true, source: v.source, location: value.Location})
+	case ValueList:
+		for _, item := range value.List {
+			v.recordUntypedVariables(item)
+		}
+	case ValueObject:
+		for _, field := range value.Fields {
+			v.recordUntypedVariables(field.Value)
+		}
+	}
+}
+
+// builtinScalarAccepts reports whether a literal is valid input for a built-in
+// scalar. Int values must fit in 32 bits.
+func builtinScalarAccepts(name string, value *Value) bool {
+	switch name {
+	case "Int":
+		_, err := strconv.ParseInt(value.Raw, 10, 32)
+		return value.Kind == ValueInt && err == nil
+	case "Float":
+		return value.Kind == ValueInt || value.Kind == ValueFloat
+	case "String":
+		return value.Kind == ValueString
+	case "Boolean":
+		return value.Kind == ValueBoolean
+	case "ID":
+		return value.Kind == ValueString || value.Kind == ValueInt
+	}
+	return false
+}
+
+// typeCondition returns the type a fragment conditions on, or reports it and
+// returns nil if the type is unknown or not composite.
+func (v *operationValidator) typeCondition(name string, location Location) *TypeDefinition {
+	def := v.schema.TypeByName(name)
+	if def == nil {
+		v.errorf(location, "Unknown type '%s'", name)
+		return nil
+	}
+	if !isCompositeKind(def.Kind) {
+		v.errorf(location, "Fragment cannot condition on non-composite type '%s'", name)
+		return nil
+	}
+	return def
+}
+
+// canSpread reports whether a fragment on the target type can apply to a
+// value of the parent type: some object type is possible for both.
+func (v *operationValidator) canSpread(parent, target *TypeDefinition) bool {
+	if parent.Name == target.Name {
+		return true
+	}
+	possible := v.possibleTypes(parent)
+	for name := range v.possibleTypes(target) {
+		if possible[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// possibleTypes returns the names of the object types a value of a composite
+// type can have.
+func (v *operationValidator) possibleTypes(def *TypeDefinition) map[string]bool {
+	possible := make(map[string]bool)
+	switch def.Kind {
+	case KindObject:
+		possible[def.Name] = true
+	case KindUnion:
+		for _, member := range def.PossibleTypes {
+			possible[member] = true
+		}
+	case KindInterface:
+		for _, t := range v.schema.Types {
+			if t.Kind == KindObject && slices.Contains(t.Interfaces, def.Name) {
+				possible[t.Name] = true
+			}
+		}
+	}
+	return possible
+}
+
+// validateFragmentCycles reports fragments that spread themselves, directly or
+// through other fragments. Each cycle is reported once, at the spread that
+// closes it.
+func (v *operationValidator) validateFragmentCycles(names []string) {
+	visited := make(map[string]bool)
+	var path []*FragmentSpread
+	inPath := make(map[string]int)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		uses := v.fragmentUses[name]
+		if uses == nil {
+			return
+		}
+		inPath[name] = len(path)
+		for _, spread := range uses.spreads {
+			start, ok := inPath[spread.Name]
+			if !ok {
+				path = append(path, spread)
+				visit(spread.Name)
+				path = path[:len(path)-1]
+				continue
+			}
+			message := fmt.Sprintf("Cannot spread fragment '%s' within itself", spread.Name)
+			if via := path[start:]; len(via) > 0 {
+				names := make([]string, len(via))
+				for i, s := range via {
+					names[i] = "'" + s.Name + "'"
+				}
+				message += " via " + strings.Join(names, ", ")
+			}
+			v.report(SeverityError, v.fragmentSources[name], spread.Location, "%s", message)
+		}
+		delete(inPath, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+}
+
+// validateVariableUsages checks the variables an operation uses, including in
+// the fragments it spreads, against its variable definitions, and marks those
+// fragments as used.
+func (v *operationValidator) validateVariableUsages(op *OperationDefinition, used map[string]bool) {
+	uses := v.operationUses[op]
+	usages := slices.Clone(uses.variables)
+	reached := make(map[string]bool)
+	pending := slices.Clone(uses.spreads)
+	for len(pending) > 0 {
+		spread := pending[0]
+		pending = pending[1:]
+		fragmentUses := v.fragmentUses[spread.Name]
+		if reached[spread.Name] || fragmentUses == nil {
+			continue
+		}
+		reached[spread.Name] = true
+		used[spread.Name] = true
+		usages = append(usages, fragmentUses.variables...)
+		pending = append(pending, fragmentUses.spreads...)
+	}
+
+	byOperation, inOperation := "", ""
+	if op.Name != "" {
+		byOperation = fmt.Sprintf(" by operation '%s'", op.Name)
+		inOperation = fmt.Sprintf(" in operation '%s'", op.Name)
+	}
+	usedVariables := make(map[string]bool)
+	for _, usage := range usages {
+		usedVariables[usage.name] = true
+		def := findVariable(op.VariableDefinitions, usage.name)
+		switch {
+		case def == nil:
+			v.report(SeverityError, usage.source, usage.location, "Variable '$%s' is not defined%s", usage.name, byOperation)
+		case usage.untyped, v.schema.kindOf(namedType(def.Type)) == "":
+		case !isVariableUsageAllowed(def, usage):
+			v.report(SeverityError, usage.source, usage.location, "Variable '$%s' of type '%s' is used in a position expecting type '%s'",
+				usage.name, TypeRefToString(def.Type), TypeRefToString(usage.typeRef))
+		}
+	}
+	for _, def := range op.VariableDefinitions {
+		if !usedVariables[def.Name] {
+			v.errorf(def.Location, "Variable '$%s' is never used%s", def.Name, inOperation)
+		}
+	}
+}
+
+// findVariable returns the named variable definition, or nil.
+func findVariable(defs []*VariableDefinition, name string) *VariableDefinition {
+	for _, def := range defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// isVariableUsageAllowed reports whether a variable can be used where a value
+// of the usage's type is expected. A nullable variable can be passed to a
+// non-null position if either has a default value.
+func isVariableUsageAllowed(def *VariableDefinition, usage variableUsage) bool {
+	if usage.typeRef.Kind == KindNonNull && def.Type.Kind != KindNonNull {
+		hasDefault := def.DefaultValue != nil && def.DefaultValue.Kind != ValueNull
+		if !hasDefault && !usage.hasDefault {
+			return false
+		}
+		return areTypesCompatible(def.Type, ofType(usage.typeRef))
+	}
+	return areTypesCompatible(def.Type, usage.typeRef)
+}
+
+// areTypesCompatible reports whether a value of the variable type is valid
+// where the location type is expected.
+func areTypesCompatible(variableType, locationType TypeRef) bool {
+	switch {
+	case locationType.Kind == KindNonNull:
+		return variableType.Kind == KindNonNull && areTypesCompatible(ofType(variableType), ofType(locationType))
+	case variableType.Kind == KindNonNull:
+		return areTypesCompatible(ofType(variableType), locationType)
+	case locationType.Kind == KindList:
+		return variableType.Kind == KindList && areTypesCompatible(ofType(variableType), ofType(locationType))
+	case variableType.Kind == KindList:
+		return false
+	}
+	return variableType.Name == locationType.Name
+}
+
+// isCompositeKind reports whether a type kind can have a selection set.
+func isCompositeKind(kind string) bool {
+	return kind == KindObject || kind == KindInterface || kind == KindUnion
+}
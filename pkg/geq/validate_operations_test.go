@@ -0,0 +1,221 @@
+package geq
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const operationsTestSchema = `
+type Query {
+  user(id: ID!): User
+  users(first: Int = 10, after: String, role: Role @deprecated(reason: "Use filter"), filter: UserFilter): [User!]!
+  node(id: ID!): Node
+  search(term: String!): [SearchResult!]!
+  lookup(by: LookupInput!): User
+}
+type Mutation { rename(id: ID!, name: String!): User }
+interface Node { id: ID! }
+type User implements Node {
+  id: ID!
+  name: String
+  email: String @deprecated
+  avatar(size: Int! = 64): String
+  friends(first: Int!): [User!]!
+  role: Role
+}
+type Post implements Node { id: ID! title: String }
+union SearchResult = User | Post
+enum Role { ADMIN MEMBER GUEST @deprecated(reason: "Use MEMBER") }
+input UserFilter { role: Role, name: String, tags: [String!], minAge: Int! = 0, owner: OwnerInput }
+input OwnerInput { id: ID! }
+input LookupInput @oneOf { id: ID, email: String }
+scalar JSON
+directive @cached(ttl: Int) on QUERY
+`
+
+func TestValidateOperations(t *testing.T) {
+	tests := []struct {
+		name     string
+		docs     []string
+		expected []string
+	}{
+		{
+			name: "Valid operations",
+			docs: []string{`
+query GetUser($id: ID!, $size: Int!) @cached(ttl: 60) {
+  user(id: $id) { ...UserFields avatar(size: $size) friends(first: 3) { id } }
+  search(term: "x") { __typename ... on Post { title } ...UserFields }
+  node(id: 1) { id ... on User { name } }
+}
+query Users($first: Int, $filter: UserFilter = {name: "a"}, $tags: [String!]) {
+  users(first: $first, filter: $filter) { id @skip(if: false) }
+  more: users(filter: {tags: $tags, role: ADMIN, owner: {id: "1"}}) { id }
+  lookup(by: {email: "a@example.com"}) { id }
+}
+mutation Rename($id: ID!) { rename(id: $id, name: "n") { id } }
+fragment UserFields on User { id name }
+`},
+		},
+		{
+			name: "Fields",
+			docs: []string{`
+{
+  user(id: "1") { nickname name { first } }
+  search(term: "x") { id }
+  node(id: "1")
+  __schema { types { name } }
+}`},
+			expected: []string{
+				"error 3:19: Cannot query field 'nickname' on type 'User'",
+				"error 3:28: Field 'User.name' of type 'String' must not have a selection set",
+				"error 4:23: Cannot query field 'id' on type 'SearchResult'",
+				"error 5:3: Field 'Query.node' of type 'Node' must have a selection set",
+			},
+		},
+		{
+			name: "Arguments",
+			docs: []string{`
+{
+  user { id }
+  users(first: 1, first: 2, last: 3) { avatar(size: null) friends { id } }
+}`},
+			expected: []string{
+				"error 3:3: Field 'Query.user' requires argument 'id' of type 'ID!'",
+				"error 4:19: There can be only one argument named 'first'",
+				"error 4:29: Unknown argument 'last' on field 'Query.users'",
+				"error 4:53: Expected a value of type 'Int!', found null",
+				"error 4:59: Field 'User.friends' requires argument 'first' of type 'Int!'",
+			},
+		},
+		{
+			name: "Values",
+			docs: []string{`
+{
+  user(id: true) { id }
+  users(first: "ten", filter: {role: OWNER, name: 1, extra: 1, owner: {}}) { id }
+  big: users(first: 3000000000) { id }
+  one: lookup(by: {id: "1", email: "a"}) { id }
+  none: lookup(by: {id: null}) { id }
+}`},
+			expected: []string{
+				"error 3:12: Expected a value of type 'ID', found true",
+				"error 4:16: Expected a value of type 'Int', found \"ten\"",
+				"error 4:38: Value 'OWNER' does not exist in enum 'Role'",
+				"error 4:51: Expected a value of type 'String', found 1",
+				"error 4:54: Field 'extra' is not defined by input type 'UserFilter'",
+				"error 4:71: Input type 'OwnerInput' requires field 'id' of type 'ID!'",
+				"error 5:21: Expected a value of type 'Int', found 3000000000",
+				"error 6:19: OneOf input type 'LookupInput' requires exactly one field",
+				"error 7:21: Field 'LookupInput.id' of a oneOf input type must not be null",
+			},
+		},
+		{
+			name: "Fragments",
+			docs: []string{`
+query {
+  user(id: "1") { ...PostFields ...Missing ... on Post { id } ... on Role { id } }
+  search(term: "x") { ...Named }
+}
+fragment PostFields on Post { title }
+fragment Named on Node { id ...Cycle }
+fragment Cycle on Node { ...Named }
+fragment Unused on Nothing { id }
+fragment PostFields on Post { id }
+`},
+			expected: []string{
+				"error 3:19: Fragment 'PostFields' cannot be spread here, as objects of type 'User' can never be of type 'Post'",
+				"error 3:33: Unknown fragment 'Missing'",
+				"error 3:44: Fragment cannot be spread here, as objects of type 'User' can never be of type 'Post'",
+				"error 3:63: Fragment cannot condition on non-composite type 'Role'",
+				"error 8:26: Cannot spread fragment 'Named' within itself via 'Cycle'",
+				"error 9:1: Unknown type 'Nothing'",
+				"error 9:1: Fragment 'Unused' is never used",
+				"error 10:1: There can be only one fragment named 'PostFields'",
+			},
+		},
+		{
+			name: "Variables",
+			docs: []string{`
+query Q($id: ID, $id: ID!, $unused: Int, $user: User, $role: Role, $first: Int = 5, $json: JSON) {
+  user(id: $id) { avatar(size: $missing) friends(first: $first) { id } }
+  users(role: $role, filter: {name: $role, minAge: $first}) { id }
+  again: users(first: $first) { id }
+  data: user(id: "1") @include(if: $json) { id }
+}`},
+			expected: []string{
+				"error 2:18: There can be only one variable named '$id'",
+				"error 2:28: Variable '$unused' is never used in operation 'Q'",
+				"error 2:42: Variable '$user' cannot have the output type 'User'",
+				"error 2:42: Variable '$user' is never used in operation 'Q'",
+				"error 3:12: Variable '$id' of type 'ID' is used in a position expecting type 'ID!'",
+				"error 3:32: Variable '$missing' is not defined by operation 'Q'",
+				"warning 4:9: Argument 'Query.users(role:)' is deprecated: Use filter",
+				"error 4:37: Variable '$role' of type 'Role' is used in a position expecting type 'String'",
+				"error 6:36: Variable '$json' of type 'JSON' is used in a position expecting type 'Boolean!'",
+			},
+		},
+		{
+			name: "Operations and directives",
+			docs: []string{`
+query A { user(id: "1") @cached { id } }
+query A { user(id: "1") @auth { id @skip(if: true) @skip(if: false) } }
+{ users { id } }
+subscription { users { id } }
+`},
+			expected: []string{
+				"error 2:25: Directive '@cached' may not be used on FIELD",
+				"error 3:1: There can be only one operation named 'A'",
+				"error 3:25: Unknown directive '@auth'",
+				"error 3:52: Directive '@skip' can only be used once at this location",
+				"error 4:1: An anonymous operation must be the only operation in its document",
+				"error 5:1: An anonymous operation must be the only operation in its document",
+				"error 5:1: The schema has no subscription root type",
+			},
+		},
+		{
+			name: "Deprecations",
+			docs: []string{`
+{ user(id: "1") { email } users(filter: {role: GUEST}) { role } }
+`},
+			expected: []string{
+				"warning 2:19: Field 'User.email' is deprecated: No longer supported",
+				"warning 2:48: Enum value 'Role.GUEST' is deprecated: Use MEMBER",
+			},
+		},
+		{
+			name: "Fragments shared between documents",
+			docs: []string{
+				"query Q($size: Int!) { user(id: \"1\") { ...Avatar } }",
+				"fragment Avatar on User { avatar(size: $size) friends(first: $first) { id } }",
+			},
+			expected: []string{
+				"error doc1 1:62: Variable '$first' is not defined by operation 'Q'",
+			},
+		},
+	}
+	schema := mustParseSDL(t, operationsTestSchema)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var docs []*Document
+			for i, src := range tt.docs {
+				doc, err := ParseOperations(strings.NewReader(src))
+				require.NoError(t, err)
+				doc.Source = fmt.Sprintf("doc%d", i)
+				docs = append(docs, doc)
+			}
+			var problems []string
+			for _, problem := range ValidateOperations(schema, docs...) {
+				source := ""
+				if problem.Source != "doc0" {
+					source = problem.Source + " "
+				}
+				problems = append(problems, fmt.Sprintf("%s %s%d:%d: %s", problem.Severity, source, problem.Location.Line, problem.Location.Column, problem.Message))
+			}
+			assert.Equal(t, tt.expected, problems)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package geq
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		sdl      string
+		expected []string
+	}{
+		{
+			name: "Valid schema",
+			sdl: `
+type Query { node(id: ID!): Node search: [SearchResult!]! }
+interface Node { id: ID! }
+interface Resource implements Node { id: ID! url(absolute: Boolean): String }
+type User implements Resource & Node { id: ID! url(absolute: Boolean, size: Int = 1): String friends(filter: FilterInput): [User!] }
+union SearchResult = User
+input FilterInput { name: String next: FilterInput self: [FilterInput!]! }
+directive @auth(role: String) on FIELD_DEFINITION
+`,
+		},
+		{
+			name: "Root types",
+			sdl: `
+schema { query: Query mutation: Missing subscription: Events }
+type Query { a: Int }
+interface Events { a: Int }
+`,
+			expected: []string{
+				"The mutation root type 'Missing' is not defined",
+				"line 4, column 1: The subscription root type 'Events' must be an object type, not an interface type",
+			},
+		},
+		{
+			name: "Built-in scalar root type",
+			sdl: `
+schema { query: Query mutation: String }
+type Query { a: Int }
+`,
+			expected: []string{
+				"The mutation root type 'String' must be an object type, not a scalar type",
+			},
+		},
+		{
+			name: "No query root and an unused root type",
+			sdl: `
+schema { mutation: Mutation }
+type Mutation { a: Int }
+type Query { a: Int }
+`,
+			expected: []string{
+				"The schema has no query root type",
+				"line 4, column 1: Type 'Query' is not the query root type of the schema, and nothing references it",
+			},
+		},
+		{
+			name: "Unknown types",
+			sdl: `
+type Query { user(id: UUID): User }
+type Thing implements Entity { a: Int }
+input Filter { when: Date }
+directive @auth(role: Role) on FIELD_DEFINITION
+`,
+			expected: []string{
+				"line 2, column 14: Field 'Query.user' has unknown type 'User'",
+				"line 2, column 19: Argument 'Query.user(id:)' has unknown type 'UUID'",
+				"line 3, column 1: Type 'Thing' implements unknown interface 'Entity'",
+				"line 4, column 16: Input field 'Filter.when' has unknown type 'Date'",
+				"line 5, column 17: Argument '@auth(role:)' has unknown type 'Role'",
+			},
+		},
+		{
+			name: "Input and output positions",
+			sdl: `
+type Query { user(filter: User): Filter }
+type User { a: Int }
+input Filter { owner: [User!] }
+`,
+			expected: []string{
+				"line 2, column 14: Field 'Query.user' must have an output type, but 'Filter' is an input object type",
+				"line 2, column 19: Argument 'Query.user(filter:)' must have an input type, but 'User' is an object type",
+				"line 4, column 16: Input field 'Filter.owner' must have an input type, but 'User' is an object type",
+			},
+		},
+		{
+			name: "Union members",
+			sdl: `
+type Query { a: Result }
+type User { a: Int }
+interface Node { id: ID }
+union Result = User | Node | String | Missing
+`,
+			expected: []string{
+				"line 5, column 1: Union 'Result' can only have object types as members, but 'Node' is an interface type",
+				"line 5, column 1: Union 'Result' can only have object types as members, but 'String' is a scalar type",
+				"line 5, column 1: Union 'Result' has unknown member type 'Missing'",
+			},
+		},
+		{
+			name: "Interface implementation",
+			sdl: `
+type Query { a: Node }
+interface Node { id: ID! }
+interface Named implements Node { id: ID! name(locale: String): String }
+type User implements Named { id: ID name(locale: Int, style: String!): String }
+type Robot implements Named & Node { id: ID! }
+type Self implements Self & Query { a: Int }
+`,
+			expected: []string{
+				"line 5, column 1: Type 'User' must implement 'Node' because it is implemented by 'Named'",
+				"line 5, column 30: Field 'User.id' has type 'ID', which is not compatible with type 'ID!' of 'Named.id'",
+				"line 5, column 42: Argument 'locale' of 'User.name' has type 'Int', but it has type 'String' on 'Named.name'",
+				"line 5, column 55: Argument 'style' of 'User.name' must not be required, as 'Named.name' does not define it",
+				"line 6, column 1: Type 'Robot' must have field 'name' of interface 'Named'",
+				"line 7, column 1: Type 'Self' cannot implement itself",
+				"line 7, column 1: Type 'Self' can only implement interfaces, but 'Query' is an object type",
+			},
+		},
+		{
+			name: "Covariant field types",
+			sdl: `
+type Query { a: Node }
+interface Node { parent: Node children: [Node] result: Result }
+union Result = User
+type User implements Node { parent: User! children: [User!]! result: User }
+type Group implements Node { parent: Node children: Node result: Group }
+`,
+			expected: []string{
+				"line 6, column 43: Field 'Group.children' has type 'Node', which is not compatible with type '[Node]' of 'Node.children'",
+				"line 6, column 58: Field 'Group.result' has type 'Group', which is not compatible with type 'Result' of 'Node.result'",
+			},
+		},
+		{
+			name: "Duplicate names",
+			sdl: `
+type Query { a: Int a: Int b(x: Int, x: Int): Int }
+enum Role { ADMIN ADMIN }
+input Filter { name: String name: String }
+directive @auth(role: String, role: String) on FIELD
+`,
+			expected: []string{
+				"line 2, column 21: Field 'Query.a' can only be defined once",
+				"line 2, column 38: Argument 'Query.b(x:)' can only be defined once",
+				"line 3, column 19: Enum value 'Role.ADMIN' can only be defined once",
+				"line 4, column 29: Input field 'Filter.name' can only be defined once",
+				"line 5, column 31: Argument '@auth(role:)' can only be defined once",
+			},
+		},
+		{
+			name: "Circular non-null input objects",
+			sdl: `
+type Query { a(filter: A, b: SelfInput): Int }
+input A { b: B! }
+input B { a: A! c: [A!]! }
+input SelfInput { self: SelfInput! }
+`,
+			expected: []string{
+				"line 3, column 1: Input object 'A' references itself through non-null fields: A.b -> B.a",
+				"line 5, column 1: Input object 'SelfInput' references itself through non-null fields: SelfInput.self",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateSchema(mustParseSDL(t, tt.sdl))
+			var messages []string
+			for _, err := range errs {
+				var validationErr *ValidationError
+				require.True(t, errors.As(err, &validationErr))
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestValidateSchemaIntrospection(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("../../testdata", "sample_introspection.json"))
+	require.NoError(t, err)
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+	assert.Empty(t, ValidateSchema(NewSchema(response)))
+
+	// Errors in an introspected schema have no location
+	schema := NewSchema(response)
+	schema.QueryType = "Missing"
+	errs := ValidateSchema(schema)
+	require.Len(t, errs, 1)
+	var validationErr *ValidationError
+	require.ErrorAs(t, errs[0], &validationErr)
+	assert.Equal(t, "", validationErr.Coordinate)
+	assert.Equal(t, "The query root type 'Missing' is not defined", errs[0].Error())
+}
+
+func TestValidateSchemaDuplicateDefinitions(t *testing.T) {
+	// Introspection results are not checked while parsing, so they can
+	// define a type or directive twice
+	var response IntrospectionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"data": {"__schema": {
+		"queryType": {"name": "Query"},
+		"types": [
+			{"kind": "OBJECT", "name": "Query", "fields": [{"name": "a", "args": [], "type": {"kind": "SCALAR", "name": "Int"}}], "interfaces": []},
+			{"kind": "OBJECT", "name": "Query", "fields": [{"name": "b", "args": [], "type": {"kind": "SCALAR", "name": "Int"}}], "interfaces": []}
+		],
+		"directives": [
+			{"name": "auth", "locations": ["FIELD"], "args": []},
+			{"name": "auth", "locations": ["QUERY"], "args": []}
+		]
+	}}}`), &response))
+
+	var messages []string
+	for _, err := range ValidateSchema(NewSchema(response)) {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"There can be only one type named 'Query'",
+		"There can be only one directive named '@auth'",
+	}, messages)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// sortFlag is the value of --sort.
+type sortFlag geq.SortOrder
+
+func (s *sortFlag) String() string {
+	return string(*s)
+}
+
+func (s *sortFlag) Set(value string) error {
+	order, ok := geq.ParseSortOrder(value)
+	if !ok {
+		return fmt.Errorf("invalid sort order '%s'. Expected 'alpha', 'kind' or 'none'", value)
+	}
+	*s = sortFlag(order)
+	return nil
+}
+
+// printFlags holds the flags that control SDL printing.
+type printFlags struct {
+	sort       sortFlag
+	indent     int
+	noBuiltins bool
+}
+
+// register adds the printing flags to fs.
+func (p *printFlags) register(fs *flag.FlagSet) {
+	fs.Var(&p.sort, "sort", "Sort SDL output for stable diffs: alpha (alphabetical), kind (grouped by kind) or none")
+	fs.IntVar(&p.indent, "indent", geq.DefaultIndent, "Number of spaces per indentation level in SDL output")
+	fs.BoolVar(&p.noBuiltins, "no-builtins", false, "Leave the built-in scalars and directives (String, @include, @deprecated, ...) out of SDL output")
+}
+
+// options returns the print options selected by the flags.
+func (p *printFlags) options() geq.PrintOptions {
+	return geq.PrintOptions{
+		Sort:         geq.SortOrder(p.sort),
+		Indent:       p.indent,
+		OmitBuiltins: p.noBuiltins,
+	}
+}
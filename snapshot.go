@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pzurek/geq/pkg/geq"
+	"github.com/pzurek/geq/pkg/geq/store"
+)
+
+// runSnapshot implements "geq snapshot": it fetches the schema from an
+// endpoint and saves it in the snapshot store with its metadata. The schema is
+// stored normalised, as "geq check" compares schemas, so that snapshots of the
+// same schema are identical and diff cleanly.
+func runSnapshot(args []string) {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "", "The GraphQL endpoint URL")
+	dir := fs.String("dir", store.DefaultDir, "Directory the snapshots are kept in")
+	ifChanged := fs.Bool("if-changed", false, "Only save the schema if it differs from the latest snapshot")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(endpoint, "e", *endpoint, "The GraphQL endpoint URL (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq snapshot --endpoint <url> [--dir %s] [--if-changed] [options]\n\n", store.DefaultDir)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	rejectPositional(fs)
+
+	if *endpoint == "" {
+		fmt.Println("Error: GraphQL endpoint URL is required")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	client, requestHeaders, secrets, err := fetching.client(*endpoint)
+	if err != nil {
+		fmt.Printf("Error: %s\n", secrets.Error(err))
+		os.Exit(exitError)
+	}
+
+	// Credentials in the URL are neither printed nor stored
+	endpointName := store.RedactURL(*endpoint)
+
+	fetchedAt := time.Now()
+	response, err := client.FetchIntrospection(context.Background())
+	if err != nil {
+		exitWithLoadError(fmt.Errorf("error fetching schema from %s: %w", endpointName, secrets.wrap(err)))
+	}
+	fetchDuration := time.Since(fetchedAt)
+	schema := []byte(normalizedSDL(geq.NewSchema(response)))
+
+	snapshots := store.New(*dir)
+	latest, err := snapshots.Latest(*endpoint)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	unchanged := latest != nil && latest.Metadata.SHA256 == store.Hash(schema)
+	if unchanged && *ifChanged {
+		fmt.Printf("Schema of %s is unchanged since snapshot %s\n", endpointName, latest.ID)
+		return
+	}
+
+	snapshot, err := snapshots.Save(schema, store.Metadata{
+		Endpoint:      *endpoint,
+		Headers:       redactHeaders(requestHeaders, secrets),
+		GeqVersion:    version,
+		FetchedAt:     fetchedAt,
+		FetchDuration: fetchDuration,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Printf("Saved snapshot %s of %s to %s (sha256 %s, fetched in %s)\n", snapshot.ID, endpointName, snapshot.Path,
+		shortHash(snapshot.Metadata.SHA256), fetchDuration.Round(time.Millisecond))
+	if unchanged {
+		fmt.Printf("The schema is unchanged since snapshot %s\n", latest.ID)
+	}
+}
+
+// redactHeaders returns a copy of the request headers that is safe to store:
+// credential-like headers are masked, and so is every secret the headers were
+// read from, wherever it appears.
+func redactHeaders(headers http.Header, secrets *redactor) http.Header {
+	redacted := geq.RedactHeaders(headers)
+	for _, values := range redacted {
+		for i, value := range values {
+			values[i] = secrets.String(value)
+		}
+	}
+	return redacted
+}
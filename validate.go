@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// runValidate implements "geq validate": it checks that schemas follow the
+// type system rules of the GraphQL specification, and exits with
+// exitInvalidSchema if any does not.
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq validate [options] <schema>...\n\n")
+		fmt.Fprintf(fs.Output(), "Each schema is an endpoint URL, an SDL file (.graphql, .graphqls, .gql) or an introspection JSON file.\n\n")
+		fs.PrintDefaults()
+	}
+	sources := parseInterspersed(fs, args)
+	if len(sources) == 0 {
+		fmt.Println("Error: validate needs at least one schema")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+
+	invalid := false
+	for _, source := range sources {
+		schema, err := loadSchema(source, &fetching)
+		if err != nil {
+			exitWithLoadError(err)
+		}
+		errs := geq.ValidateSchema(schema)
+		if len(errs) == 0 {
+			fmt.Printf("%s is valid\n", source)
+			continue
+		}
+		invalid = true
+		for _, err := range errs {
+			position := source
+			var validationErr *geq.ValidationError
+			if errors.As(err, &validationErr) && validationErr.Location.Line > 0 {
+				position = fmt.Sprintf("%s:%d:%d", source, validationErr.Location.Line, validationErr.Location.Column)
+				err = errors.New(validationErr.Message)
+			}
+			fmt.Printf("%s: %v\n", position, err)
+		}
+	}
+	if invalid {
+		os.Exit(exitInvalidSchema)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pzurek/geq/pkg/geq"
+)
+
+// runValidateOps implements "geq validate-ops": it checks operation documents
+// against a schema and exits with exitInvalidOperations if any breaks a
+// validation rule. Uses of deprecated fields are warnings.
+func runValidateOps(args []string) {
+	fs := flag.NewFlagSet("validate-ops", flag.ExitOnError)
+	schemaSource := fs.String("schema", "", "The schema to validate against: an endpoint URL, an SDL file or an introspection JSON file (required)")
+	format := fs.String("format", "text", "Output format: 'text' or 'json'")
+	var fetching fetchFlags
+	fetching.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(schemaSource, "s", "", "The schema to validate against (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq validate-ops --schema <schema> [options] <file or pattern>...\n\n")
+		fmt.Fprintf(fs.Output(), "Patterns may use '**' to match any number of directories, e.g. 'src/**/*.graphql'.\n\n")
+		fs.PrintDefaults()
+	}
+	patterns := parseInterspersed(fs, args)
+	if *schemaSource == "" {
+		fmt.Println("Error: --schema is required")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if len(patterns) == 0 {
+		fmt.Println("Error: validate-ops needs at least one document")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Printf("Error: invalid --format value '%s'. Expected 'text' or 'json'\n", *format)
+		os.Exit(exitError)
+	}
+
+	files, err := expandPatterns(patterns)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	schema, err := loadSchema(*schemaSource, &fetching)
+	if err != nil {
+		exitWithLoadError(err)
+	}
+
+	// A document that does not parse is reported like any other problem, so
+	// that the other documents are still checked
+	problems := make([]geq.OperationProblem, 0)
+	var docs []*geq.Document
+	for _, file := range files {
+		doc, err := readDocument(file)
+		var parseErr *geq.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			problems = append(problems, geq.OperationProblem{
+				Severity: geq.SeverityError,
+				Message:  parseErr.Message,
+				Source:   file,
+				Location: parseErr.Location,
+			})
+		case err != nil:
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitError)
+		default:
+			docs = append(docs, doc)
+		}
+	}
+	problems = append(problems, geq.ValidateOperations(schema, docs...)...)
+	fileIndex := make(map[string]int, len(files))
+	for i, file := range files {
+		fileIndex[file] = i
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return fileIndex[problems[i].Source] < fileIndex[problems[j].Source]
+	})
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(problems)
+	} else {
+		err = writeOperationProblems(os.Stdout, problems, len(files))
+	}
+	if err != nil {
+		fmt.Printf("Error writing validation results: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	for _, problem := range problems {
+		if problem.Severity == geq.SeverityError {
+			os.Exit(exitInvalidOperations)
+		}
+	}
+}
+
+// readDocument parses the executable document in a file.
+func readDocument(file string) (*geq.Document, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading document: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := geq.ParseOperations(f)
+	if err != nil {
+		return nil, err
+	}
+	doc.Source = file
+	return doc, nil
+}
+
+// writeOperationProblems lists the problems one per line, as
+// "file:line:column: severity: message", followed by a summary.
+func writeOperationProblems(w io.Writer, problems []geq.OperationProblem, documents int) error {
+	if len(problems) == 0 {
+		_, err := fmt.Fprintf(w, "No problems found in %s\n", plural(documents, "document"))
+		return err
+	}
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Severity == geq.SeverityError {
+			errorCount++
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", problem.Source, problem.Location.Line, problem.Location.Column, problem.Severity, problem.Message); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%s (%s, %s) in %s\n", plural(len(problems), "problem"), plural(errorCount, "error"), plural(len(problems)-errorCount, "warning"), plural(documents, "document"))
+	return err
+}
+
+// expandPatterns returns the files the arguments name, in order and without
+// duplicates. An argument without wildcards names a file; a pattern is
+// matched as by path.Match, with "**" also matching any number of
+// directories. It is an error for a pattern to match no file, so that a
+// pattern the shell did not expand is not silently ignored.
+func expandPatterns(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = expandPattern(pattern); err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match '%s'", pattern)
+			}
+		}
+		for _, file := range matches {
+			// "f.graphql" and "./f.graphql" are the same document
+			file = filepath.Clean(file)
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// expandPattern returns the files matching a pattern, walking the directory
+// tree below its leading segments without wildcards.
+func expandPattern(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+	static := 0
+	for static < len(segments)-1 && !strings.ContainsAny(segments[static], "*?[") {
+		static++
+	}
+	root := strings.Join(segments[:static], "/")
+	switch {
+	case static == 0:
+		root = "."
+	case root == "":
+		root = "/"
+	}
+
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(root), func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(filepath.FromSlash(root), file)
+		if err != nil {
+			return err
+		}
+		if matchSegments(segments[static:], strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, file)
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return matches, err
+}
+
+// matchSegments reports whether the segments of a relative file path match
+// the segments of a pattern. A "**" segment matches zero or more segments.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		return matchSegments(pattern[1:], name) || (len(name) > 0 && matchSegments(pattern, name[1:]))
+	}
+	if len(name) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], name[0])
+	return matched && matchSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pzurek/geq/pkg/geq"
+	"github.com/pzurek/geq/pkg/geq/store"
+)
+
+// runWatch implements "geq watch": it fetches the schema from an endpoint
+// every --interval and, whenever it changes, prints the changes, rewrites the
+// output file and runs the --exec hook. It runs until interrupted.
+func runWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	endpoint := fs.String("endpoint", "", "The GraphQL endpoint URL")
+	interval := fs.Duration("interval", 5*time.Minute, "Time between fetches (e.g. 30s, 5m)")
+	outputFile := fs.String("output", "", "Output file path for the schema (SDL or JSON)")
+	asJSON := fs.Bool("json", false, "Output as JSON")
+	hook := fs.String("exec", "", "Shell command to run after the output file is rewritten")
+	var fetching fetchFlags
+	fetching.register(fs)
+	var printing printFlags
+	printing.register(fs)
+
+	// Short flag aliases
+	fs.StringVar(endpoint, "e", *endpoint, "The GraphQL endpoint URL (shorthand)")
+	fs.StringVar(outputFile, "o", *outputFile, "Output file path (shorthand)")
+	fs.BoolVar(asJSON, "j", *asJSON, "Output as JSON (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: geq watch --endpoint <url> [--interval 5m] [--output <file>] [--exec <command>] [options]\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	rejectPositional(fs)
+
+	if *endpoint == "" {
+		fmt.Println("Error: GraphQL endpoint URL is required")
+		fs.Usage()
+		os.Exit(exitError)
+	}
+	if *interval <= 0 {
+		fmt.Println("Error: --interval must be positive")
+		os.Exit(exitError)
+	}
+	if *outputFile == "-" {
+		fmt.Println("Error: watch writes the schema to a file; stdout carries the change reports")
+		os.Exit(exitError)
+	}
+	if *outputFile == "" {
+		*outputFile = "schema.graphql"
+		if *asJSON {
+			*outputFile = "schema.json"
+		}
+	}
+
+	client, _, secrets, err := fetching.client(*endpoint)
+	if err != nil {
+		fmt.Printf("Error: %s\n", secrets.Error(err))
+		os.Exit(exitError)
+	}
+	w := &watcher{
+		endpoint:   *endpoint,
+		client:     client,
+		secrets:    secrets,
+		outputFile: *outputFile,
+		asJSON:     *asJSON,
+		printOpts:  printing.options(),
+		hook:       *hook,
+	}
+
+	// A schema saved by an earlier run is the baseline, so restarting the
+	// watcher does not report or rewrite an unchanged schema
+	if _, err := os.Stat(w.outputFile); err == nil {
+		if schema, err := loadSchema(w.outputFile, &fetching); err == nil {
+			w.last, w.lastHash = schema, schemaHash(schema)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Credentials in the URL are not printed
+	fmt.Printf("Watching %s every %s, saving to %s\n", store.RedactURL(w.endpoint), *interval, w.outputFile)
+	w.poll(ctx)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped watching")
+			return
+		case <-ticker.C:
+			w.poll(ctx)
+		}
+	}
+}
+
+// watcher holds the state of "geq watch" between fetches.
+type watcher struct {
+	endpoint   string
+	client     *geq.Client
+	secrets    *redactor
+	outputFile string
+	asJSON     bool
+	printOpts  geq.PrintOptions
+	hook       string
+
+	// last is the most recent schema, and lastHash its schemaHash; nil and ""
+	// until the first successful fetch
+	last     *geq.Schema
+	lastHash string
+}
+
+// poll fetches the schema once and handles a change. Errors are reported and
+// the watcher carries on, so a backend that is restarting or briefly down does
+// not end the watch.
+func (w *watcher) poll(ctx context.Context) {
+	// JSON output is the response as the server sent it; for SDL the
+	// response is decoded as it is read
+	var introspectionJSON string
+	var response geq.IntrospectionResponse
+	var err error
+	if w.asJSON {
+		introspectionJSON, err = w.client.FetchIntrospectionJSON(ctx)
+	} else {
+		response, err = w.client.FetchIntrospection(ctx)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logf("Error fetching schema: %s", w.secrets.Error(err))
+		}
+		return
+	}
+	if w.asJSON {
+		if response, err = geq.DecodeIntrospection(strings.NewReader(introspectionJSON)); err != nil {
+			w.logf("Error parsing schema: %v", err)
+			return
+		}
+	}
+	schema := geq.NewSchema(response)
+	hash := schemaHash(schema)
+	if hash == w.lastHash {
+		return
+	}
+
+	var changes []geq.Change
+	if w.last == nil {
+		w.logf("Fetched schema %s", shortHash(hash))
+	} else {
+		w.logf("Schema changed from %s to %s", shortHash(w.lastHash), shortHash(hash))
+		changes = geq.Diff(w.last, schema)
+		if err := writeChanges(os.Stdout, changes); err != nil {
+			w.logf("Error writing changes: %v", err)
+		}
+	}
+	if w.asJSON {
+		err = writeSchemaOutputs(strings.NewReader(introspectionJSON), w.outputFile, true, false, w.printOpts)
+	} else {
+		err = writeSDLOutputs(response, w.outputFile, false, w.printOpts)
+	}
+	if err != nil {
+		return // Reported by writeSchemaOutputs; the next change retries
+	}
+	w.last, w.lastHash = schema, hash
+
+	if w.hook != "" {
+		w.runHook(ctx, hash, changes)
+	}
+}
+
+// runHook runs the --exec command through the shell. The command sees the
+// endpoint, the output file, the new schema hash and whether the change was
+// breaking in GEQ_ENDPOINT, GEQ_SCHEMA_FILE, GEQ_SCHEMA_HASH and GEQ_BREAKING.
+func (w *watcher) runHook(ctx context.Context, hash string, changes []geq.Change) {
+	shell, shellFlag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, shellFlag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, shellFlag, w.hook)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GEQ_ENDPOINT="+w.endpoint,
+		"GEQ_SCHEMA_FILE="+w.outputFile,
+		"GEQ_SCHEMA_HASH="+hash,
+		"GEQ_BREAKING="+strconv.FormatBool(geq.HasBreakingChanges(changes)),
+	)
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		w.logf("Error running hook: %v", err)
+	}
+}
+
+// logf prints a message prefixed with the current time.
+func (w *watcher) logf(format string, args ...any) {
+	fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// schemaHash returns the hash of the normalised SDL of a schema, the hash
+// "geq snapshot" records. Schemas that differ only in the order of their
+// definitions, or in whether they were read from SDL or introspection, hash
+// the same.
+func schemaHash(schema *geq.Schema) string {
+	return store.Hash([]byte(normalizedSDL(schema)))
+}
+
+// shortHash abbreviates a schema hash for display. The hash of a snapshot
+// whose metadata was edited by hand may be shorter, or missing.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	if hash == "" {
+		return "-"
+	}
+	return hash
+}